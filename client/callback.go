@@ -0,0 +1,31 @@
+package client
+
+// repositoryCallback forwards repository changes to the client's evaluator, the evaluator is
+// created after the repository it queries so it is looked up when the change happens
+type repositoryCallback struct {
+	client *CfClient
+}
+
+func (r repositoryCallback) OnFlagStored(identifier string) {
+	if r.client.evaluator != nil {
+		r.client.evaluator.OnFlagStored(identifier)
+	}
+}
+
+func (r repositoryCallback) OnFlagDeleted(identifier string) {
+	if r.client.evaluator != nil {
+		r.client.evaluator.OnFlagDeleted(identifier)
+	}
+}
+
+func (r repositoryCallback) OnSegmentStored(identifier string) {
+	if r.client.evaluator != nil {
+		r.client.evaluator.OnSegmentStored(identifier)
+	}
+}
+
+func (r repositoryCallback) OnSegmentDeleted(identifier string) {
+	if r.client.evaluator != nil {
+		r.client.evaluator.OnSegmentDeleted(identifier)
+	}
+}
@@ -90,8 +90,12 @@ func NewCfClient(sdkKey string, options ...ConfigOption) (*CfClient, error) {
 	if err != nil {
 		return nil, err
 	}
-	client.repository = repository.New(lruCache)
-	client.evaluator, err = evaluation.NewEvaluator(client.repository, client, config.Logger)
+	client.repository = repository.NewWithStorageAndCallback(lruCache, nil, repositoryCallback{client: client})
+	var evaluatorOptions []evaluation.EvaluatorOption
+	if config.stickyStore != nil {
+		evaluatorOptions = append(evaluatorOptions, evaluation.WithStickyStore(config.stickyStore))
+	}
+	client.evaluator, err = evaluation.NewEvaluator(client.repository, client, config.Logger, evaluatorOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -443,6 +447,52 @@ func (c *CfClient) JSONVariation(key string, target *evaluation.Target, defaultV
 	return value, nil
 }
 
+// BoolVariationDetail returns the value of a boolean feature flag for a given target together with
+// the reason it was served.
+//
+// Returns defaultValue if there is an error or if the flag doesn't exist, the error is set on the detail
+func (c *CfClient) BoolVariationDetail(key string, target *evaluation.Target,
+	defaultValue bool) (bool, evaluation.EvaluationDetail) {
+	return c.evaluator.BoolVariationDetail(key, target, defaultValue)
+}
+
+// StringVariationDetail returns the value of a string feature flag for a given target together with
+// the reason it was served.
+//
+// Returns defaultValue if there is an error or if the flag doesn't exist, the error is set on the detail
+func (c *CfClient) StringVariationDetail(key string, target *evaluation.Target,
+	defaultValue string) (string, evaluation.EvaluationDetail) {
+	return c.evaluator.StringVariationDetail(key, target, defaultValue)
+}
+
+// IntVariationDetail returns the value of a integer feature flag for a given target together with
+// the reason it was served.
+//
+// Returns defaultValue if there is an error or if the flag doesn't exist, the error is set on the detail
+func (c *CfClient) IntVariationDetail(key string, target *evaluation.Target,
+	defaultValue int64) (int64, evaluation.EvaluationDetail) {
+	value, detail := c.evaluator.IntVariationDetail(key, target, int(defaultValue))
+	return int64(value), detail
+}
+
+// NumberVariationDetail returns the value of a float64 feature flag for a given target together with
+// the reason it was served.
+//
+// Returns defaultValue if there is an error or if the flag doesn't exist, the error is set on the detail
+func (c *CfClient) NumberVariationDetail(key string, target *evaluation.Target,
+	defaultValue float64) (float64, evaluation.EvaluationDetail) {
+	return c.evaluator.NumberVariationDetail(key, target, defaultValue)
+}
+
+// JSONVariationDetail returns the value of a feature flag for the given target together with the
+// reason it was served.
+//
+// Returns defaultValue if there is an error or if the flag doesn't exist, the error is set on the detail
+func (c *CfClient) JSONVariationDetail(key string, target *evaluation.Target,
+	defaultValue types.JSON) (types.JSON, evaluation.EvaluationDetail) {
+	return c.evaluator.JSONVariationDetail(key, target, defaultValue)
+}
+
 // Close shuts down the Feature Flag client. After calling this, the client
 // should no longer be used
 func (c *CfClient) Close() error {
@@ -197,9 +197,58 @@ var FeatureConfigsResponse = func(req *http.Request) (*http.Response, error) {
 	FeatureConfigResponse = append(FeatureConfigResponse, MakeStringFeatureConfigs("TestStringAOnWithPreReqFalse", "Alpha", "Bravo", "on", MakeBoolPreRequisite("PreReq1", "false"))...)
 	FeatureConfigResponse = append(FeatureConfigResponse, MakeStringFeatureConfigs("TestStringAOnWithPreReqTrue", "Alpha", "Bravo", "on", MakeBoolPreRequisite("PreReq1", "true"))...)
 
+	FeatureConfigResponse = append(FeatureConfigResponse,
+		MakeRolloutFeatureConfig(MakeBoolFeatureConfig("TestBoolRollout", "true", "false", "on", nil), "true"))
+	FeatureConfigResponse = append(FeatureConfigResponse,
+		MakeRolloutFeatureConfig(MakeStringFeatureConfig("TestStringRollout", "Alpha", "Bravo", "on", nil), "Alpha"))
+
 	return httpmock.NewJsonResponse(200, FeatureConfigResponse)
 }
 
+// memoryStickyStore keeps sticky assignments in memory keyed by flag and target
+type memoryStickyStore map[string]string
+
+func (s memoryStickyStore) GetAssignment(flagIdentifier string, targetIdentifier string) (string, bool) {
+	variation, ok := s[flagIdentifier+"/"+targetIdentifier]
+	return variation, ok
+}
+
+func (s memoryStickyStore) SetAssignment(flagIdentifier string, targetIdentifier string, variation string) {
+	s[flagIdentifier+"/"+targetIdentifier] = variation
+}
+
+func TestCfClient_WithStickyStore(t *testing.T) {
+	// the rollouts serve Alpha and true to every target, john keeps the variations assigned before
+	store := memoryStickyStore{
+		"TestStringRollout/john": "Bravo",
+		"TestBoolRollout/john":   "false",
+	}
+	client, err := client.NewCfClient(sdkKey,
+		client.WithURL(URL),
+		client.WithStreamEnabled(false),
+		client.WithHTTPClient(http.DefaultClient),
+		client.WithStoreEnabled(false),
+		client.WithStickyStore(store),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := client.IsInitialized(); !ok {
+		t.Fatal(err)
+	}
+
+	john := target()
+	stringValue, _ := client.StringVariation("TestStringRollout", john, "foo")
+	assert.Equal(t, "B", stringValue, "john didn't get his stored string variation")
+	boolValue, _ := client.BoolVariation("TestBoolRollout", john, true)
+	assert.Equal(t, false, boolValue, "john didn't get his stored bool variation")
+
+	paul := &evaluation.Target{Identifier: "paul"}
+	stringValue, _ = client.StringVariation("TestStringRollout", paul, "foo")
+	assert.Equal(t, "A", stringValue, "paul didn't get the rollout variation")
+	assert.Equal(t, "Alpha", store["TestStringRollout/paul"], "paul's assignment wasn't stored")
+}
+
 func TestCfClient_Close(t *testing.T) {
 	client, err := newClient(&http.Client{})
 	if err != nil {
@@ -26,6 +26,7 @@ type config struct {
 	target              evaluation.Target
 	eventStreamListener stream.EventStreamListener
 	enableAnalytics     bool
+	stickyStore         evaluation.StickyStore
 }
 
 func newDefaultConfig() *config {
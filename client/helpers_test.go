@@ -107,6 +107,22 @@ func MakeStringFeatureConfig(name, defaultVariation, offVariation, state string,
 	}
 }
 
+// MakeRolloutFeatureConfig turns the flag into a rollout serving the variation to every target
+func MakeRolloutFeatureConfig(featureConfig rest.FeatureConfig, variation string) rest.FeatureConfig {
+	weighted := make([]rest.WeightedVariation, 0, len(featureConfig.Variations))
+	for _, v := range featureConfig.Variations {
+		weight := 0
+		if v.Identifier == variation {
+			weight = 100
+		}
+		weighted = append(weighted, rest.WeightedVariation{Variation: v.Identifier, Weight: weight})
+	}
+	featureConfig.DefaultServe = rest.Serve{
+		Distribution: &rest.Distribution{BucketBy: "identifier", Variations: weighted},
+	}
+	return featureConfig
+}
+
 func intPtr(value int64) *int64 {
 	return &value
 }
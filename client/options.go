@@ -104,3 +104,11 @@ func WithEventStreamListener(e stream.EventStreamListener) ConfigOption {
 		config.eventStreamListener = e
 	}
 }
+
+// WithStickyStore persists the variation each target is bucketed into by a percentage rollout in the
+// store, so targets keep their variation when the rollout weights change
+func WithStickyStore(store evaluation.StickyStore) ConfigOption {
+	return func(config *config) {
+		config.stickyStore = store
+	}
+}
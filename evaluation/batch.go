@@ -0,0 +1,67 @@
+package evaluation
+
+import (
+	"context"
+	"errors"
+)
+
+// StringVariationBatch evaluates the string flag for each of the targets and returns the values keyed
+// by target identifier, targets the flag fails to evaluate for get defaultValue. The flag and the
+// segments it references are fetched once for the whole batch.
+func (e Evaluator) StringVariationBatch(identifier string, targets []*Target, defaultValue string) map[string]string {
+	values := make(map[string]string, len(targets))
+	e.evaluateBatch(identifier, "string", targets, func(e *evaluationState, target *Target) error {
+		value, detail := e.stringVariation(identifier, target, defaultValue)
+		values[target.Identifier] = value
+		return detail.Error
+	})
+	return values
+}
+
+// BoolVariationBatch is like StringVariationBatch for boolean flags
+func (e Evaluator) BoolVariationBatch(identifier string, targets []*Target, defaultValue bool) map[string]bool {
+	values := make(map[string]bool, len(targets))
+	e.evaluateBatch(identifier, "boolean", targets, func(e *evaluationState, target *Target) error {
+		value, detail := e.boolVariation(identifier, target, defaultValue)
+		values[target.Identifier] = value
+		return detail.Error
+	})
+	return values
+}
+
+// IntVariationBatch is like StringVariationBatch for int flags
+func (e Evaluator) IntVariationBatch(identifier string, targets []*Target, defaultValue int) map[string]int {
+	values := make(map[string]int, len(targets))
+	e.evaluateBatch(identifier, "int", targets, func(e *evaluationState, target *Target) error {
+		value, detail := e.intVariation(identifier, target, defaultValue)
+		values[target.Identifier] = value
+		return detail.Error
+	})
+	return values
+}
+
+// evaluateBatch calls evaluate for every non nil target with an evaluation sharing the flags and
+// segments fetched for earlier targets, failures are logged once for the whole batch
+func (e Evaluator) evaluateBatch(identifier, kind string, targets []*Target,
+	evaluate func(e *evaluationState, target *Target) error) {
+	memo := newEvaluationMemo(nil)
+	failed := 0
+	var firstErr error
+	for _, target := range targets {
+		if target == nil {
+			continue
+		}
+		state := e.newEvaluation(context.Background())
+		state.memo = memo.forTarget()
+		if err := evaluate(state, target); err != nil && !errors.Is(err, ErrFlagDisabled) {
+			if failed == 0 {
+				firstErr = err
+			}
+			failed++
+		}
+	}
+	if failed > 0 {
+		e.logger.Errorf("Error while evaluating %s flag '%s' for %d of %d targets, err: %v", kind, identifier,
+			failed, len(targets), firstErr)
+	}
+}
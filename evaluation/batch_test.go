@@ -0,0 +1,92 @@
+package evaluation
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+// batchFlag serves true to the beta segment and false to everyone else
+var batchFlag = rest.FeatureConfig{
+	Feature: "batch",
+	State:   rest.FeatureStateOn,
+	Kind:    "boolean",
+	Rules: &[]rest.ServingRule{{
+		Clauses: []rest.Clause{{Op: segmentMatchOperator, Values: []string{beta}}},
+		Serve:   rest.Serve{Variation: &identifierTrue},
+	}},
+	DefaultServe: rest.Serve{Variation: &identifierFalse},
+	Variations:   boolVariations,
+}
+
+func batchTargets(n int) []*Target {
+	targets := make([]*Target, 0, n+1)
+	targets = append(targets, &Target{Identifier: harness})
+	for i := 0; i < n; i++ {
+		targets = append(targets, &Target{Identifier: "target" + strconv.Itoa(i)})
+	}
+	return targets
+}
+
+func TestEvaluator_VariationBatch(t *testing.T) {
+	query := countingQuery{
+		TestRepository: NewTestRepository(map[string]rest.FeatureConfig{
+			batchFlag.Feature: batchFlag,
+			theme:             testRepo.flags[theme],
+			size:              testRepo.flags[size],
+		}, testRepo.segments),
+		lookups: map[string]int{},
+	}
+	e, _ := NewEvaluator(query, nil, logger.NewNoOpLogger())
+	targets := append(batchTargets(2), nil)
+
+	got := e.BoolVariationBatch(batchFlag.Feature, targets, false)
+	want := map[string]bool{harness: true, "target0": false, "target1": false}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Evaluator.BoolVariationBatch() = %v, want %v", got, want)
+	}
+	if want := map[string]int{"flag " + batchFlag.Feature: 1, "segment " + beta: 1}; !reflect.DeepEqual(query.lookups, want) {
+		t.Errorf("lookups = %v, want %v", query.lookups, want)
+	}
+
+	themes := e.StringVariationBatch(theme, targets, darktheme)
+	if want := map[string]string{harness: lighttheme, "target0": lighttheme, "target1": lighttheme}; !reflect.DeepEqual(themes, want) {
+		t.Errorf("Evaluator.StringVariationBatch() = %v, want %v", themes, want)
+	}
+
+	ints := e.IntVariationBatch(size, targets, 0)
+	if len(ints) != 3 || ints[harness] != e.IntVariation(size, &Target{Identifier: harness}, 0) {
+		t.Errorf("Evaluator.IntVariationBatch() = %v, want the same values as IntVariation", ints)
+	}
+
+	defaults := e.StringVariationBatch("flagNotFound1000", targets, darktheme)
+	if want := map[string]string{harness: darktheme, "target0": darktheme, "target1": darktheme}; !reflect.DeepEqual(defaults, want) {
+		t.Errorf("Evaluator.StringVariationBatch() = %v, want %v", defaults, want)
+	}
+}
+
+func BenchmarkBoolVariationBatch(b *testing.B) {
+	e, _ := NewEvaluator(NewTestRepository(map[string]rest.FeatureConfig{batchFlag.Feature: batchFlag},
+		testRepo.segments), nil, logger.NewNoOpLogger())
+	targets := batchTargets(1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.BoolVariationBatch(batchFlag.Feature, targets, false)
+	}
+}
+
+func BenchmarkBoolVariationLoop(b *testing.B) {
+	e, _ := NewEvaluator(NewTestRepository(map[string]rest.FeatureConfig{batchFlag.Feature: batchFlag},
+		testRepo.segments), nil, logger.NewNoOpLogger())
+	targets := batchTargets(1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		values := make(map[string]bool, len(targets))
+		for _, target := range targets {
+			values[target.Identifier] = e.BoolVariation(batchFlag.Feature, target, false)
+		}
+	}
+}
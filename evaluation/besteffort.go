@@ -0,0 +1,49 @@
+package evaluation
+
+import (
+	"context"
+	"errors"
+
+	"github.com/harness/ff-golang-server-sdk/rest"
+
+	"go.uber.org/multierr"
+)
+
+// evaluationErrors collects the non fatal errors of a best effort evaluation. All methods are
+// safe to call on nil errors so other evaluations don't pay for it.
+type evaluationErrors struct {
+	errs []error
+}
+
+func (c *evaluationErrors) record(err error) {
+	if c != nil {
+		c.errs = append(c.errs, err)
+	}
+}
+
+func (c *evaluationErrors) combine() error {
+	if c == nil {
+		return nil
+	}
+	return multierr.Combine(c.errs...)
+}
+
+// EvaluateBestEffort is like Evaluate but doesn't abort on errors, segment and prerequisite lookups
+// which fail and clauses which can't be evaluated are collected, together with the evaluation error
+// if any, into the returned multi-error
+// whose individual errors multierr.Errors returns. defaultVariation is served when the flag can't be
+// evaluated at all.
+func (e Evaluator) EvaluateBestEffort(identifier string, target *Target, kind string,
+	defaultVariation rest.Variation) (rest.Variation, EvaluationReason, error) {
+	state := e.newEvaluation(context.Background())
+	state.errs = &evaluationErrors{}
+	variation, reason, err := state.evaluateWithReason(identifier, target, kind)
+	if err != nil {
+		// strict evaluations fail with the invalid clause which is already collected
+		if !errors.Is(err, ErrInvalidClause) {
+			state.errs.record(err)
+		}
+		variation = defaultVariation
+	}
+	return variation, reason, state.errs.combine()
+}
@@ -0,0 +1,122 @@
+package evaluation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/rest"
+
+	"go.uber.org/multierr"
+)
+
+func TestEvaluator_EvaluateBestEffort(t *testing.T) {
+	segmentFlag := rest.FeatureConfig{
+		Feature: "segmentFlag",
+		State:   rest.FeatureStateOn,
+		Kind:    "boolean",
+		Rules: &[]rest.ServingRule{{
+			Priority: 1,
+			Clauses:  []rest.Clause{{Op: segmentMatchOperator, Values: []string{"missingSegment"}}},
+			Serve:    rest.Serve{Variation: &identifierTrue},
+		}, {
+			Priority: 2,
+			Clauses:  []rest.Clause{{Op: segmentMatchOperator, Values: []string{beta}}},
+			Serve:    rest.Serve{Variation: &identifierTrue},
+		}},
+		DefaultServe: rest.Serve{Variation: &identifierFalse},
+		Variations:   boolVariations,
+	}
+	withPrerequisite := segmentFlag
+	withPrerequisite.Feature = "withPrerequisite"
+	withPrerequisite.Prerequisites = &[]rest.Prerequisite{{Feature: "missingPrereq", Variations: []string{identifierTrue}}}
+	clauseFlag := func(feature string, clause rest.Clause) rest.FeatureConfig {
+		return rest.FeatureConfig{
+			Feature: feature,
+			State:   rest.FeatureStateOn,
+			Kind:    "boolean",
+			Rules: &[]rest.ServingRule{{
+				Clauses: []rest.Clause{clause},
+				Serve:   rest.Serve{Variation: &identifierTrue},
+			}},
+			DefaultServe: rest.Serve{Variation: &identifierFalse},
+			Variations:   boolVariations,
+		}
+	}
+	flags := map[string]rest.FeatureConfig{
+		segmentFlag.Feature:      segmentFlag,
+		withPrerequisite.Feature: withPrerequisite,
+		theme:                    testRepo.flags[theme],
+		"noValues":               clauseFlag("noValues", rest.Clause{Attribute: identifier, Op: equalOperator}),
+		"invalidPattern": clauseFlag("invalidPattern",
+			rest.Clause{Attribute: identifier, Op: matchOperator, Values: []string{"[harness"}}),
+	}
+	e, _ := NewEvaluator(NewTestRepository(flags, testRepo.segments), nil, logger.NewNoOpLogger())
+	fallback := rest.Variation{Identifier: "fallback", Value: "fallback"}
+
+	tests := []struct {
+		name          string
+		identifier    string
+		target        *Target
+		wantVariation string
+		wantErrs      []error
+	}{
+		{name: "segment lookup fails", identifier: segmentFlag.Feature, target: &Target{Identifier: harness},
+			wantVariation: identifierTrue, wantErrs: []error{ErrSegmentReference}},
+		{name: "segment and prerequisite lookups fail", identifier: withPrerequisite.Feature,
+			target: &Target{Identifier: "outsider"}, wantVariation: identifierFalse,
+			wantErrs: []error{ErrPrerequisiteUnavailable, ErrSegmentReference}},
+		{name: "clause without values", identifier: "noValues", target: &Target{Identifier: harness},
+			wantVariation: identifierFalse, wantErrs: []error{ErrInvalidClause}},
+		{name: "malformed match pattern", identifier: "invalidPattern", target: &Target{Identifier: harness},
+			wantVariation: identifierFalse, wantErrs: []error{ErrInvalidClause}},
+		{name: "flag can't be evaluated", identifier: "flagNotFound1000", target: &Target{Identifier: harness},
+			wantVariation: fallback.Identifier, wantErrs: []error{nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variation, _, err := e.EvaluateBestEffort(tt.identifier, tt.target, "boolean", fallback)
+			if variation.Identifier != tt.wantVariation {
+				t.Errorf("Evaluator.EvaluateBestEffort() variation = %v, want %v", variation.Identifier, tt.wantVariation)
+			}
+			errs := multierr.Errors(err)
+			if len(errs) != len(tt.wantErrs) {
+				t.Fatalf("Evaluator.EvaluateBestEffort() errors = %v, want %d", errs, len(tt.wantErrs))
+			}
+			for i, want := range tt.wantErrs {
+				if want != nil && !errors.Is(errs[i], want) {
+					t.Errorf("Evaluator.EvaluateBestEffort() error %d = %v, want %v", i, errs[i], want)
+				}
+			}
+		})
+	}
+
+	if _, _, err := e.EvaluateBestEffort(theme, &Target{Identifier: harness}, "string", fallback); err != nil {
+		t.Errorf("Evaluator.EvaluateBestEffort() error = %v, want none", err)
+	}
+}
+
+func TestEvaluator_EvaluateBestEffortStrict(t *testing.T) {
+	flag := rest.FeatureConfig{
+		Feature: "unknownOperator",
+		State:   rest.FeatureStateOn,
+		Kind:    "boolean",
+		Rules: &[]rest.ServingRule{{
+			Clauses: []rest.Clause{{Attribute: identifier, Op: "statswith", Values: []string{"har"}}},
+			Serve:   rest.Serve{Variation: &identifierTrue},
+		}},
+		DefaultServe: rest.Serve{Variation: &identifierFalse},
+		Variations:   boolVariations,
+	}
+	repo := NewTestRepository(map[string]rest.FeatureConfig{flag.Feature: flag}, nil)
+	e, _ := NewEvaluator(repo, nil, logger.NewNoOpLogger(), WithStrictMode(true))
+	fallback := rest.Variation{Identifier: "fallback", Value: "fallback"}
+
+	variation, _, err := e.EvaluateBestEffort(flag.Feature, &Target{Identifier: harness}, "boolean", fallback)
+	if variation.Identifier != fallback.Identifier {
+		t.Errorf("Evaluator.EvaluateBestEffort() variation = %v, want %v", variation.Identifier, fallback.Identifier)
+	}
+	if errs := multierr.Errors(err); len(errs) != 1 || !errors.Is(errs[0], ErrInvalidClause) {
+		t.Errorf("Evaluator.EvaluateBestEffort() errors = %v, want a single %v", errs, ErrInvalidClause)
+	}
+}
@@ -0,0 +1,73 @@
+package evaluation
+
+import (
+	"sync"
+
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+const (
+	weightsCheck                = "weights"
+	firstVariationFallbackCheck = "firstVariationFallback"
+	emptyDefaultServeCheck      = "emptyDefaultServe"
+	fallthroughCheck            = "fallthrough"
+)
+
+// flagCheck identifies a check done for a flag, such as validating its distribution weights
+type flagCheck struct {
+	name string
+	flag string
+}
+
+// flagChecks remembers the flag versions each check was done for so that misconfigured flags are
+// logged once per flag version rather than on every evaluation. It is safe for concurrent use and
+// all methods are safe to call on nil checks, checks are then done on every evaluation.
+type flagChecks struct {
+	mu       sync.Mutex
+	versions map[flagCheck]int64
+}
+
+func newFlagChecks() *flagChecks {
+	return &flagChecks{versions: make(map[flagCheck]int64)}
+}
+
+// first reports whether the named check is done for the current version of the flag for the
+// first time
+func (c *flagChecks) first(name string, flag rest.FeatureConfig) bool {
+	if c == nil {
+		return true
+	}
+	var version int64
+	if flag.Version != nil {
+		version = *flag.Version
+	}
+	key := flagCheck{name: name, flag: flag.Feature}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if checked, ok := c.versions[key]; ok && checked == version {
+		return false
+	}
+	c.versions[key] = version
+	return true
+}
+
+// forget drops the versions every check was done for the flag
+func (c *flagChecks) forget(identifier string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.versions {
+		if key.flag == identifier {
+			delete(c.versions, key)
+		}
+	}
+}
+
+// warnOnce logs the warning about the flag once per flag version and check
+func (e Evaluator) warnOnce(check string, flag rest.FeatureConfig, template string, args ...interface{}) {
+	if e.checks.first(check, flag) {
+		e.logger.Warnf(template, args...)
+	}
+}
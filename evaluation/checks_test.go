@@ -0,0 +1,73 @@
+package evaluation
+
+import (
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+func TestEvaluator_warnOnce(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []EvaluatorOption
+	}{
+		{
+			name:    "first variation fallback",
+			options: []EvaluatorOption{WithFirstVariationFallback(true)},
+		},
+		{
+			name:    "empty default serve off variation",
+			options: []EvaluatorOption{WithEmptyDefaultServe(EmptyDefaultServeOff, "")},
+		},
+		{
+			name:    "empty default serve fallback variation",
+			options: []EvaluatorOption{WithEmptyDefaultServe(EmptyDefaultServeFallback, identifierTrue)},
+		},
+		{
+			name:    "fallthrough variation",
+			options: []EvaluatorOption{WithFallthroughVariations(map[rest.FeatureConfigKind]string{"boolean": identifierTrue})},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version := int64(1)
+			flag := rest.FeatureConfig{
+				Feature:      "emptyDefaultServe",
+				State:        rest.FeatureStateOn,
+				Kind:         "boolean",
+				Version:      &version,
+				OffVariation: identifierFalse,
+				Variations:   boolVariations,
+			}
+			flags := map[string]rest.FeatureConfig{flag.Feature: flag}
+			recorder := &warnRecorder{}
+			e, _ := NewEvaluator(NewTestRepository(flags, nil), nil, recorder, tt.options...)
+			target := &Target{Identifier: harness}
+
+			for i := 0; i < 3; i++ {
+				e.BoolVariation(flag.Feature, target, false)
+			}
+			if len(recorder.warnings) != 1 {
+				t.Fatalf("logged %d warnings for a single flag version, want 1: %v", len(recorder.warnings),
+					recorder.warnings)
+			}
+
+			next := int64(2)
+			flag.Version = &next
+			flags[flag.Feature] = flag
+			e.BoolVariation(flag.Feature, target, false)
+			e.BoolVariation(flag.Feature, target, false)
+			if len(recorder.warnings) != 2 {
+				t.Errorf("logged %d warnings after a new flag version, want 2: %v", len(recorder.warnings),
+					recorder.warnings)
+			}
+
+			e.OnFlagDeleted(flag.Feature)
+			e.BoolVariation(flag.Feature, target, false)
+			if len(recorder.warnings) != 3 {
+				t.Errorf("logged %d warnings after the flag was deleted, want 3: %v", len(recorder.warnings),
+					recorder.warnings)
+			}
+		})
+	}
+}
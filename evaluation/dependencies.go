@@ -0,0 +1,105 @@
+package evaluation
+
+import "sync"
+
+// dependencyKind identifies the evaluator cache a dependency is held in
+type dependencyKind int
+
+const (
+	patternDependency dependencyKind = iota
+	scheduleDependency
+)
+
+// dependency is a value the evaluator caches on behalf of flag or segment rules, such as a match
+// pattern or a schedule window
+type dependency struct {
+	kind  dependencyKind
+	value string
+}
+
+// dependencyOwner is the flag or segment whose rules use a dependency
+type dependencyOwner struct {
+	segment    bool
+	identifier string
+}
+
+// cacheDependencies records which flags and segments use the values cached by the evaluator, so
+// storing or deleting a flag or segment only evicts the values no other flag or segment uses. It
+// is safe for concurrent use and all methods are safe to call on a nil cacheDependencies.
+type cacheDependencies struct {
+	mu     sync.Mutex
+	owners map[dependencyOwner]map[dependency]struct{}
+	users  map[dependency]map[dependencyOwner]struct{}
+}
+
+func newCacheDependencies() *cacheDependencies {
+	return &cacheDependencies{
+		owners: make(map[dependencyOwner]map[dependency]struct{}),
+		users:  make(map[dependency]map[dependencyOwner]struct{}),
+	}
+}
+
+// record remembers that the owner uses the value
+func (d *cacheDependencies) record(owner dependencyOwner, value dependency) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.owners[owner][value]; ok {
+		return
+	}
+	if d.owners[owner] == nil {
+		d.owners[owner] = make(map[dependency]struct{})
+	}
+	d.owners[owner][value] = struct{}{}
+	if d.users[value] == nil {
+		d.users[value] = make(map[dependencyOwner]struct{})
+	}
+	d.users[value][owner] = struct{}{}
+}
+
+// forget drops the values the owner used and returns those no other owner uses anymore
+func (d *cacheDependencies) forget(owner dependencyOwner) []dependency {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var unused []dependency
+	for value := range d.owners[owner] {
+		delete(d.users[value], owner)
+		if len(d.users[value]) == 0 {
+			delete(d.users, value)
+			unused = append(unused, value)
+		}
+	}
+	delete(d.owners, owner)
+	return unused
+}
+
+// recordDependency records that the rules being evaluated use the cached value, values used by
+// segment rules belong to the segments on the segment path and other values to the flag
+func (e *evaluationState) recordDependency(kind dependencyKind, value string) {
+	if len(e.segmentPath) > 0 {
+		for segment := range e.segmentPath {
+			e.dependencies.record(dependencyOwner{segment: true, identifier: segment}, dependency{kind, value})
+		}
+		return
+	}
+	if e.feature != "" {
+		e.dependencies.record(dependencyOwner{identifier: e.feature}, dependency{kind, value})
+	}
+}
+
+// invalidate evicts the cached values which only the flag or segment used
+func (e Evaluator) invalidate(owner dependencyOwner) {
+	for _, value := range e.dependencies.forget(owner) {
+		switch value.kind {
+		case patternDependency:
+			e.regexes.evict(value.value)
+		case scheduleDependency:
+			e.schedules.evictWindow(value.value)
+		}
+	}
+}
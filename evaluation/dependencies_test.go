@@ -0,0 +1,36 @@
+package evaluation
+
+import "testing"
+
+func Test_cacheDependencies(t *testing.T) {
+	flag := dependencyOwner{identifier: "flag"}
+	other := dependencyOwner{identifier: "other"}
+	segment := dependencyOwner{segment: true, identifier: "flag"}
+	shared := dependency{kind: patternDependency, value: "^shared$"}
+	own := dependency{kind: scheduleDependency, value: "mon-fri 09:00-17:00"}
+
+	d := newCacheDependencies()
+	d.record(flag, shared)
+	d.record(flag, own)
+	d.record(other, shared)
+	d.record(segment, own)
+
+	if unused := d.forget(flag); len(unused) != 0 {
+		t.Errorf("cacheDependencies.forget() = %v, want values still used elsewhere kept", unused)
+	}
+	if unused := d.forget(other); len(unused) != 1 || unused[0] != shared {
+		t.Errorf("cacheDependencies.forget() = %v, want [%v]", unused, shared)
+	}
+	if unused := d.forget(segment); len(unused) != 1 || unused[0] != own {
+		t.Errorf("cacheDependencies.forget() = %v, want [%v]", unused, own)
+	}
+	if unused := d.forget(flag); len(unused) != 0 {
+		t.Errorf("cacheDependencies.forget() for a forgotten owner = %v, want none", unused)
+	}
+
+	var nilDependencies *cacheDependencies
+	nilDependencies.record(flag, shared)
+	if unused := nilDependencies.forget(flag); unused != nil {
+		t.Errorf("cacheDependencies.forget() on nil = %v, want nil", unused)
+	}
+}
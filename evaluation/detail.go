@@ -0,0 +1,72 @@
+package evaluation
+
+import (
+	"context"
+
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+// EvaluationDetail tells why a variation method returned its value
+type EvaluationDetail struct {
+	// Reason is the decision path of the evaluation, its RuleID identifies the matched rule when
+	// Reason.Kind is ReasonRuleMatch
+	Reason EvaluationReason
+	// VariationIdentifier identifies the served variation, it is empty when the default value was returned
+	VariationIdentifier string
+	// Error is why the default value was returned, or ErrFlagDisabled when the off variation of a
+	// flag which is turned off was returned. A PrerequisiteError is returned with the off variation
+	// when the prerequisites of the flag form a cycle or are nested too deep.
+	Error error
+}
+
+// servedDetail describes an evaluation which returned the value of the variation
+func servedDetail(identifier string, variation rest.Variation, reason EvaluationReason) EvaluationDetail {
+	return EvaluationDetail{
+		Reason:              reason,
+		VariationIdentifier: variation.Identifier,
+		Error:               servedErr(identifier, reason),
+	}
+}
+
+// servedErr is the error returned together with a served variation
+func servedErr(identifier string, reason EvaluationReason) error {
+	if err := disabledErr(identifier, reason); err != nil {
+		return err
+	}
+	return prerequisiteErr(identifier, reason)
+}
+
+// parseErrorDetail describes an evaluation which returned the default value because the value of the
+// served variation couldn't be parsed
+func parseErrorDetail(err error) EvaluationDetail {
+	return EvaluationDetail{Reason: newEvaluationReason(ReasonParseError), Error: err}
+}
+
+// BoolVariationDetail is like BoolVariation but also returns why the value was returned, errors are
+// reported on the detail rather than logged
+func (e Evaluator) BoolVariationDetail(identifier string, target *Target, defaultValue bool) (bool, EvaluationDetail) {
+	return e.newEvaluation(context.Background()).boolVariation(identifier, target, defaultValue)
+}
+
+// StringVariationDetail is like StringVariation but also returns why the value was returned
+func (e Evaluator) StringVariationDetail(identifier string, target *Target,
+	defaultValue string) (string, EvaluationDetail) {
+	return e.newEvaluation(context.Background()).stringVariation(identifier, target, defaultValue)
+}
+
+// IntVariationDetail is like IntVariation but also returns why the value was returned
+func (e Evaluator) IntVariationDetail(identifier string, target *Target, defaultValue int) (int, EvaluationDetail) {
+	return e.newEvaluation(context.Background()).intVariation(identifier, target, defaultValue)
+}
+
+// NumberVariationDetail is like NumberVariation but also returns why the value was returned
+func (e Evaluator) NumberVariationDetail(identifier string, target *Target,
+	defaultValue float64) (float64, EvaluationDetail) {
+	return e.newEvaluation(context.Background()).numberVariation(identifier, target, defaultValue)
+}
+
+// JSONVariationDetail is like JSONVariation but also returns why the value was returned
+func (e Evaluator) JSONVariationDetail(identifier string, target *Target,
+	defaultValue map[string]interface{}) (map[string]interface{}, EvaluationDetail) {
+	return e.newEvaluation(context.Background()).jsonVariation(identifier, target, defaultValue)
+}
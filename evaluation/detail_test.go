@@ -0,0 +1,107 @@
+package evaluation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+func TestEvaluator_VariationDetail(t *testing.T) {
+	repo := NewTestRepository(
+		map[string]rest.FeatureConfig{
+			simple:            testRepo.flags[simple],
+			prereqVarNotFound: testRepo.flags[prereqVarNotFound],
+			"off": {
+				Feature:      "off",
+				State:        rest.FeatureStateOff,
+				Kind:         "boolean",
+				OffVariation: identifierFalse,
+				Variations:   boolVariations,
+			},
+			theme: {
+				Feature: theme,
+				State:   rest.FeatureStateOn,
+				Kind:    "string",
+				Rules: &[]rest.ServingRule{
+					{
+						RuleId:   "harness2",
+						Priority: 1,
+						Clauses: []rest.Clause{
+							{Attribute: identifier, Op: equalOperator, Values: []string{harness2}},
+						},
+						Serve: rest.Serve{Variation: &darktheme},
+					},
+				},
+				DefaultServe: rest.Serve{Variation: &lighttheme},
+				Variations:   stringVariations,
+			},
+			"seats": {
+				Feature:      "seats",
+				State:        rest.FeatureStateOn,
+				Kind:         "int",
+				DefaultServe: rest.Serve{Variation: &harness1},
+				Variations:   []rest.Variation{{Identifier: harness1, Value: "many"}},
+			},
+		},
+		testRepo.segments,
+	)
+	e, _ := NewEvaluator(repo, nil, logger.NewNoOpLogger())
+
+	t.Run("rule match", func(t *testing.T) {
+		value, detail := e.StringVariationDetail(theme, &Target{Identifier: harness2}, "none")
+		if value != darktheme || detail.VariationIdentifier != darktheme || detail.Error != nil {
+			t.Errorf("Evaluator.StringVariationDetail() = %v, %+v, want %v served without error", value, detail, darktheme)
+		}
+		if detail.Reason.Kind != ReasonRuleMatch || detail.Reason.RuleID != "harness2" {
+			t.Errorf("Evaluator.StringVariationDetail() reason = %+v, want rule harness2 matched", detail.Reason)
+		}
+	})
+
+	t.Run("default serve", func(t *testing.T) {
+		value, detail := e.StringVariationDetail(theme, &Target{Identifier: harness}, "none")
+		if value != lighttheme || detail.Reason.Kind != ReasonDefault || detail.VariationIdentifier != lighttheme {
+			t.Errorf("Evaluator.StringVariationDetail() = %v, %+v, want %v served by default", value, detail, lighttheme)
+		}
+	})
+
+	t.Run("flag is off", func(t *testing.T) {
+		value, detail := e.BoolVariationDetail("off", &Target{Identifier: harness}, true)
+		if value || detail.Reason.Kind != ReasonOff || detail.VariationIdentifier != identifierFalse {
+			t.Errorf("Evaluator.BoolVariationDetail() = %v, %+v, want the off variation", value, detail)
+		}
+		if !errors.Is(detail.Error, ErrFlagDisabled) {
+			t.Errorf("Evaluator.BoolVariationDetail() error = %v, want %v", detail.Error, ErrFlagDisabled)
+		}
+	})
+
+	t.Run("prerequisite failed", func(t *testing.T) {
+		_, detail := e.BoolVariationDetail(prereqVarNotFound, &Target{Identifier: harness}, true)
+		if detail.Reason.Kind != ReasonPrerequisiteFailed || detail.Reason.Prerequisite == "" {
+			t.Errorf("Evaluator.BoolVariationDetail() reason = %+v, want a failed prerequisite", detail.Reason)
+		}
+	})
+
+	t.Run("missing flag", func(t *testing.T) {
+		value, detail := e.NumberVariationDetail("missing", &Target{Identifier: harness}, 1.5)
+		if value != 1.5 || detail.Reason.Kind != ReasonError || detail.VariationIdentifier != "" || detail.Error == nil {
+			t.Errorf("Evaluator.NumberVariationDetail() = %v, %+v, want the default value with an error", value, detail)
+		}
+	})
+
+	t.Run("kind mismatch", func(t *testing.T) {
+		value, detail := e.JSONVariationDetail(theme, &Target{Identifier: harness}, nil)
+		if value != nil || !errors.Is(detail.Error, ErrFlagKindMismatch) {
+			t.Errorf("Evaluator.JSONVariationDetail() = %v, %+v, want %v", value, detail, ErrFlagKindMismatch)
+		}
+	})
+
+	t.Run("value can't be parsed", func(t *testing.T) {
+		value, detail := e.IntVariationDetail("seats", &Target{Identifier: harness}, 10)
+		if value != 10 || detail.Reason.Kind != ReasonParseError || !errors.Is(detail.Error, ErrInvalidVariationValue) {
+			t.Errorf("Evaluator.IntVariationDetail() = %v, %+v, want the default value with %v", value, detail,
+				ErrInvalidVariationValue)
+		}
+	})
+}
@@ -11,4 +11,32 @@ var (
 	ErrEvaluationFlag = errors.New("error while evaluating flag")
 	// ErrFlagKindMismatch ...
 	ErrFlagKindMismatch = errors.New("flag kind mismatch")
+	// ErrFlagListingUnsupported ...
+	ErrFlagListingUnsupported = errors.New("query provider does not support listing flags")
+	// ErrVariationTooLarge ...
+	ErrVariationTooLarge = errors.New("variation value exceeds the size limit")
+	// ErrInvalidVariationValue ...
+	ErrInvalidVariationValue = errors.New("variation value doesn't match the flag kind")
+	// ErrVariationMismatch ...
+	ErrVariationMismatch = errors.New("served variation differs from the expected variation")
+	// ErrSegmentReference ...
+	ErrSegmentReference = errors.New("segment reference doesn't resolve")
+	// ErrInvalidOperator ...
+	ErrInvalidOperator = errors.New("invalid custom operator")
+	// ErrFlagDisabled ...
+	ErrFlagDisabled = errors.New("flag is turned off")
+	// ErrInvalidReasonMessage ...
+	ErrInvalidReasonMessage = errors.New("invalid reason message template")
+	// ErrVariationParserMissing ...
+	ErrVariationParserMissing = errors.New("no variation parser registered for the flag kind")
+	// ErrFlagReference ...
+	ErrFlagReference = errors.New("flag reference doesn't resolve")
+	// ErrInvalidClause ...
+	ErrInvalidClause = errors.New("invalid clause")
+	// ErrPrerequisiteUnavailable ...
+	ErrPrerequisiteUnavailable = errors.New("prerequisite flag couldn't be retrieved or evaluated")
+	// ErrPrerequisiteCycle ...
+	ErrPrerequisiteCycle = errors.New("prerequisite flags form a cycle")
+	// ErrPrerequisiteDepth ...
+	ErrPrerequisiteDepth = errors.New("prerequisite flags are nested too deep")
 )
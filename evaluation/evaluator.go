@@ -1,15 +1,18 @@
 package evaluation
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
-	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/pkg/repository"
 
 	"github.com/harness/ff-golang-server-sdk/rest"
 )
@@ -17,15 +20,101 @@ import (
 const (
 	oneHundred = 100
 
-	segmentMatchOperator   = "segmentMatch"
-	matchOperator          = "match"
-	inOperator             = "in"
-	equalOperator          = "equal"
+	segmentMatchOperator = "segmentMatch"
+	matchOperator        = "match"
+	inOperator           = "in"
+	notInOperator        = "not_in"
+	equalOperator        = "equal"
+	notEqualOperator     = "not_equal"
+	// gt, gte, lt and lte compare the target attribute against the first clause value,
+	// numerically when both parse as numbers and lexicographically otherwise
 	gtOperator             = "gt"
+	gteOperator            = "gte"
+	ltOperator             = "lt"
+	lteOperator            = "lte"
 	startsWithOperator     = "starts_with"
 	endsWithOperator       = "ends_with"
 	containsOperator       = "contains"
 	equalSensitiveOperator = "equal_sensitive"
+	globAnyCIOperator      = "glob_any_ci"
+	floatEqualOperator     = "float_equal"
+	// stage_in matches when the current rollout stage of the evaluator is one of the clause
+	// values, the clause attribute is ignored
+	stageInOperator = "stage_in"
+	// exists matches when the target has a non empty clause attribute, clause values are ignored
+	existsOperator = "exists"
+	// fuzzy_in matches when the attribute is within an edit (Levenshtein) distance of any clause
+	// value, ignoring case. The first clause value is the maximum distance and the remaining
+	// values are the candidates. It is considerably slower than the other operators so should
+	// only be used where typo tolerance is really needed.
+	fuzzyInOperator = "fuzzy_in"
+	// hashed_equal matches when the target attribute, which the caller has already hashed, equals
+	// any clause value hashed with the evaluator's attribute hasher. Without a hasher the clause
+	// values are expected to be hashed already.
+	hashedEqualOperator = "hashed_equal"
+	// semver_gt, semver_lt and semver_equal compare the target attribute against the first clause
+	// value as semantic versions, they don't match when either fails to parse
+	semverGtOperator    = "semver_gt"
+	semverLtOperator    = "semver_lt"
+	semverEqualOperator = "semver_equal"
+	// semver_satisfies matches when the target attribute satisfies any of the npm style version
+	// constraints in the clause values, for example [">=1.2.0 <2.0.0 || 3.x"]
+	semverSatisfiesOperator = "semver_satisfies"
+	// allow_deny matches when the attribute equals any clause value and none of the clause values
+	// prefixed with "!", for example ["admin", "!suspended"]
+	allowDenyOperator = "allow_deny"
+	// before and after compare the target attribute against the first clause value chronologically,
+	// both are parsed as RFC3339 timestamps (2024-01-01T00:00:00Z) or Unix epoch seconds (1704067200)
+	// or milliseconds (1704067200000) and don't match when either fails to parse. A clause value of
+	// "now" is the current time of the evaluator clock, for example trialEnd after ["now"]
+	beforeOperator = "before"
+	afterOperator  = "after"
+	nowValue       = "now"
+	// ip_in_cidr and ip_in_cidr_any match when the ip address attribute falls within any of the
+	// clause CIDR ranges, for example ["10.0.0.0/8", "2001:db8::/32"], invalid ranges are skipped
+	ipInCIDROperator    = "ip_in_cidr"
+	ipInCIDRAnyOperator = "ip_in_cidr_any"
+	// attr_equal matches when the attribute equals the attribute named by the first clause value,
+	// for example billingCountry attr_equal ["shippingCountry"], ignoring case unless
+	// attr_equal_sensitive is used. Neither matches when either attribute is missing
+	attrEqualOperator          = "attr_equal"
+	attrEqualSensitiveOperator = "attr_equal_sensitive"
+	// schedule matches when the current time falls within any of the clause windows, for example
+	// ["mon-fri 09:00-17:00", "sat 10:00-14:00"], in the time zone named by the optional clause
+	// attribute or the evaluator location. Windows such as "fri 22:00-02:00" span midnight
+	scheduleOperator = "schedule"
+	// starts_with_i, ends_with_i and contains_i are like starts_with, ends_with and contains but
+	// ignore case and surrounding whitespace of both the attribute and the clause value
+	startsWithIOperator = "starts_with_i"
+	endsWithIOperator   = "ends_with_i"
+	containsIOperator   = "contains_i"
+	// sampled matches a stable percentage of targets, given by the first clause value, bucketed by a
+	// hash of the attribute and the optional sample key in the second clause value, for example
+	// identifier sampled ["10", "telemetry"]. The sample key keeps sampling independent of the
+	// flag's own rollout
+	sampledOperator = "sampled"
+	// segment_and_attr matches when the target is in the segment named by the first clause value and
+	// the attribute satisfies the operator in the second clause value against the remaining values,
+	// for example country segment_and_attr ["beta", "in", "US", "CA"]
+	segmentAndAttrOperator = "segment_and_attr"
+	// weighted_bucket assigns the target to one of the buckets in the clause values after the first,
+	// given as "name:weight", by a hash of the attribute and matches when it is assigned the bucket
+	// named by the first clause value, for example identifier weighted_bucket ["b", "a:50", "b:30", "c:20"]
+	weightedBucketOperator = "weighted_bucket"
+	// in_list matches when the attribute is in any of the lists named by the clause values, the
+	// lists are loaded on demand from the ListProvider configured with WithListProvider
+	inListOperator = "in_list"
+	// not_match and not_contains are match and contains with their result inverted, like a clause
+	// with Negate set
+	notMatchOperator    = "not_match"
+	notContainsOperator = "not_contains"
+
+	defaultFloatEpsilon = 1e-9
+	// maxSegmentDepth limits how deep segments may reference other segments
+	maxSegmentDepth = 16
+	// defaultMaxPrerequisiteDepth limits how deep prerequisites may require other prerequisites
+	// unless WithMaxPrerequisiteDepth is configured
+	defaultMaxPrerequisiteDepth = 16
 )
 
 // Query provides methods for segment and flag retrieval
@@ -34,6 +123,19 @@ type Query interface {
 	GetFlag(identifier string) (rest.FeatureConfig, error)
 }
 
+// ContextQuery is an optional interface implemented by Query providers which support
+// cancellation and deadlines while retrieving segments and flags
+type ContextQuery interface {
+	GetSegmentWithContext(ctx context.Context, identifier string) (rest.Segment, error)
+	GetFlagWithContext(ctx context.Context, identifier string) (rest.FeatureConfig, error)
+}
+
+// FlagLister is an optional interface implemented by Query providers
+// which are able to enumerate all flags
+type FlagLister interface {
+	GetFlags() ([]rest.FeatureConfig, error)
+}
+
 // PostEvalData holds information for post evaluation processing
 type PostEvalData struct {
 	FeatureConfig *rest.FeatureConfig
@@ -47,139 +149,942 @@ type PostEvaluateCallback interface {
 	PostEvaluateProcessor(data *PostEvalData)
 }
 
+// MetricsCallback is notified of every evaluation, unlike PostEvaluateCallback also of those that
+// failed, for example because the flag wasn't found or is of another kind, and served the caller's
+// default value. The variation is nil when err is not.
+type MetricsCallback interface {
+	RecordEvaluation(flagIdentifier string, target *Target, variation *rest.Variation, err error)
+}
+
+// EmptyDefaultServePolicy decides what is served when no target mapping or rule matched and the
+// default serve of the flag has neither a variation nor a distribution. Empty default serves are
+// resolved by the policy first, then by WithFirstVariationFallback and last by WithFallthroughVariations,
+// each only applies when the previous ones resolved to no variation.
+type EmptyDefaultServePolicy int
+
+const (
+	// EmptyDefaultServeFail fails the evaluation so the caller's default value is served
+	EmptyDefaultServeFail EmptyDefaultServePolicy = iota
+	// EmptyDefaultServeOff serves the off variation of the flag
+	EmptyDefaultServeOff
+	// EmptyDefaultServeFallback serves the fallback variation configured with WithEmptyDefaultServe
+	EmptyDefaultServeFallback
+)
+
+// MissingAttributePolicy decides how clauses are evaluated when the target lacks the clause attribute
+type MissingAttributePolicy int
+
+const (
+	// MissingAttributeFail makes the clause false
+	MissingAttributeFail MissingAttributePolicy = iota
+	// MissingAttributePass makes the clause true, for optional attributes
+	MissingAttributePass
+	// MissingAttributeSkip leaves the clause out of its rule, a rule whose clauses are all
+	// skipped doesn't match
+	MissingAttributeSkip
+)
+
+// MixedComparisonPolicy decides how the gt, gte, lt and lte operators compare a numeric value
+// with a non numeric one
+type MixedComparisonPolicy int
+
+const (
+	// MixedComparisonLexicographic compares both values as strings
+	MixedComparisonLexicographic MixedComparisonPolicy = iota
+	// MixedComparisonNumericFirst orders numeric values before any non numeric value
+	MixedComparisonNumericFirst
+	// MixedComparisonFail makes the clause false
+	MixedComparisonFail
+)
+
+// InvalidPatternPolicy decides how match clauses are evaluated when their pattern is not a valid
+// regular expression
+type InvalidPatternPolicy int
+
+const (
+	// InvalidPatternFail makes the clause false
+	InvalidPatternFail InvalidPatternPolicy = iota
+	// InvalidPatternPass makes the clause true so a malformed pattern doesn't exclude every target
+	InvalidPatternPass
+)
+
+// PrerequisiteErrorPolicy decides how prerequisites which can't be retrieved or evaluated are treated
+type PrerequisiteErrorPolicy int
+
+const (
+	// PrerequisiteErrorPass treats the prerequisite as satisfied, failing open
+	PrerequisiteErrorPass PrerequisiteErrorPolicy = iota
+	// PrerequisiteErrorFail treats the prerequisite as unmet so the off variation is served, failing closed
+	PrerequisiteErrorFail
+)
+
 // Evaluator engine evaluates flag from provided query
 type Evaluator struct {
-	query            Query
-	postEvalCallback PostEvaluateCallback
-	logger           logger.Logger
+	query                  Query
+	postEvalCallback       PostEvaluateCallback
+	logger                 logger.Logger
+	firstVariationFallback bool
+	clauseStatistics       *ClauseStatistics
+	floatEpsilon           float64
+	stickyStore            StickyStore
+	stage                  string
+	maxJSONVariationSize   int
+	emptyDefaultServe      EmptyDefaultServePolicy
+	emptyDefaultFallback   string
+	attributeHasher        func(value string) string
+	attributeTransforms    map[string][]AttributeTransform
+	operators              *operatorRegistry
+	operatorAliases        map[string]string
+	missingAttribute       MissingAttributePolicy
+	metricsCallback        MetricsCallback
+	numericLocale          NumericLocale
+	maxAttributeLength     int
+	clock                  func() time.Time
+	location               *time.Location
+	fallthroughVariations  map[rest.FeatureConfigKind]string
+	mixedComparison        MixedComparisonPolicy
+	variationParsers       map[string]VariationParser
+	invalidPattern         InvalidPatternPolicy
+	tracer                 Tracer
+	strict                 bool
+	lastKnown              *lastKnownCache
+	prerequisiteError      PrerequisiteErrorPolicy
+	lists                  *listCache
+	regexes                *regexCache
+	bucketBy               string
+	maxPrerequisiteDepth   int
+	checks                 *flagChecks
+	schedules              *scheduleCache
+	dependencies           *cacheDependencies
+}
+
+// evaluationState holds the state of a single evaluation, it is created by newEvaluation and
+// passed down the call chain so the Evaluator itself only carries configuration
+type evaluationState struct {
+	Evaluator
+
+	feature       string
+	ctx           context.Context
+	trace         *evaluationTrace
+	reason        *EvaluationReason
+	segment       *rest.Segment
+	memo          *evaluationMemo
+	prerequisites *prerequisiteChain
+	// segments on the current segmentMatch path
+	segmentPath map[string]struct{}
+	counts      *evaluationCounts
+	clauseErr   *clauseError
+	errs        *evaluationErrors
+}
+
+// newEvaluation returns the state every evaluation of a flag starts from, flags and segments
+// are memoized for the lifetime of the state
+func (e Evaluator) newEvaluation(ctx context.Context) *evaluationState {
+	reason := newEvaluationReason(ReasonError)
+	return &evaluationState{
+		Evaluator:   e,
+		ctx:         ctx,
+		reason:      &reason,
+		segment:     &rest.Segment{},
+		memo:        newEvaluationMemo(nil),
+		segmentPath: make(map[string]struct{}),
+	}
+}
+
+// EvaluatorOption is used for advanced evaluator configuration
+// using options pattern
+type EvaluatorOption func(e *Evaluator)
+
+// WithFirstVariationFallback serves the first variation of a flag when its default serve
+// has neither a variation nor a distribution, instead of failing the evaluation. It only applies
+// when the WithEmptyDefaultServe policy resolves to no variation, as EmptyDefaultServeFail does,
+// and takes precedence over WithFallthroughVariations.
+func WithFirstVariationFallback(enabled bool) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.firstVariationFallback = enabled
+	}
+}
+
+// WithClauseStatistics collects, per serving rule and clause index, how often a clause
+// short-circuited a failing rule into the provided statistics. Rules combining their
+// clauses with or are not recorded.
+func WithClauseStatistics(statistics *ClauseStatistics) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.clauseStatistics = statistics
+	}
+}
+
+// WithFloatEpsilon sets the tolerance used by the float_equal operator, a non positive
+// epsilon restores the default of 1e-9
+func WithFloatEpsilon(epsilon float64) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.floatEpsilon = epsilon
+	}
+}
+
+// WithStickyStore persists distribution assignments in the store so targets keep their
+// variation when distribution weights change
+func WithStickyStore(store StickyStore) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.stickyStore = store
+	}
+}
+
+// WithBucketBy buckets percentage rollouts which don't name a bucketBy attribute by the target
+// attribute, for example accountId so every target of an account is served the same variation.
+// Targets without the attribute are bucketed by their identifier.
+func WithBucketBy(attribute string) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.bucketBy = attribute
+	}
+}
+
+// WithStage sets the current rollout stage (for example internal, beta or ga) which
+// stage_in clauses are matched against
+func WithStage(stage string) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.stage = stage
+	}
+}
+
+// WithMaxJSONVariationSize limits the size in bytes of json variation values, larger values
+// are rejected and the default value is served instead. A non positive size disables the limit
+func WithMaxJSONVariationSize(size int) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.maxJSONVariationSize = size
+	}
+}
+
+// WithEmptyDefaultServe sets the policy applied to flags with an empty default serve, the
+// fallback variation is only used by EmptyDefaultServeFallback. The policy takes precedence over
+// WithFirstVariationFallback and WithFallthroughVariations, which only apply when it resolves to no
+// variation.
+func WithEmptyDefaultServe(policy EmptyDefaultServePolicy, fallbackVariation string) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.emptyDefaultServe = policy
+		e.emptyDefaultFallback = fallbackVariation
+	}
+}
+
+// WithAttributeHasher sets the hashing scheme clause values are hashed with before being compared
+// by the hashed_equal operator, targets must carry attribute values hashed with the same scheme
+func WithAttributeHasher(hasher func(value string) string) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.attributeHasher = hasher
+	}
+}
+
+// WithAttributeTransform registers a transform applied to the attribute before clauses are
+// evaluated against it, transforms registered for the same attribute are applied in order
+func WithAttributeTransform(attribute string, transform AttributeTransform) EvaluatorOption {
+	return func(e *Evaluator) {
+		if e.attributeTransforms == nil {
+			e.attributeTransforms = make(map[string][]AttributeTransform)
+		}
+		e.attributeTransforms[attribute] = append(e.attributeTransforms[attribute], transform)
+	}
+}
+
+// WithOperatorAliases normalizes clause operators named differently by other platforms, for example
+// {"eq": "equal", "ne": "not_equal"}, before they are evaluated
+func WithOperatorAliases(aliases map[string]string) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.operatorAliases = aliases
+	}
+}
+
+// WithMissingAttributePolicy sets how clauses are evaluated when the target lacks the clause
+// attribute, exists, stage_in and segmentMatch clauses are not affected
+func WithMissingAttributePolicy(policy MissingAttributePolicy) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.missingAttribute = policy
+	}
+}
+
+// WithInvalidPatternPolicy sets how match clauses are evaluated when their pattern fails to
+// compile, the compile error is logged as a warning either way
+func WithInvalidPatternPolicy(policy InvalidPatternPolicy) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.invalidPattern = policy
+	}
+}
+
+// WithTracer starts a span with the tracer around every evaluation made through Evaluate or the typed
+// variation methods, recording the flag, reason, served variation and the number of segments and
+// prerequisites checked as span attributes
+func WithTracer(tracer Tracer) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.tracer = tracer
+	}
+}
+
+// WithStrictMode makes evaluations fail with ErrInvalidClause when they come across a clause with an
+// unknown operator or without values, instead of treating the clause as not matching
+func WithStrictMode(enabled bool) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.strict = enabled
+	}
+}
+
+// WithLastKnownFallback serves the variation last served to a target when its flag can't be retrieved,
+// for example because the Query fails transiently, instead of failing the evaluation. Flags which
+// aren't found because they were deleted are never served from the fallback. At most size
+// variations are kept, least recently used first out, a non positive size disables the fallback.
+func WithLastKnownFallback(size int) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.lastKnown = newLastKnownCache(size)
+	}
+}
+
+// WithPrerequisiteErrorPolicy sets how prerequisites which can't be retrieved or evaluated are
+// treated, by default they are treated as satisfied
+func WithPrerequisiteErrorPolicy(policy PrerequisiteErrorPolicy) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.prerequisiteError = policy
+	}
+}
+
+// WithMaxPrerequisiteDepth limits how deep prerequisites may require other prerequisites, flags whose
+// prerequisites are nested deeper serve their off variation. A non positive depth restores the default of 16.
+func WithMaxPrerequisiteDepth(depth int) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.maxPrerequisiteDepth = depth
+	}
+}
+
+// WithListProvider resolves the lists named by in_list clauses with the provider, each list is
+// cached for ttl after it is loaded
+func WithListProvider(provider ListProvider, ttl time.Duration) EvaluatorOption {
+	return func(e *Evaluator) {
+		if provider == nil {
+			e.lists = nil
+			return
+		}
+		e.lists = newListCache(provider, ttl)
+	}
+}
+
+// WithMetricsCallback notifies the callback of the outcome of every evaluation, both successful and failed
+func WithMetricsCallback(callback MetricsCallback) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.metricsCallback = callback
+	}
+}
+
+// WithNumericLocale sets the separators string attribute values compared by the gt, gte, lt, lte and
+// float_equal operators are formatted with, clause values always use a '.' decimal separator
+func WithNumericLocale(locale NumericLocale) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.numericLocale = locale
+	}
+}
+
+// WithMaxAttributeLength guards the regex and string operators against oversized input, clauses
+// don't match attribute values longer than length bytes. A non positive length disables the limit
+func WithMaxAttributeLength(length int) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.maxAttributeLength = length
+	}
+}
+
+// WithClock sets the clock schedule and before or after "now" clauses are matched against and
+// in_list lists expire by, it defaults to time.Now
+func WithClock(clock func() time.Time) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.clock = clock
+	}
+}
+
+// WithLocation sets the time zone schedule clauses are matched in for targets without their own,
+// it defaults to UTC
+func WithLocation(location *time.Location) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.location = location
+	}
+}
+
+// WithFallthroughVariations sets, per flag kind, the identifier of the variation served as a last
+// resort when everything else resolves to no variation. For empty default serves it applies after
+// the WithEmptyDefaultServe policy and WithFirstVariationFallback.
+func WithFallthroughVariations(variations map[rest.FeatureConfigKind]string) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.fallthroughVariations = variations
+	}
+}
+
+// WithMixedComparison sets how the gt, gte, lt and lte operators compare a numeric value with a non
+// numeric one, by default both are compared as strings
+func WithMixedComparison(policy MixedComparisonPolicy) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.mixedComparison = policy
+	}
+}
+
+// WithVariationParser registers the parser VariationValue parses the values of flags of a custom kind
+// with, for example "duration". Parsers registered for the built-in kinds are ignored.
+func WithVariationParser(kind string, parser VariationParser) EvaluatorOption {
+	return func(e *Evaluator) {
+		if e.variationParsers == nil {
+			e.variationParsers = make(map[string]VariationParser)
+		}
+		e.variationParsers[kind] = parser
+	}
 }
 
 // NewEvaluator constructs evaluator with query instance
-func NewEvaluator(query Query, postEvalCallback PostEvaluateCallback, logger logger.Logger) (*Evaluator, error) {
+func NewEvaluator(query Query, postEvalCallback PostEvaluateCallback, logger logger.Logger,
+	options ...EvaluatorOption) (*Evaluator, error) {
 	if query == nil {
 		return nil, ErrQueryProviderMissing
 	}
-	return &Evaluator{
+	evaluator := &Evaluator{
 		logger:           logger,
 		query:            query,
 		postEvalCallback: postEvalCallback,
-	}, nil
+		regexes:          newRegexCache(),
+		checks:           newFlagChecks(),
+		schedules:        newScheduleCache(),
+		dependencies:     newCacheDependencies(),
+	}
+	for _, opt := range options {
+		opt(evaluator)
+	}
+	return evaluator, nil
+}
+
+// OnFlagStored evicts the state the evaluator derived from the previous flag configuration, such
+// as compiled match patterns and parsed schedule windows no other flag or segment uses. Together
+// with OnFlagDeleted, OnSegmentStored and OnSegmentDeleted it implements the repository callback.
+func (e Evaluator) OnFlagStored(identifier string) {
+	e.invalidate(dependencyOwner{identifier: identifier})
+}
+
+// OnFlagDeleted evicts the state the evaluator derived from the flag configuration
+func (e Evaluator) OnFlagDeleted(identifier string) {
+	e.invalidate(dependencyOwner{identifier: identifier})
+	e.checks.forget(identifier)
+	e.lastKnown.forget(identifier)
+}
+
+// OnSegmentStored evicts the state the evaluator derived from the previous segment rules
+func (e Evaluator) OnSegmentStored(identifier string) {
+	e.invalidate(dependencyOwner{segment: true, identifier: identifier})
+}
+
+// OnSegmentDeleted evicts the state the evaluator derived from the segment rules
+func (e Evaluator) OnSegmentDeleted(identifier string) {
+	e.invalidate(dependencyOwner{segment: true, identifier: identifier})
 }
 
-func (e Evaluator) evaluateClause(clause *rest.Clause, target *Target) bool {
+// evaluateClause reports whether the target satisfies the clause, negated clauses invert the result
+// of their operator
+func (e *evaluationState) evaluateClause(clause *rest.Clause, target *Target) bool {
 	if clause == nil {
 		return false
 	}
 
+	operator := e.clauseOperator(clause)
+	negate := clause.Negate
+	if positive, ok := negatedOperators[operator]; ok {
+		operator, negate = positive, !negate
+	}
+
+	if operator == existsOperator {
+		return (formatAttrValue(e.getAttrValue(target, clause.Attribute)) != "") != negate
+	}
+
 	values := clause.Values
 	if len(values) == 0 {
+		e.invalidClause(fmt.Errorf("%w: clause %s %s has no values", ErrInvalidClause, clause.Attribute, clause.Op))
 		return false
 	}
 	value := values[0]
 
-	operator := clause.Op
-	if operator == "" {
+	if !e.knownOperator(operator) {
+		e.invalidClause(fmt.Errorf("%w: unknown operator %q", ErrInvalidClause, clause.Op))
 		return false
 	}
 
-	attrValue := getAttrValue(target, clause.Attribute)
+	if operator == stageInOperator {
+		return (e.stage != "" && contains(values, e.stage)) != negate
+	}
+
+	if operator == scheduleOperator {
+		return e.matchSchedule(clause, target) != negate
+	}
+
+	attrValue := e.getAttrValue(target, clause.Attribute)
 	if operator != segmentMatchOperator && !attrValue.IsValid() {
+		return e.missingAttribute == MissingAttributePass
+	}
+
+	// attributes and patterns which can't be evaluated are not negated, that would turn an unusable
+	// value into a match
+	if elements, ok := listAttrValues(attrValue); ok && e.exceedsMaxAttributeLength(clause.Attribute, elements...) {
+		return false
+	}
+	if e.exceedsMaxAttributeLength(clause.Attribute, formatAttrValue(attrValue)) {
 		return false
 	}
+	if operator == matchOperator {
+		e.recordDependency(patternDependency, value)
+		if _, err := e.regexes.compile(value); err != nil {
+			e.logger.Warnf("Flag %s attribute %s has an invalid match pattern %q: %v",
+				e.feature, clause.Attribute, value, err)
+			e.errs.record(fmt.Errorf("%w: invalid match pattern %q: %v", ErrInvalidClause, value, err))
+			return e.invalidPattern == InvalidPatternPass
+		}
+	}
 
-	object := ""
-	switch attrValue.Kind() {
-	case reflect.Int, reflect.Int64:
-		object = strconv.FormatInt(attrValue.Int(), 10)
-	case reflect.Bool:
-		object = strconv.FormatBool(attrValue.Bool())
-	case reflect.String:
-		object = attrValue.String()
-	case reflect.Array, reflect.Chan, reflect.Complex128, reflect.Complex64, reflect.Func, reflect.Interface,
-		reflect.Invalid, reflect.Ptr, reflect.Slice, reflect.Struct, reflect.Uintptr, reflect.UnsafePointer,
-		reflect.Float32, reflect.Float64, reflect.Int16, reflect.Int32, reflect.Int8, reflect.Map, reflect.Uint,
-		reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint8:
-		object = fmt.Sprintf("%v", object)
-	default:
-		// Use string formatting as last ditch effort for any unexpected values
-		object = fmt.Sprintf("%v", object)
+	return e.applyOperator(operator, clause, target, attrValue) != negate
+}
+
+// applyOperator evaluates the clause operator against the attribute value of the target
+func (e *evaluationState) applyOperator(operator string, clause *rest.Clause, target *Target,
+	attrValue reflect.Value) bool {
+	values := clause.Values
+	value := values[0]
+
+	// list valued attributes such as roles match when any of their elements match
+	if elements, ok := listAttrValues(attrValue); ok {
+		switch operator {
+		case inOperator, notInOperator:
+			// elements are matched one by one so clause values are normalized to each element's kind
+			raw, _ := listAttrElements(attrValue)
+			for _, element := range raw {
+				if e.applyOperator(inOperator, clause, target, element) {
+					return operator == inOperator
+				}
+			}
+			return operator == notInOperator
+		case allowDenyOperator:
+			return matchAllowDeny(values, elements)
+		case inListOperator:
+			return e.inList(values, elements)
+		case equalOperator, notEqualOperator:
+			for _, element := range elements {
+				if strings.EqualFold(element, value) {
+					return operator == equalOperator
+				}
+			}
+			return operator == notEqualOperator
+		case containsOperator:
+			for _, element := range elements {
+				if strings.Contains(element, value) {
+					return true
+				}
+			}
+			return false
+		case containsIOperator:
+			for _, element := range elements {
+				if strings.Contains(foldValue(element), foldValue(value)) {
+					return true
+				}
+			}
+			return false
+		case equalSensitiveOperator, startsWithOperator, endsWithOperator, startsWithIOperator,
+			endsWithIOperator, matchOperator:
+			for _, element := range elements {
+				if e.applyOperator(operator, clause, target, reflect.ValueOf(element)) {
+					return true
+				}
+			}
+			return false
+		}
 	}
 
+	object := formatAttrValue(attrValue)
+
 	switch operator {
 	case startsWithOperator:
 		return strings.HasPrefix(object, value)
 	case endsWithOperator:
 		return strings.HasSuffix(object, value)
 	case matchOperator:
-		found, err := regexp.MatchString(value, object)
-		if err != nil || !found {
-			return false
-		}
-		return true
+		e.recordDependency(patternDependency, value)
+		matched, _ := e.regexes.match(value, object)
+		return matched
 	case containsOperator:
 		return strings.Contains(object, value)
+	case startsWithIOperator:
+		return strings.HasPrefix(foldValue(object), foldValue(value))
+	case endsWithIOperator:
+		return strings.HasSuffix(foldValue(object), foldValue(value))
+	case containsIOperator:
+		return strings.Contains(foldValue(object), foldValue(value))
 	case equalOperator:
 		return strings.EqualFold(object, value)
+	case notEqualOperator:
+		return !strings.EqualFold(object, value)
 	case equalSensitiveOperator:
-		return object == value
+		return object == normalizeClauseValue(attrValue.Kind(), value)
 	case inOperator:
-		for _, val := range values {
-			if val == object {
+		return contains(normalizeClauseValues(attrValue.Kind(), values), object)
+	case notInOperator:
+		return !contains(normalizeClauseValues(attrValue.Kind(), values), object)
+	case allowDenyOperator:
+		return matchAllowDeny(values, []string{object})
+	case gtOperator:
+		c, ok := compareMixedValues(e.numericLocale.normalizeAttr(attrValue.Kind(), object), value, e.mixedComparison)
+		return ok && c > 0
+	case gteOperator:
+		c, ok := compareMixedValues(e.numericLocale.normalizeAttr(attrValue.Kind(), object), value, e.mixedComparison)
+		return ok && c >= 0
+	case ltOperator:
+		c, ok := compareMixedValues(e.numericLocale.normalizeAttr(attrValue.Kind(), object), value, e.mixedComparison)
+		return ok && c < 0
+	case lteOperator:
+		c, ok := compareMixedValues(e.numericLocale.normalizeAttr(attrValue.Kind(), object), value, e.mixedComparison)
+		return ok && c <= 0
+	case globAnyCIOperator:
+		for _, pattern := range values {
+			if matchGlob(strings.ToLower(pattern), strings.ToLower(object)) {
+				return true
+			}
+		}
+		return false
+	case floatEqualOperator:
+		return floatEqual(e.numericLocale.normalizeAttr(attrValue.Kind(), object), value, e.epsilon())
+	case semverGtOperator:
+		c, ok := compareSemver(object, value)
+		return ok && c > 0
+	case semverLtOperator:
+		c, ok := compareSemver(object, value)
+		return ok && c < 0
+	case semverEqualOperator:
+		c, ok := compareSemver(object, value)
+		return ok && c == 0
+	case semverSatisfiesOperator:
+		for _, constraint := range values {
+			if matchSemverConstraint(object, constraint) {
+				return true
+			}
+		}
+		return false
+	case beforeOperator:
+		c, ok := compareTimes(object, e.clauseTime(value))
+		return ok && c < 0
+	case afterOperator:
+		c, ok := compareTimes(object, e.clauseTime(value))
+		return ok && c > 0
+	case ipInCIDROperator, ipInCIDRAnyOperator:
+		return ipInAnyCIDR(object, values)
+	case inListOperator:
+		return e.inList(values, []string{object})
+	case weightedBucketOperator:
+		bucket := weightedBucket(strings.Join([]string{clause.Attribute, object}, ":"), values[1:])
+		return bucket != "" && bucket == value
+	case sampledOperator:
+		sampleKey := ""
+		if len(values) > 1 {
+			sampleKey = values[1]
+		}
+		return isSampled(object, value, sampleKey)
+	case attrEqualOperator, attrEqualSensitiveOperator:
+		other := e.getAttrValue(target, value)
+		if !other.IsValid() {
+			return false
+		}
+		if operator == attrEqualSensitiveOperator {
+			return object == formatAttrValue(other)
+		}
+		return strings.EqualFold(object, formatAttrValue(other))
+	case hashedEqualOperator:
+		for _, v := range values {
+			if e.attributeHasher != nil {
+				v = e.attributeHasher(v)
+			}
+			if strings.EqualFold(object, v) {
+				return true
+			}
+		}
+		return false
+	case fuzzyInOperator:
+		maxDistance, err := strconv.Atoi(value)
+		if err != nil || maxDistance < 0 {
+			return false
+		}
+		for _, candidate := range values[1:] {
+			if levenshtein(strings.ToLower(object), strings.ToLower(candidate)) <= maxDistance {
 				return true
 			}
 		}
 		return false
-	case gtOperator:
-		return object > value
 	case segmentMatchOperator:
 		return e.isTargetIncludedOrExcludedInSegment(values, target)
+	case segmentAndAttrOperator:
+		if len(values) < 2 {
+			return false
+		}
+		condition := rest.Clause{Attribute: clause.Attribute, Op: values[1], Values: values[2:]}
+		return e.isTargetIncludedOrExcludedInSegment(values[:1], target) && e.evaluateClause(&condition, target)
 	default:
+		if fn, ok := e.customOperator(operator); ok {
+			return fn(object, values)
+		}
+		return false
+	}
+}
+
+// invalidClause records a clause which can't be evaluated, it fails strict evaluations and is
+// reported by best effort ones
+func (e *evaluationState) invalidClause(err error) {
+	e.clauseErr.record(err)
+	e.errs.record(err)
+}
+
+// clauseError keeps the first invalid clause found by a strict evaluation. All methods are safe to
+// call on a nil clauseError so lenient evaluations don't pay for it.
+type clauseError struct {
+	first error
+}
+
+func (c *clauseError) record(err error) {
+	if c != nil && c.first == nil {
+		c.first = err
+	}
+}
+
+func (c *clauseError) err() error {
+	if c == nil {
+		return nil
+	}
+	return c.first
+}
+
+// exceedsMaxAttributeLength reports whether any of the attribute values is longer than the configured maximum
+func (e Evaluator) exceedsMaxAttributeLength(attribute string, values ...string) bool {
+	if e.maxAttributeLength <= 0 {
+		return false
+	}
+	for _, value := range values {
+		if len(value) > e.maxAttributeLength {
+			e.logger.Warnf("Attribute %s is %d bytes long which exceeds the maximum of %d, the clause doesn't match",
+				attribute, len(value), e.maxAttributeLength)
+			return true
+		}
+	}
+	return false
+}
+
+// clauseTime resolves the "now" value of before and after clauses to the current time
+func (e Evaluator) clauseTime(value string) string {
+	if value == nowValue {
+		return e.now().Format(time.RFC3339Nano)
+	}
+	return value
+}
+
+func (e Evaluator) now() time.Time {
+	if e.clock == nil {
+		return time.Now()
+	}
+	return e.clock()
+}
+
+// clauseOperator returns the operator of the clause with aliases resolved
+func (e Evaluator) clauseOperator(clause *rest.Clause) string {
+	if alias, ok := e.operatorAliases[clause.Op]; ok {
+		return alias
+	}
+	return clause.Op
+}
+
+// skipClause reports whether the clause is left out of its rule because the target lacks its attribute
+func (e *evaluationState) skipClause(clause *rest.Clause, target *Target) bool {
+	if e.missingAttribute != MissingAttributeSkip {
+		return false
+	}
+	switch e.clauseOperator(clause) {
+	case existsOperator, stageInOperator, segmentMatchOperator, scheduleOperator:
 		return false
 	}
+	return !e.getAttrValue(target, clause.Attribute).IsValid()
+}
+
+// contextErr returns the error of the evaluation context once it is cancelled or past its deadline
+func (e *evaluationState) contextErr() error {
+	if e.ctx == nil {
+		return nil
+	}
+	return e.ctx.Err()
+}
+
+func (e *evaluationState) getFlag(identifier string) (rest.FeatureConfig, error) {
+	if err := e.contextErr(); err != nil {
+		return rest.FeatureConfig{}, err
+	}
+	if flag, ok := e.memo.flag(identifier); ok {
+		return flag, nil
+	}
+	var flag rest.FeatureConfig
+	var err error
+	if cq, ok := e.query.(ContextQuery); ok && e.ctx != nil {
+		flag, err = cq.GetFlagWithContext(e.ctx, identifier)
+	} else {
+		flag, err = e.query.GetFlag(identifier)
+	}
+	if err == nil {
+		e.memo.setFlag(flag)
+	}
+	return flag, err
 }
 
-func (e Evaluator) evaluateClauses(clauses []rest.Clause, target *Target) bool {
+func (e *evaluationState) getSegment(identifier string) (rest.Segment, error) {
+	if err := e.contextErr(); err != nil {
+		return rest.Segment{}, err
+	}
+	if segment, ok := e.memo.segment(identifier); ok {
+		return segment, nil
+	}
+	var segment rest.Segment
+	var err error
+	if cq, ok := e.query.(ContextQuery); ok && e.ctx != nil {
+		segment, err = cq.GetSegmentWithContext(e.ctx, identifier)
+	} else {
+		segment, err = e.query.GetSegment(identifier)
+	}
+	if err == nil {
+		e.memo.setSegment(segment)
+	}
+	return segment, err
+}
+
+func (e Evaluator) epsilon() float64 {
+	if e.floatEpsilon <= 0 {
+		return defaultFloatEpsilon
+	}
+	return e.floatEpsilon
+}
+
+func (e *evaluationState) evaluateClauses(clauses []rest.Clause, target *Target) bool {
+	return e.firstFailingClause(clauses, target) == -1
+}
+
+// firstFailingClause returns the index of the clause that short-circuited the evaluation
+// or -1 when all clauses matched, when every clause was skipped the first one is returned
+func (e *evaluationState) firstFailingClause(clauses []rest.Clause, target *Target) int {
+	skipped := 0
 	for i := range clauses {
-		if !e.evaluateClause(&clauses[i], target) {
-			return false
+		if e.skipClause(&clauses[i], target) {
+			skipped++
+			continue
+		}
+		node := e.trace.beginClause(&clauses[i])
+		matched := e.evaluateClause(&clauses[i], target)
+		e.trace.end(node, matched)
+		if !matched {
+			return i
 		}
 	}
-	return true
+	if skipped > 0 && skipped == len(clauses) {
+		return 0
+	}
+	return -1
 }
 
-func (e Evaluator) evaluateRule(servingRule *rest.ServingRule, target *Target) bool {
-	return e.evaluateClauses(servingRule.Clauses, target)
+// evaluateAnyClause reports whether any of the clauses matches the target
+func (e *evaluationState) evaluateAnyClause(clauses []rest.Clause, target *Target) bool {
+	for i := range clauses {
+		if e.skipClause(&clauses[i], target) {
+			continue
+		}
+		node := e.trace.beginClause(&clauses[i])
+		matched := e.evaluateClause(&clauses[i], target)
+		e.trace.end(node, matched)
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateRuleClauses combines the clauses of a rule with and unless the rule opts into or, it
+// returns whether the rule matched and for and rules the index of the failing clause or -1
+func (e *evaluationState) evaluateRuleClauses(servingRule *rest.ServingRule, target *Target) (bool, int) {
+	if servingRule.Logic != nil && *servingRule.Logic == rest.ServingRuleLogicOr {
+		return e.evaluateAnyClause(servingRule.Clauses, target), -1
+	}
+	failed := e.firstFailingClause(servingRule.Clauses, target)
+	return failed == -1, failed
+}
+
+// evaluateRule is like evaluateRuleClauses but records the failing clause in the clause statistics
+func (e *evaluationState) evaluateRule(servingRule *rest.ServingRule, target *Target) bool {
+	matched, failed := e.evaluateRuleClauses(servingRule, target)
+	if failed != -1 {
+		e.clauseStatistics.record(servingRule.RuleId, failed)
+	}
+	return matched
 }
 
-func (e Evaluator) evaluateRules(servingRules []rest.ServingRule, target *Target) string {
+func (e *evaluationState) evaluateRules(feature string, servingRules []rest.ServingRule, target *Target) string {
 	if target == nil || servingRules == nil {
 		return ""
 	}
 
-	sort.SliceStable(servingRules, func(i, j int) bool {
-		return servingRules[i].Priority < servingRules[j].Priority
-	})
-	for i := range servingRules {
-		rule := servingRules[i]
+	rules := sortedRules(servingRules)
+	for i := range rules {
+		rule := rules[i]
+		e.recordSegment(rest.Segment{})
+		node := e.trace.beginRule(&rule)
+		matched := e.evaluateRule(&rule, target)
+		e.trace.end(node, matched)
 		// if evaluation is false just continue to next rule
-		if !e.evaluateRule(&rule, target) {
+		if !matched {
 			continue
 		}
 
 		// rule matched, check if there is distribution
 		if rule.Serve.Distribution != nil {
-			return evaluateDistribution(rule.Serve.Distribution, target)
+			reason := e.ruleMatchReason(i, &rule)
+			variation := e.evaluateStickyDistribution(feature, rule.Serve.Distribution, target)
+			reason.setRollout(rule.Serve.Distribution, variation)
+			e.reason.set(reason)
+			return variation
 		}
 
 		// rule matched, here must be variation if distribution is undefined or null
 		if rule.Serve.Variation != nil {
+			e.reason.set(e.ruleMatchReason(i, &rule))
 			return *rule.Serve.Variation
 		}
 	}
 	return ""
 }
 
-func (e Evaluator) evaluateVariationMap(variationsMap []rest.VariationMap, target *Target) string {
+// sortedRules returns a copy of the serving rules in priority order, the serving rules
+// themselves are shared by every evaluation of the flag so must not be reordered
+func sortedRules(servingRules []rest.ServingRule) []rest.ServingRule {
+	rules := make([]rest.ServingRule, len(servingRules))
+	copy(rules, servingRules)
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Priority < rules[j].Priority
+	})
+	return rules
+}
+
+func (e *evaluationState) ruleMatchReason(index int, rule *rest.ServingRule) EvaluationReason {
+	reason := newEvaluationReason(ReasonRuleMatch)
+	reason.RuleIndex = index
+	reason.RulePriority = rule.Priority
+	reason.RuleID = rule.RuleId
+	if e.segment != nil {
+		reason.SegmentID = e.segment.Identifier
+		reason.SegmentName = e.segment.Name
+	}
+	return reason
+}
+
+// recordSegment remembers the segment which included the target in the rule being evaluated,
+// it is a no-op when reasons are not collected
+func (e *evaluationState) recordSegment(segment rest.Segment) {
+	if e.segment != nil {
+		*e.segment = segment
+	}
+}
+
+func (e *evaluationState) evaluateVariationMap(variationsMap []rest.VariationMap, target *Target) string {
 	if variationsMap == nil || target == nil {
 		return ""
 	}
@@ -201,140 +1106,372 @@ func (e Evaluator) evaluateVariationMap(variationsMap []rest.VariationMap, targe
 	return ""
 }
 
-func (e Evaluator) evaluateFlag(fc rest.FeatureConfig, target *Target) (rest.Variation, error) {
+func (e *evaluationState) evaluateFlag(fc rest.FeatureConfig, target *Target) (rest.Variation, error) {
+	// prerequisites are evaluated with the state of the flag requiring them
+	defer func(feature string) { e.feature = feature }(e.feature)
+	e.feature = fc.Feature
 	var variation = fc.OffVariation
-	if fc.State == rest.FeatureStateOn {
+	if fc.State != rest.FeatureStateOn {
+		e.trace.end(e.trace.begin(traceOff, "off"), true)
+		e.reason.set(newEvaluationReason(ReasonOff))
+	} else {
+		e.validateWeights(fc)
 		variation = ""
 		if fc.VariationToTargetMap != nil {
+			node := e.trace.begin(traceVariationMap, traceVariationMap)
 			variation = e.evaluateVariationMap(*fc.VariationToTargetMap, target)
+			e.trace.end(node, variation != "")
+			if variation != "" {
+				e.reason.set(newEvaluationReason(ReasonTargetMatch))
+			}
 		}
 		if variation == "" && fc.Rules != nil {
-			variation = e.evaluateRules(*fc.Rules, target)
+			variation = e.evaluateRules(fc.Feature, *fc.Rules, target)
 		}
 		if variation == "" {
-			variation = evaluateDistribution(fc.DefaultServe.Distribution, target)
+			e.trace.end(e.trace.begin(traceDefaultServe, traceDefaultServe), true)
+			reason := newEvaluationReason(ReasonDefault)
+			if fc.DefaultServe.Distribution != nil {
+				variation = e.evaluateStickyDistribution(fc.Feature, fc.DefaultServe.Distribution, target)
+				reason.setRollout(fc.DefaultServe.Distribution, variation)
+			}
+			e.reason.set(reason)
+			if variation == "" && fc.DefaultServe.Variation != nil {
+				variation = *fc.DefaultServe.Variation
+			}
+			if fc.DefaultServe.Distribution == nil && fc.DefaultServe.Variation == nil {
+				variation = e.emptyDefaultServeVariation(fc)
+			}
 		}
-		if variation == "" && fc.DefaultServe.Variation != nil {
-			variation = *fc.DefaultServe.Variation
+		if variation == "" && e.firstVariationFallback && fc.DefaultServe.Variation == nil &&
+			fc.DefaultServe.Distribution == nil && len(fc.Variations) > 0 {
+			// default serve is misconfigured so fall back to the variations in their defined order
+			e.warnOnce(firstVariationFallbackCheck, fc,
+				"Flag %s has an empty default serve, serving first variation %s", fc.Feature, fc.Variations[0].Identifier)
+			variation = fc.Variations[0].Identifier
 		}
 	}
 
+	if fallthroughVariation, ok := e.fallthroughVariations[fc.Kind]; ok && variation == "" {
+		e.warnOnce(fallthroughCheck, fc, "Flag %s resolved to no variation, serving fallthrough variation %s",
+			fc.Feature, fallthroughVariation)
+		variation = fallthroughVariation
+	}
+
 	if variation != "" {
 		return findVariation(fc.Variations, variation)
 	}
 	return rest.Variation{}, fmt.Errorf("%w: %s", ErrEvaluationFlag, fc.Feature)
 }
 
-func (e Evaluator) isTargetIncludedOrExcludedInSegment(segmentList []string, target *Target) bool {
+// emptyDefaultServeVariation returns the variation the configured policy serves for a flag
+// which default serve has neither a variation nor a distribution
+func (e Evaluator) emptyDefaultServeVariation(fc rest.FeatureConfig) string {
+	switch e.emptyDefaultServe {
+	case EmptyDefaultServeOff:
+		e.warnOnce(emptyDefaultServeCheck, fc,
+			"Flag %s has an empty default serve and no target or rule matched, serving off variation %s",
+			fc.Feature, fc.OffVariation)
+		return fc.OffVariation
+	case EmptyDefaultServeFallback:
+		e.warnOnce(emptyDefaultServeCheck, fc,
+			"Flag %s has an empty default serve and no target or rule matched, serving fallback variation %s",
+			fc.Feature, e.emptyDefaultFallback)
+		return e.emptyDefaultFallback
+	case EmptyDefaultServeFail:
+		return ""
+	default:
+		return ""
+	}
+}
+
+// isTargetIncludedOrExcludedInSegment reports whether the target is included in any of the segments.
+// Segment rules may reference other segments with segmentMatch clauses, a segment already on the
+// current path or nested deeper than maxSegmentDepth doesn't include the target.
+func (e *evaluationState) isTargetIncludedOrExcludedInSegment(segmentList []string, target *Target) bool {
 	if segmentList == nil {
 		return false
 	}
 	for _, segmentIdentifier := range segmentList {
-		segment, err := e.query.GetSegment(segmentIdentifier)
-		if err != nil {
-			return false
+		if _, ok := e.segmentPath[segmentIdentifier]; ok {
+			e.logger.Errorf(
+				"Segment cycle detected, segment %v is already on the segment path", segmentIdentifier)
+			continue
 		}
-		// Should Target be excluded - if in excluded list we return false
-		if segment.Excluded != nil && isTargetInList(target, *segment.Excluded) {
-			e.logger.Debugf("Target %s excluded from segment %s via exclude list", target.Name, segment.Name)
-			return false
+		if len(e.segmentPath) >= maxSegmentDepth {
+			e.logger.Errorf(
+				"Segment %v is nested deeper than %d segments", segmentIdentifier, maxSegmentDepth)
+			continue
 		}
+		node := e.trace.beginSegment(segmentIdentifier)
+		e.segmentPath[segmentIdentifier] = struct{}{}
+		included, decided := e.isTargetIncludedOrExcludedInSingleSegment(segmentIdentifier, target)
+		delete(e.segmentPath, segmentIdentifier)
+		e.trace.end(node, included)
+		if decided {
+			return included
+		}
+	}
+	return false
+}
 
-		// Should Target be included - if in included list we return true
-		if segment.Included != nil && isTargetInList(target, *segment.Included) {
-			e.logger.Debugf(
-				"Target %s included in segment %s via include list",
-				target.Name,
-				segment.Name)
-			return true
+// isTargetIncludedOrExcludedInSingleSegment reports whether the target is included in the segment
+// and whether that outcome is final, which is not the case when no include list or rule matched
+func (e *evaluationState) isTargetIncludedOrExcludedInSingleSegment(segmentIdentifier string,
+	target *Target) (bool, bool) {
+	e.counts.segment()
+	segment, err := e.getSegment(segmentIdentifier)
+	if err != nil {
+		e.errs.record(fmt.Errorf("%w: segment %s: %v", ErrSegmentReference, segmentIdentifier, err))
+		return false, true
+	}
+	e.trace.nameSegment(segment.Name)
+	// Should Target be excluded - if in excluded list we return false
+	if segment.Excluded != nil && isTargetInList(target, *segment.Excluded) {
+		e.logger.Debugf("Target %s excluded from segment %s via exclude list", target.Name, segment.Name)
+		return false, true
+	}
+
+	// Should Target be included - if in included list we return true
+	if segment.Included != nil && isTargetInList(target, *segment.Included) {
+		e.logger.Debugf(
+			"Target %s included in segment %s via include list",
+			target.Name,
+			segment.Name)
+		e.recordSegment(segment)
+		return true, true
+	}
+
+	// Should Target be included via segment rules - any matching rule block includes the target
+	if segment.Rules != nil {
+		or := segment.Logic != nil && *segment.Logic == rest.SegmentLogicOr
+		for _, block := range segmentRuleBlocks(*segment.Rules) {
+			if (or && e.evaluateAnyClause(block, target)) || (!or && e.evaluateClauses(block, target)) {
+				e.logger.Debugf(
+					"Target %s included in segment %s via rules", target.Name, segment.Name)
+				e.recordSegment(segment)
+				return true, true
+			}
 		}
+	}
+	return false, false
+}
 
-		// Should Target be included via segment rules
-		rules := segment.Rules
-		if rules != nil && e.evaluateClauses(*rules, target) {
-			e.logger.Debugf(
-				"Target %s included in segment %s via rules", target.Name, segment.Name)
-			return true
+// segmentRuleBlocks groups segment rules into blocks of clauses sharing the same id, in order of
+// first appearance. Clauses within a block are combined using the segment logic, and by default,
+// while blocks are ORed. Clauses without an id all belong to the same block.
+func segmentRuleBlocks(clauses []rest.Clause) [][]rest.Clause {
+	blocks := make([][]rest.Clause, 0, len(clauses))
+	index := make(map[string]int, len(clauses))
+	for _, clause := range clauses {
+		i, ok := index[clause.Id]
+		if !ok {
+			i = len(blocks)
+			index[clause.Id] = i
+			blocks = append(blocks, nil)
 		}
+		blocks[i] = append(blocks[i], clause)
 	}
-	return false
+	return blocks
+}
+
+func (e *evaluationState) checkPreRequisite(fc *rest.FeatureConfig, target *Target) (prerequisiteCheck, error) {
+	return e.checkPreRequisiteChain(fc, target, map[string]struct{}{})
 }
 
-func (e Evaluator) checkPreRequisite(fc *rest.FeatureConfig, target *Target) (bool, error) {
+// checkPreRequisiteChain checks the prerequisites of fc, visited holds the features on the
+// current prerequisite path and is used to break cycles
+func (e *evaluationState) checkPreRequisiteChain(fc *rest.FeatureConfig, target *Target,
+	visited map[string]struct{}) (prerequisiteCheck, error) {
 	if e.query == nil {
 		e.logger.Errorf(ErrQueryProviderMissing.Error())
-		return true, ErrQueryProviderMissing
+		return prerequisiteCheck{satisfied: true}, ErrQueryProviderMissing
 	}
+	result := prerequisiteCheck{satisfied: true}
 	prerequisites := fc.Prerequisites
 	if prerequisites != nil {
 		e.logger.Debugf(
 			"Checking pre requisites %v of parent feature %v",
 			prerequisites,
 			fc.Feature)
+		visited[fc.Feature] = struct{}{}
+		defer delete(visited, fc.Feature)
 		for _, pre := range *prerequisites {
-			prereqFeature := pre.Feature
-			prereqFeatureConfig, err := e.query.GetFlag(prereqFeature)
-			if err != nil {
-				e.logger.Errorf(
-					"Could not retrieve the pre requisite details of feature flag : %v", prereqFeature)
-				return true, nil
+			node := e.trace.beginPrerequisite(pre.Feature)
+			check, decided := e.checkSinglePreRequisite(fc.Feature, pre, target, visited)
+			e.trace.end(node, check.satisfied)
+			if decided {
+				if !check.satisfied {
+					reason := newEvaluationReason(ReasonPrerequisiteFailed)
+					reason.Prerequisite = pre.Feature
+					reason.PrerequisiteFailure = check.failure
+					e.reason.set(reason)
+				}
+				return check, nil
 			}
-
-			prereqEvaluatedVariation, err := e.evaluateFlag(prereqFeatureConfig, target)
-			if err != nil {
-				e.logger.Errorf(
-					"Could not evaluate the prerequisite details of feature flag : %v", prereqFeature)
-				return true, nil
+			// prerequisites further down the chain which couldn't be resolved are reported
+			// unless a later prerequisite decides the outcome
+			if check.failure != "" && result.failure == "" {
+				result = check
 			}
+		}
+	}
+	return result, nil
+}
 
-			e.logger.Debugf(
-				"Pre requisite flag %v has variation %v for target %v",
-				prereqFeatureConfig.Feature,
-				prereqEvaluatedVariation,
-				target)
-
-			// Compare if the pre requisite variation is a possible valid value of
-			// the pre requisite FF
-			validPrereqVariations := pre.Variations
-			e.logger.Debugf(
-				"Pre requisite flag %v should have the variations %v",
-				prereqFeatureConfig.Feature,
-				validPrereqVariations)
-			if !contains(validPrereqVariations, prereqEvaluatedVariation.Identifier) {
-				return false, nil
-			}
-			if r, _ := e.checkPreRequisite(&prereqFeatureConfig, target); !r {
-				return false, nil
-			}
+// prerequisiteDepth is the maximum number of nested prerequisites
+func (e Evaluator) prerequisiteDepth() int {
+	if e.maxPrerequisiteDepth > 0 {
+		return e.maxPrerequisiteDepth
+	}
+	return defaultMaxPrerequisiteDepth
+}
+
+// unavailablePrerequisite is the outcome of a prerequisite which couldn't be retrieved or evaluated
+func (e Evaluator) unavailablePrerequisite(feature string) prerequisiteCheck {
+	return prerequisiteCheck{
+		satisfied: e.prerequisiteError == PrerequisiteErrorPass,
+		feature:   feature,
+		failure:   PrerequisiteUnavailable,
+	}
+}
+
+// checkSinglePreRequisite checks the prerequisite and reports whether that outcome is final for
+// the parent feature, which is the case when it is unmet or can't be resolved
+func (e *evaluationState) checkSinglePreRequisite(parent string, pre rest.Prerequisite, target *Target,
+	visited map[string]struct{}) (prerequisiteCheck, bool) {
+	e.counts.prerequisite()
+	prereqFeature := pre.Feature
+	if _, ok := visited[prereqFeature]; ok {
+		e.logger.Errorf(
+			"Pre requisite cycle detected, feature flag %v is already on the pre requisite path", prereqFeature)
+		return prerequisiteCheck{feature: prereqFeature, failure: PrerequisiteCycle}, true
+	}
+	if depth := e.prerequisiteDepth(); len(visited) > depth {
+		e.logger.Errorf(
+			"Pre requisite flag %v is nested deeper than %d pre requisites", prereqFeature, depth)
+		return prerequisiteCheck{feature: prereqFeature, failure: PrerequisiteTooDeep}, true
+	}
+	prereqFeatureConfig, err := e.getFlag(prereqFeature)
+	if err != nil {
+		e.logger.Errorf(
+			"Could not retrieve the pre requisite details of feature flag : %v, err: %v", prereqFeature, err)
+		e.errs.record(fmt.Errorf("%w: %s: %v", ErrPrerequisiteUnavailable, prereqFeature, err))
+		return e.unavailablePrerequisite(prereqFeature), true
+	}
+
+	prereqEvaluatedVariation, ok := e.memo.prerequisite(prereqFeature)
+	if !ok {
+		prereqEvaluatedVariation, err = e.evaluateFlag(prereqFeatureConfig, target)
+		if err != nil {
+			e.logger.Errorf(
+				"Could not evaluate the prerequisite details of feature flag : %v, err: %v", prereqFeature, err)
+			e.errs.record(fmt.Errorf("%w: %s: %v", ErrPrerequisiteUnavailable, prereqFeature, err))
+			return e.unavailablePrerequisite(prereqFeature), true
 		}
+		e.memo.setPrerequisite(prereqFeature, prereqEvaluatedVariation)
+	}
+
+	e.logger.Debugf(
+		"Pre requisite flag %v has variation %v for target %v",
+		prereqFeatureConfig.Feature,
+		prereqEvaluatedVariation,
+		target)
+
+	// Compare if the pre requisite variation is a possible valid value of
+	// the pre requisite FF
+	validPrereqVariations := pre.Variations
+	e.logger.Debugf(
+		"Pre requisite flag %v should have the variations %v",
+		prereqFeatureConfig.Feature,
+		validPrereqVariations)
+	satisfied := contains(validPrereqVariations, prereqEvaluatedVariation.Identifier)
+	e.prerequisites.record(parent, prereqFeature, prereqEvaluatedVariation, satisfied)
+	if !satisfied {
+		return prerequisiteCheck{feature: prereqFeature, failure: PrerequisiteUnmet}, true
 	}
-	return true, nil
+	check, _ := e.checkPreRequisiteChain(&prereqFeatureConfig, target, visited)
+	return check, !check.satisfied
 }
 
-func (e Evaluator) evaluate(identifier string, target *Target, kind string) (rest.Variation, error) {
+func (e *evaluationState) evaluate(identifier string, target *Target, kind string) (rest.Variation, error) {
+	endSpan := e.startSpan(identifier)
+	variation, err := e.evaluateIdentifier(identifier, target, kind)
+	endSpan(variation, err)
+	e.recordEvaluation(identifier, target, variation, err)
+	return variation, err
+}
 
+func (e Evaluator) recordEvaluation(identifier string, target *Target, variation rest.Variation, err error) {
+	if e.metricsCallback == nil {
+		return
+	}
+	if err != nil {
+		e.metricsCallback.RecordEvaluation(identifier, target, nil, err)
+		return
+	}
+	e.metricsCallback.RecordEvaluation(identifier, target, &variation, nil)
+}
+
+// flag retrieves the flag every entry point starts evaluating from
+func (e *evaluationState) flag(identifier string) (rest.FeatureConfig, error) {
 	if e.query == nil {
 		e.logger.Errorf(ErrQueryProviderMissing.Error())
-		return rest.Variation{}, ErrQueryProviderMissing
+		return rest.FeatureConfig{}, ErrQueryProviderMissing
 	}
-	flag, err := e.query.GetFlag(identifier)
+	return e.getFlag(identifier)
+}
+
+func (e *evaluationState) evaluateIdentifier(identifier string, target *Target, kind string) (rest.Variation, error) {
+	flag, err := e.flag(identifier)
 	if err != nil {
+		if errors.Is(err, repository.ErrFeatureConfigNotFound) {
+			return rest.Variation{}, err
+		}
+		if variation, ok := e.lastKnown.get(identifier, kind, target); ok && e.contextErr() == nil {
+			e.logger.Warnf("Flag %s couldn't be retrieved, serving its last known variation %s, err: %v",
+				identifier, variation.Identifier, err)
+			e.reason.set(newEvaluationReason(ReasonLastKnown))
+			return variation, nil
+		}
 		return rest.Variation{}, err
 	}
-	if string(flag.Kind) != kind {
+	if !kindMatches(flag.Kind, kind) {
 		return rest.Variation{}, fmt.Errorf("%w, expected: %s, got: %s", ErrFlagKindMismatch, kind, flag.Kind)
 	}
 
-	if flag.Prerequisites != nil {
-		prereq, err := e.checkPreRequisite(&flag, target)
-		if err != nil || !prereq {
-			return findVariation(flag.Variations, flag.OffVariation)
-		}
+	if e.strict {
+		e.clauseErr = &clauseError{}
+	}
+	variation, err := e.evaluateFeature(flag, target)
+	if err == nil {
+		err = e.clauseErr.err()
 	}
-	variation, err := e.evaluateFlag(flag, target)
 	if err != nil {
 		return rest.Variation{}, err
 	}
+	// lookups failing because the context ended must not be mistaken for a valid result
+	if err := e.contextErr(); err != nil {
+		return rest.Variation{}, err
+	}
+	// rejected variations are neither remembered nor reported as served
+	if kind == "json" && e.maxJSONVariationSize > 0 && len(variation.Value) > e.maxJSONVariationSize {
+		return rest.Variation{}, fmt.Errorf("%w: %s is %d bytes", ErrVariationTooLarge, identifier,
+			len(variation.Value))
+	}
+	e.reason.setFlagVersion(flag.Version)
+	e.lastKnown.set(identifier, kind, target, variation)
+	e.postEvaluate(flag, target, variation)
+	return variation, nil
+}
+
+// kindMatches reports whether a flag of kind flagKind can be evaluated as kind, number flags also
+// accept int flags as numbers used to be stored as ints
+func kindMatches(flagKind rest.FeatureConfigKind, kind string) bool {
+	return string(flagKind) == kind || (kind == "number" && flagKind == rest.FeatureConfigKindInt)
+}
+
+func (e Evaluator) postEvaluate(flag rest.FeatureConfig, target *Target, variation rest.Variation) {
 	if e.postEvalCallback != nil {
 		data := PostEvalData{
 			FeatureConfig: &flag,
@@ -344,73 +1481,458 @@ func (e Evaluator) evaluate(identifier string, target *Target, kind string) (res
 
 		e.postEvalCallback.PostEvaluateProcessor(&data)
 	}
-	return variation, nil
+}
+
+// evaluateFeature checks prerequisites of the flag and evaluates it for the target
+func (e *evaluationState) evaluateFeature(flag rest.FeatureConfig, target *Target) (rest.Variation, error) {
+	if flag.Prerequisites == nil {
+		return e.evaluateFlag(flag, target)
+	}
+	check, err := e.checkPreRequisite(&flag, target)
+	if err != nil || !check.satisfied {
+		if err != nil {
+			e.reason.set(newEvaluationReason(ReasonPrerequisiteFailed))
+		}
+		return findVariation(flag.Variations, flag.OffVariation)
+	}
+	variation, err := e.evaluateFlag(flag, target)
+	// prerequisites which couldn't be resolved but were treated as satisfied are still named by the reason
+	if err == nil && check.failure != "" {
+		e.reason.setPrerequisite(check.feature, check.failure)
+	}
+	return variation, err
+}
+
+// EvaluatePath evaluates the flag for the target without any post evaluation processing
+// and returns the targeting path taken as a compact string, for example
+// "variationMap→miss; rule#2→clause country equal US match; serve blue"
+func (e Evaluator) EvaluatePath(identifier string, target *Target) (string, rest.Variation, error) {
+	state := e.newEvaluation(context.Background())
+	flag, err := state.flag(identifier)
+	if err != nil {
+		return "", rest.Variation{}, err
+	}
+
+	state.trace = &evaluationTrace{}
+	variation, err := state.evaluateFeature(flag, target)
+	if err != nil {
+		return state.trace.path(rest.Variation{}), rest.Variation{}, err
+	}
+	return state.trace.path(variation), variation, nil
+}
+
+// MatchingRules returns the ids of all serving rules of the flag whose clauses match the target
+// in priority order, not only the first one which would be served. It is meant for diagnosing
+// overlapping or shadowed rules.
+func (e Evaluator) MatchingRules(identifier string, target *Target) ([]string, error) {
+	state := e.newEvaluation(context.Background())
+	flag, err := state.flag(identifier)
+	if err != nil {
+		return nil, err
+	}
+	matching := make([]string, 0)
+	if flag.Rules == nil || target == nil {
+		return matching, nil
+	}
+	for _, rule := range sortedRules(*flag.Rules) {
+		if matched, _ := state.evaluateRuleClauses(&rule, target); matched {
+			matching = append(matching, rule.RuleId)
+		}
+	}
+	return matching, nil
+}
+
+// MatchClause reports whether the clause matches the target exactly as it would while evaluating a
+// flag. It is meant for testing clauses against sample targets without setting up a flag.
+func (e Evaluator) MatchClause(clause *rest.Clause, target *Target) bool {
+	return e.newEvaluation(context.Background()).evaluateClause(clause, target)
+}
+
+// MatchRule reports whether the clauses of the serving rule match the target exactly as they would
+// while evaluating a flag, without recording clause statistics. It is meant for testing rules
+// against sample targets without setting up a flag.
+func (e Evaluator) MatchRule(rule *rest.ServingRule, target *Target) bool {
+	matched, _ := e.newEvaluation(context.Background()).evaluateRuleClauses(rule, target)
+	return matched
+}
+
+// AssertVariation evaluates the flag for the target without any post evaluation processing and
+// returns an error describing the reason and matched rule when the served variation identifier
+// differs from expected. It is meant for gating configuration changes in CI.
+func (e Evaluator) AssertVariation(identifier string, target *Target, expected string) error {
+	state := e.newEvaluation(context.Background())
+	flag, err := state.flag(identifier)
+	if err != nil {
+		return err
+	}
+
+	variation, err := state.evaluateFeature(flag, target)
+	if err != nil {
+		return err
+	}
+	if variation.Identifier != expected {
+		return fmt.Errorf("%w: flag %s served %s instead of %s, reason: %s",
+			ErrVariationMismatch, identifier, variation.Identifier, expected, *state.reason)
+	}
+	return nil
+}
+
+// ValidateSegments checks that every segment referenced by the variation maps and rules of the
+// flag, including segmentMatch clauses nested in the rules of referenced segments, resolves via
+// the Query provider. It returns an error for each dangling or failing reference.
+func (e Evaluator) ValidateSegments(fc rest.FeatureConfig) []error {
+	if e.query == nil {
+		return []error{ErrQueryProviderMissing}
+	}
+	var references []string
+	if fc.VariationToTargetMap != nil {
+		for _, vm := range *fc.VariationToTargetMap {
+			if vm.TargetSegments != nil {
+				references = append(references, *vm.TargetSegments...)
+			}
+		}
+	}
+	if fc.Rules != nil {
+		for _, rule := range *fc.Rules {
+			references = append(references, segmentMatchReferences(rule.Clauses)...)
+		}
+	}
+
+	var errs []error
+	state := e.newEvaluation(context.Background())
+	visited := make(map[string]struct{})
+	for len(references) > 0 {
+		identifier := references[0]
+		references = references[1:]
+		if _, ok := visited[identifier]; ok {
+			continue
+		}
+		visited[identifier] = struct{}{}
+		segment, err := state.getSegment(identifier)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: flag %s references segment %s: %v",
+				ErrSegmentReference, fc.Feature, identifier, err))
+			continue
+		}
+		if segment.Rules != nil {
+			references = append(references, segmentMatchReferences(*segment.Rules)...)
+		}
+	}
+	return errs
+}
+
+// segmentMatchReferences returns the segment identifiers referenced by segmentMatch clauses
+func segmentMatchReferences(clauses []rest.Clause) []string {
+	var references []string
+	for _, clause := range clauses {
+		if clause.Op == segmentMatchOperator {
+			references = append(references, clause.Values...)
+		}
+	}
+	return references
+}
+
+func (e Evaluator) listFlags() ([]rest.FeatureConfig, error) {
+	if e.query == nil {
+		e.logger.Errorf(ErrQueryProviderMissing.Error())
+		return nil, ErrQueryProviderMissing
+	}
+	lister, ok := e.query.(FlagLister)
+	if !ok {
+		return nil, ErrFlagListingUnsupported
+	}
+	return lister.GetFlags()
+}
+
+// EnabledBoolFlags returns identifiers of all boolean flags which evaluate to true for the target,
+// flags of other kinds are skipped. It requires the Query provider to implement FlagLister.
+func (e Evaluator) EnabledBoolFlags(target *Target) ([]string, error) {
+	flags, err := e.listFlags()
+	if err != nil {
+		return nil, err
+	}
+	enabled := make([]string, 0)
+	for _, flag := range flags {
+		if flag.Kind != "boolean" {
+			continue
+		}
+		if e.BoolVariation(flag.Feature, target, false) {
+			enabled = append(enabled, flag.Feature)
+		}
+	}
+	sort.Strings(enabled)
+	return enabled, nil
+}
+
+// EvaluateAll evaluates every flag for the target and returns the served variations keyed by
+// flag identifier, flags which fail to evaluate are left out. Segments and prerequisites are
+// resolved once for the whole sweep. It requires the Query provider to implement FlagLister.
+func (e Evaluator) EvaluateAll(target *Target) (map[string]rest.Variation, error) {
+	flags, err := e.listFlags()
+	if err != nil {
+		return nil, err
+	}
+	memo := newEvaluationMemo(flags)
+	variations := make(map[string]rest.Variation, len(flags))
+	for _, flag := range flags {
+		state := e.newEvaluation(context.Background())
+		state.memo = memo
+		variation, err := state.evaluate(flag.Feature, target, string(flag.Kind))
+		if err != nil {
+			e.logger.Errorf("Error while evaluating flag '%s', err: %v", flag.Feature, err)
+			continue
+		}
+		variations[flag.Feature] = variation
+	}
+	return variations, nil
+}
+
+// EvaluateFlagsForTarget evaluates the flags with the given identifiers for the target and returns the
+// served variations keyed by flag identifier, flags which fail to evaluate are left out. Flags,
+// segments and prerequisites are resolved once for the whole set.
+func (e Evaluator) EvaluateFlagsForTarget(identifiers []string, target *Target) (map[string]rest.Variation, error) {
+	if e.query == nil {
+		e.logger.Errorf(ErrQueryProviderMissing.Error())
+		return nil, ErrQueryProviderMissing
+	}
+	memo := newEvaluationMemo(nil)
+	variations := make(map[string]rest.Variation, len(identifiers))
+	for _, identifier := range identifiers {
+		state := e.newEvaluation(context.Background())
+		state.memo = memo
+		flag, err := state.flag(identifier)
+		if err != nil {
+			e.logger.Errorf("Error while retrieving flag '%s', err: %v", identifier, err)
+			e.recordEvaluation(identifier, target, rest.Variation{}, err)
+			continue
+		}
+		// the flag is memoized so evaluating it doesn't retrieve it again
+		variation, err := state.evaluate(identifier, target, string(flag.Kind))
+		if err != nil {
+			e.logger.Errorf("Error while evaluating flag '%s', err: %v", identifier, err)
+			continue
+		}
+		variations[identifier] = variation
+	}
+	return variations, nil
+}
+
+// Evaluate evaluates the flag of the given kind for the target and returns the served variation
+// together with the reason describing which step of the evaluation decided it
+func (e Evaluator) Evaluate(identifier string, target *Target, kind string) (rest.Variation, EvaluationReason, error) {
+	return e.EvaluateCtx(context.Background(), identifier, target, kind)
+}
+
+// EvaluateCtx is like Evaluate but stops retrieving flags and segments and returns the
+// context error once ctx is cancelled or its deadline is exceeded
+func (e Evaluator) EvaluateCtx(ctx context.Context, identifier string, target *Target,
+	kind string) (rest.Variation, EvaluationReason, error) {
+	return e.newEvaluation(ctx).evaluateWithReason(identifier, target, kind)
+}
+
+// evaluateWithReason evaluates the flag and returns the served variation together with its reason
+func (e *evaluationState) evaluateWithReason(identifier string, target *Target,
+	kind string) (rest.Variation, EvaluationReason, error) {
+	variation, err := e.evaluate(identifier, target, kind)
+	if errors.Is(err, ErrVariationTooLarge) {
+		return rest.Variation{}, newEvaluationReason(ReasonParseError), err
+	}
+	if err != nil {
+		return rest.Variation{}, newEvaluationReason(ReasonError), err
+	}
+	return variation, *e.reason, nil
+}
+
+// disabledErr returns ErrFlagDisabled when the off variation was served because the flag is turned off
+func disabledErr(identifier string, reason EvaluationReason) error {
+	if reason.Kind == ReasonOff {
+		return fmt.Errorf("%w: %s", ErrFlagDisabled, identifier)
+	}
+	return nil
 }
 
 // BoolVariation returns boolean evaluation for target
 func (e Evaluator) BoolVariation(identifier string, target *Target, defaultValue bool) bool {
-	variation, err := e.evaluate(identifier, target, "boolean")
-	if err != nil {
+	return e.BoolVariationCtx(context.Background(), identifier, target, defaultValue)
+}
+
+// BoolVariationCtx is like BoolVariation but returns defaultValue once ctx is cancelled or its deadline is exceeded
+func (e Evaluator) BoolVariationCtx(ctx context.Context, identifier string, target *Target, defaultValue bool) bool {
+	value, detail := e.newEvaluation(ctx).boolVariation(identifier, target, defaultValue)
+	if err := detail.Error; err != nil && !errors.Is(err, ErrFlagDisabled) {
 		e.logger.Errorf("Error while evaluating boolean flag '%s', err: %v", identifier, err)
-		return defaultValue
 	}
-	return strings.ToLower(variation.Value) == "true"
+	return value
 }
 
-// StringVariation returns string evaluation for target
-func (e Evaluator) StringVariation(identifier string, target *Target, defaultValue string) string {
+// BoolVariationWithErr is like BoolVariation but also returns the error when the flag is missing,
+// of another kind or its value isn't a boolean, in which case defaultValue is returned. When the flag is
+// turned off the value of its off variation is returned together with ErrFlagDisabled
+func (e Evaluator) BoolVariationWithErr(identifier string, target *Target, defaultValue bool) (bool, error) {
+	value, detail := e.newEvaluation(context.Background()).boolVariation(identifier, target, defaultValue)
+	return value, detail.Error
+}
 
-	variation, err := e.evaluate(identifier, target, "string")
+func (e *evaluationState) boolVariation(identifier string, target *Target,
+	defaultValue bool) (bool, EvaluationDetail) {
+	variation, reason, err := e.evaluateWithReason(identifier, target, "boolean")
 	if err != nil {
+		return defaultValue, EvaluationDetail{Reason: reason, Error: err}
+	}
+	val, err := parseBool(variation.Value)
+	if err != nil {
+		return defaultValue, parseErrorDetail(fmt.Errorf("%w: %s has value %q", ErrInvalidVariationValue, identifier,
+			variation.Value))
+	}
+	return val, servedDetail(identifier, variation, reason)
+}
+
+// StringVariation returns string evaluation for target, served values of the form "@flag:otherFlag"
+// are resolved by evaluating the string flag otherFlag for the same target
+func (e Evaluator) StringVariation(identifier string, target *Target, defaultValue string) string {
+	return e.StringVariationCtx(context.Background(), identifier, target, defaultValue)
+}
+
+// StringVariationCtx is like StringVariation but returns defaultValue once ctx is cancelled or its deadline is exceeded
+func (e Evaluator) StringVariationCtx(ctx context.Context, identifier string, target *Target,
+	defaultValue string) string {
+	value, detail := e.newEvaluation(ctx).stringVariation(identifier, target, defaultValue)
+	if err := detail.Error; err != nil && !errors.Is(err, ErrFlagDisabled) {
 		e.logger.Errorf("Error while evaluating string flag '%s', err: %v", identifier, err)
-		return defaultValue
 	}
-	return variation.Value
+	return value
+}
+
+// StringVariationWithErr is like StringVariation but also returns the error when the flag is
+// missing or of another kind, in which case defaultValue is returned. When the flag is
+// turned off the value of its off variation is returned together with ErrFlagDisabled
+func (e Evaluator) StringVariationWithErr(identifier string, target *Target, defaultValue string) (string, error) {
+	value, detail := e.newEvaluation(context.Background()).stringVariation(identifier, target, defaultValue)
+	return value, detail.Error
+}
+
+func (e *evaluationState) stringVariation(identifier string, target *Target,
+	defaultValue string) (string, EvaluationDetail) {
+	variation, reason, err := e.evaluateWithReason(identifier, target, "string")
+	if err != nil {
+		return defaultValue, EvaluationDetail{Reason: reason, Error: err}
+	}
+	value, err := e.resolveFlagReference(e.ctx, identifier, target, variation.Value)
+	if err != nil {
+		return defaultValue, EvaluationDetail{Reason: newEvaluationReason(ReasonError), Error: err}
+	}
+	return value, servedDetail(identifier, variation, reason)
 }
 
 // IntVariation returns int evaluation for target
 func (e Evaluator) IntVariation(identifier string, target *Target, defaultValue int) int {
+	return e.IntVariationCtx(context.Background(), identifier, target, defaultValue)
+}
 
-	variation, err := e.evaluate(identifier, target, "int")
-	if err != nil {
+// IntVariationCtx is like IntVariation but returns defaultValue once ctx is cancelled or its deadline is exceeded
+func (e Evaluator) IntVariationCtx(ctx context.Context, identifier string, target *Target, defaultValue int) int {
+	value, detail := e.newEvaluation(ctx).intVariation(identifier, target, defaultValue)
+	if err := detail.Error; err != nil && !errors.Is(err, ErrFlagDisabled) {
 		e.logger.Errorf("Error while evaluating int flag '%s', err: %v", identifier, err)
-		return defaultValue
+	}
+	return value
+}
+
+// IntVariationWithErr is like IntVariation but also returns the error when the flag is missing,
+// of another kind or its value isn't an integer, in which case defaultValue is returned. When the flag is
+// turned off the value of its off variation is returned together with ErrFlagDisabled
+func (e Evaluator) IntVariationWithErr(identifier string, target *Target, defaultValue int) (int, error) {
+	value, detail := e.newEvaluation(context.Background()).intVariation(identifier, target, defaultValue)
+	return value, detail.Error
+}
+
+func (e *evaluationState) intVariation(identifier string, target *Target,
+	defaultValue int) (int, EvaluationDetail) {
+	variation, reason, err := e.evaluateWithReason(identifier, target, "int")
+	if err != nil {
+		return defaultValue, EvaluationDetail{Reason: reason, Error: err}
 	}
 	val, err := strconv.Atoi(variation.Value)
 	if err != nil {
-		return defaultValue
+		return defaultValue, parseErrorDetail(fmt.Errorf("%w: %s has value %q", ErrInvalidVariationValue, identifier,
+			variation.Value))
 	}
-	return val
+	return val, servedDetail(identifier, variation, reason)
 }
 
-// NumberVariation returns number evaluation for target
+// NumberVariation returns number evaluation for target, flags of the number kind as well as int flags are accepted
 func (e Evaluator) NumberVariation(identifier string, target *Target, defaultValue float64) float64 {
-	//all numbers are stored as ints in the database
-	variation, err := e.evaluate(identifier, target, "int")
-	if err != nil {
+	return e.NumberVariationCtx(context.Background(), identifier, target, defaultValue)
+}
+
+// NumberVariationCtx is like NumberVariation but returns defaultValue once ctx is cancelled or its deadline is exceeded
+func (e Evaluator) NumberVariationCtx(ctx context.Context, identifier string, target *Target,
+	defaultValue float64) float64 {
+	value, detail := e.newEvaluation(ctx).numberVariation(identifier, target, defaultValue)
+	if err := detail.Error; err != nil && !errors.Is(err, ErrFlagDisabled) {
 		e.logger.Errorf("Error while evaluating number flag '%s', err: %v", identifier, err)
-		return defaultValue
+	}
+	return value
+}
+
+// NumberVariationWithErr is like NumberVariation but also returns the error when the flag is
+// missing, of another kind or its value isn't a number, in which case defaultValue is returned. When the flag is
+// turned off the value of its off variation is returned together with ErrFlagDisabled
+func (e Evaluator) NumberVariationWithErr(identifier string, target *Target,
+	defaultValue float64) (float64, error) {
+	value, detail := e.newEvaluation(context.Background()).numberVariation(identifier, target, defaultValue)
+	return value, detail.Error
+}
+
+func (e *evaluationState) numberVariation(identifier string, target *Target,
+	defaultValue float64) (float64, EvaluationDetail) {
+	variation, reason, err := e.evaluateWithReason(identifier, target, "number")
+	if err != nil {
+		return defaultValue, EvaluationDetail{Reason: reason, Error: err}
 	}
 	val, err := strconv.ParseFloat(variation.Value, 64)
 	if err != nil {
-		return defaultValue
+		return defaultValue, parseErrorDetail(fmt.Errorf("%w: %s has value %q", ErrInvalidVariationValue, identifier,
+			variation.Value))
 	}
-	return val
+	return val, servedDetail(identifier, variation, reason)
 }
 
 // JSONVariation returns json evaluation for target
 func (e Evaluator) JSONVariation(identifier string, target *Target,
 	defaultValue map[string]interface{}) map[string]interface{} {
+	return e.JSONVariationCtx(context.Background(), identifier, target, defaultValue)
+}
 
-	variation, err := e.evaluate(identifier, target, "json")
-	if err != nil {
+// JSONVariationCtx is like JSONVariation but returns defaultValue once ctx is cancelled or its deadline is exceeded
+func (e Evaluator) JSONVariationCtx(ctx context.Context, identifier string, target *Target,
+	defaultValue map[string]interface{}) map[string]interface{} {
+	value, detail := e.newEvaluation(ctx).jsonVariation(identifier, target, defaultValue)
+	if err := detail.Error; err != nil && !errors.Is(err, ErrFlagDisabled) {
 		e.logger.Errorf("Error while evaluating json flag '%s', err: %v", identifier, err)
-		return defaultValue
+	}
+	return value
+}
+
+// JSONVariationWithErr is like JSONVariation but also returns the error when the flag is missing,
+// of another kind or its value isn't a json object, in which case defaultValue is returned. When the flag is
+// turned off the value of its off variation is returned together with ErrFlagDisabled
+func (e Evaluator) JSONVariationWithErr(identifier string, target *Target,
+	defaultValue map[string]interface{}) (map[string]interface{}, error) {
+	value, detail := e.newEvaluation(context.Background()).jsonVariation(identifier, target, defaultValue)
+	return value, detail.Error
+}
+
+func (e *evaluationState) jsonVariation(identifier string, target *Target,
+	defaultValue map[string]interface{}) (map[string]interface{}, EvaluationDetail) {
+	variation, reason, err := e.evaluateWithReason(identifier, target, "json")
+	if err != nil {
+		return defaultValue, EvaluationDetail{Reason: reason, Error: err}
 	}
 	val := make(map[string]interface{})
 	err = json.Unmarshal([]byte(variation.Value), &val)
 	if err != nil {
-		return defaultValue
+		return defaultValue, parseErrorDetail(fmt.Errorf("%w: %s: %v", ErrInvalidVariationValue, identifier, err))
 	}
-	return val
+	return val, servedDetail(identifier, variation, reason)
 }
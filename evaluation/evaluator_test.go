@@ -1,9 +1,15 @@
 package evaluation
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/harness/ff-golang-server-sdk/logger"
 
@@ -304,6 +310,14 @@ func (m TestRepository) GetFlag(identifier string) (rest.FeatureConfig, error) {
 	return flag, nil
 }
 
+func (m TestRepository) GetFlags() ([]rest.FeatureConfig, error) {
+	flags := make([]rest.FeatureConfig, 0, len(m.flags))
+	for _, flag := range m.flags {
+		flags = append(flags, flag)
+	}
+	return flags, nil
+}
+
 func TestNewEvaluator(t *testing.T) {
 	noOpLogger := logger.NewNoOpLogger()
 	eval, _ := NewEvaluator(testRepo, nil, noOpLogger)
@@ -617,1169 +631,4714 @@ func TestEvaluator_evaluateClause(t *testing.T) {
 			},
 			want: true,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			e := Evaluator{
-				query:  tt.fields.query,
-				logger: logger.NewNoOpLogger(),
-			}
-			if got := e.evaluateClause(tt.args.clause, tt.args.target); got != tt.want {
-				t.Errorf("Evaluator.evaluateClause() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestEvaluator_evaluateRules(t *testing.T) {
-	type fields struct {
-		query Query
-	}
-	type args struct {
-		servingRules []rest.ServingRule
-		target       *Target
-	}
-	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   string
-	}{
-		{
-			name: "when rules is nil return \"\"",
-			args: args{
-				servingRules: nil,
-			},
-			want: "",
-		},
 		{
-			name: "when target is nil return \"\"",
+			name:   "check glob any ci operator matches mixed case domain",
+			fields: fields{},
 			args: args{
-				target: nil,
+				clause: &rest.Clause{
+					Attribute: "email",
+					Op:        globAnyCIOperator,
+					Values:    []string{"*@harness.io", "*@Example.com"},
+				},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"email": "John.Doe@EXAMPLE.COM",
+					},
+				},
 			},
-			want: "",
+			want: true,
 		},
 		{
-			name: "evaluate rule",
+			name:   "check glob any ci operator (no pattern matches) should return false",
+			fields: fields{},
 			args: args{
-				// both rule clauses are true so it will serve false and true
-				// priority is on second one and should return true
-				servingRules: []rest.ServingRule{
-					{
-						Priority: 2,
-						Clauses: []rest.Clause{
-							{
-								Attribute: identifier,
-								Op:        equalOperator,
-								Values:    []string{harness},
-							},
-						},
-						Serve: rest.Serve{
-							Variation: &identifierFalse,
-						},
-					},
-					{
-						Priority: 1,
-						Clauses: []rest.Clause{
-							{
-								Attribute: identifier,
-								Op:        equalOperator,
-								Values:    []string{harness},
-							},
-						},
-						Serve: rest.Serve{
-							Variation: &identifierTrue,
-						},
-					},
+				clause: &rest.Clause{
+					Attribute: "email",
+					Op:        globAnyCIOperator,
+					Values:    []string{"*@harness.io", "*@Example.com"},
 				},
 				target: &Target{
 					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"email": "john.doe@example.org",
+					},
 				},
 			},
-			want: identifierTrue,
+			want: false,
 		},
 		{
-			name: "evaluate rule continue in for loop should occur",
+			name:   "check gte operator with equal values",
+			fields: fields{},
 			args: args{
-				// both rule clauses are true so it will serve false and true
-				// priority is on second one and should return true
-				servingRules: []rest.ServingRule{
-					{
-						Priority: 1,
-						Clauses: []rest.Clause{
-							{
-								Attribute: identifier,
-								Op:        equalOperator,
-								Values:    []string{"harnesss"},
-							},
-						},
-						Serve: rest.Serve{
-							Variation: &identifierTrue,
-						},
-					},
-					{
-						Priority: 2,
-						Clauses: []rest.Clause{
-							{
-								Attribute: identifier,
-								Op:        equalOperator,
-								Values:    []string{harness},
-							},
-						},
-						Serve: rest.Serve{
-							Variation: &identifierTrue,
-						},
-					},
+				clause: &rest.Clause{
+					Attribute: identifier,
+					Op:        gteOperator,
+					Values:    []string{"B"},
 				},
 				target: &Target{
-					Identifier: harness,
+					Identifier: "B",
 				},
 			},
-			want: identifierTrue,
+			want: true,
 		},
 		{
-			name: "evaluate rule by distribution",
+			name:   "check gte operator - negative path",
+			fields: fields{},
 			args: args{
-				servingRules: []rest.ServingRule{
-					{
-						Priority: 1,
-						Clauses: []rest.Clause{
-							{
-								Attribute: identifier,
-								Op:        equalOperator,
-								Values:    []string{harness},
-							},
-						},
-						Serve: rest.Serve{
-							Distribution: &rest.Distribution{
-								BucketBy: identifier,
-								Variations: []rest.WeightedVariation{
-									{Variation: identifierTrue, Weight: 5},
-									{Variation: identifierFalse, Weight: 95},
-								},
-							},
-						},
-					},
+				clause: &rest.Clause{
+					Attribute: identifier,
+					Op:        gteOperator,
+					Values:    []string{"B"},
 				},
 				target: &Target{
-					Identifier: harness,
+					Identifier: "A",
 				},
 			},
-			want: identifierFalse,
+			want: false,
 		},
 		{
-			name: "evaluate rule (target is nil) return variation identifier empty",
+			name:   "check lt operator",
+			fields: fields{},
 			args: args{
-				servingRules: []rest.ServingRule{
-					{
-						Priority: 1,
-						Clauses: []rest.Clause{
-							{
-								Attribute: identifier,
-								Op:        equalOperator,
-								Values:    []string{harness},
-							},
-						},
-						Serve: rest.Serve{
-							Variation: &identifierFalse,
-						},
-					},
+				clause: &rest.Clause{
+					Attribute: identifier,
+					Op:        ltOperator,
+					Values:    []string{"B"},
+				},
+				target: &Target{
+					Identifier: "A",
 				},
-				target: nil,
 			},
-			want: "",
+			want: true,
 		},
 		{
-			name: "when rules is empty return \"\"",
+			name:   "check lt operator with equal values - negative path",
+			fields: fields{},
 			args: args{
-				servingRules: []rest.ServingRule{},
+				clause: &rest.Clause{
+					Attribute: identifier,
+					Op:        ltOperator,
+					Values:    []string{"B"},
+				},
 				target: &Target{
-					Identifier: harness,
+					Identifier: "B",
 				},
 			},
-			want: "",
+			want: false,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			e := Evaluator{
-				query:  tt.fields.query,
-				logger: logger.NewNoOpLogger(),
-			}
-			if got := e.evaluateRules(tt.args.servingRules, tt.args.target); got != tt.want {
-				t.Errorf("Evaluator.evaluateRules() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestEvaluator_evaluateVariationMap(t *testing.T) {
-	type fields struct {
-		query Query
-	}
-	type args struct {
-		variationsMap []rest.VariationMap
-		target        *Target
-	}
-	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   string
-	}{
 		{
-			name: "when variations map is nil return \"\"",
+			name:   "check lte operator with equal values",
+			fields: fields{},
 			args: args{
-				variationsMap: nil,
+				clause: &rest.Clause{
+					Attribute: identifier,
+					Op:        lteOperator,
+					Values:    []string{"B"},
+				},
+				target: &Target{
+					Identifier: "B",
+				},
 			},
-			want: "",
+			want: true,
 		},
 		{
-			name: "when target is nil return \"\"",
+			name:   "check lte operator - negative path",
+			fields: fields{},
 			args: args{
-				target: nil,
+				clause: &rest.Clause{
+					Attribute: identifier,
+					Op:        lteOperator,
+					Values:    []string{"A"},
+				},
+				target: &Target{
+					Identifier: "B",
+				},
 			},
-			want: "",
+			want: false,
 		},
 		{
-			name: "when target identifier in targets serve true",
+			name:   "check gt operator compares numbers numerically",
+			fields: fields{},
 			args: args{
-				variationsMap: []rest.VariationMap{
-					{
-						Variation: identifierTrue,
-						Targets: &[]rest.TargetMap{
-							{
-								Identifier: &targetIdentifier,
-							},
-						},
-					},
+				clause: &rest.Clause{
+					Attribute: "age",
+					Op:        gtOperator,
+					Values:    []string{"9"},
 				},
 				target: &Target{
-					Identifier: targetIdentifier,
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"age": 10,
+					},
 				},
 			},
-			want: identifierTrue,
+			want: true,
 		},
 		{
-			name: "when all targets in all variation maps is nil then serve \"\"",
-			fields: fields{
-				query: testRepo,
-			},
+			name:   "check gt operator compares numeric strings numerically",
+			fields: fields{},
 			args: args{
-				variationsMap: []rest.VariationMap{
-					{
-						Variation:      identifierTrue,
-						TargetSegments: &[]string{beta},
-					},
+				clause: &rest.Clause{
+					Attribute: "age",
+					Op:        gtOperator,
+					Values:    []string{"99"},
 				},
 				target: &Target{
-					Identifier: targetIdentifier,
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"age": "100",
+					},
 				},
 			},
-			want: identifierTrue,
+			want: true,
 		},
 		{
-			name: "when all targets and segments in all variation maps is nil then serve \"\"",
-			fields: fields{
-				query: testRepo,
-			},
+			name:   "check lte operator compares numbers numerically",
+			fields: fields{},
 			args: args{
+				clause: &rest.Clause{
+					Attribute: "age",
+					Op:        lteOperator,
+					Values:    []string{"18"},
+				},
 				target: &Target{
-					Identifier: targetIdentifier,
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"age": 9,
+					},
 				},
 			},
-			want: "",
+			want: true,
 		},
 		{
-			name: "target identifier in segments serve true",
-			fields: fields{
-				query: testRepo,
-			},
+			name:   "check gt operator with mixed numeric and non-numeric values compares as strings",
+			fields: fields{},
 			args: args{
-				variationsMap: []rest.VariationMap{
-					{
-						Variation:      identifierTrue,
-						TargetSegments: &[]string{beta},
-					},
+				clause: &rest.Clause{
+					Attribute: "age",
+					Op:        gtOperator,
+					Values:    []string{"abc"},
 				},
 				target: &Target{
-					Identifier: targetIdentifier,
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"age": 100,
+					},
 				},
 			},
-			want: identifierTrue,
+			want: false,
 		},
 		{
-			name: "when variations map is empty return \"\"",
+			name:   "check float equal operator within epsilon",
+			fields: fields{},
 			args: args{
-				variationsMap: []rest.VariationMap{},
+				clause: &rest.Clause{
+					Attribute: "score",
+					Op:        floatEqualOperator,
+					Values:    []string{"0.3"},
+				},
 				target: &Target{
-					Identifier: targetIdentifier,
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"score": "0.30000000000000004",
+					},
 				},
 			},
-			want: "",
+			want: true,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			e := Evaluator{
-				query:  tt.fields.query,
-				logger: logger.NewNoOpLogger(),
-			}
-			if got := e.evaluateVariationMap(tt.args.variationsMap, tt.args.target); got != tt.want {
-				t.Errorf("Evaluator.evaluateVariationMap() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestEvaluator_evaluateFlag(t *testing.T) {
-	type fields struct {
-		query Query
-	}
-	type args struct {
-		fc     rest.FeatureConfig
-		target *Target
-	}
-	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    rest.Variation
-		wantErr bool
-	}{
 		{
-			name: "evaluation of flag when is off state serve off variation",
+			name:   "check float equal operator beyond epsilon",
+			fields: fields{},
 			args: args{
-				fc: rest.FeatureConfig{
-					OffVariation: offVariation,
-					State:        rest.FeatureStateOff,
-					Variations:   boolVariations,
+				clause: &rest.Clause{
+					Attribute: "score",
+					Op:        floatEqualOperator,
+					Values:    []string{"0.3"},
+				},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"score": "0.3001",
+					},
 				},
 			},
-			want:    boolVariations[1],
-			wantErr: false,
+			want: false,
 		},
 		{
-			name: "evaluation with target when flag is off serve off variation",
+			name:   "check float equal operator with non numeric attribute",
+			fields: fields{},
 			args: args{
-				fc: rest.FeatureConfig{
-					OffVariation: offVariation,
-					State:        rest.FeatureStateOff,
-					Variations:   boolVariations,
+				clause: &rest.Clause{
+					Attribute: identifier,
+					Op:        floatEqualOperator,
+					Values:    []string{"0.3"},
 				},
 				target: &Target{
 					Identifier: harness,
 				},
 			},
-			want:    boolVariations[1],
-			wantErr: false,
+			want: false,
 		},
 		{
-			name: "evaluate flag should return default serve variation",
+			name:   "check equal operator with float attribute",
+			fields: fields{},
 			args: args{
-				fc: rest.FeatureConfig{
-					State:      rest.FeatureStateOn,
-					Variations: boolVariations,
-					DefaultServe: rest.Serve{
-						Variation: &boolVariations[0].Value,
-					},
+				clause: &rest.Clause{
+					Attribute: "score",
+					Op:        equalOperator,
+					Values:    []string{"4.5"},
 				},
 				target: &Target{
 					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"score": 4.5,
+					},
 				},
 			},
-			want:    boolVariations[0],
-			wantErr: false,
+			want: true,
 		},
 		{
-			name: "evaluate flag should return default serve distribution",
+			name:   "check equal operator with float32 attribute",
+			fields: fields{},
 			args: args{
-				fc: rest.FeatureConfig{
-					State:      rest.FeatureStateOn,
-					Variations: boolVariations,
-					DefaultServe: rest.Serve{
-						Distribution: &rest.Distribution{
-							Variations: []rest.WeightedVariation{
-								{
-									Variation: identifierTrue,
-									Weight:    5,
-								},
-								{
-									Variation: identifierFalse,
-									Weight:    95,
-								},
-							},
-						},
-					},
+				clause: &rest.Clause{
+					Attribute: "score",
+					Op:        equalOperator,
+					Values:    []string{"0.1"},
 				},
 				target: &Target{
 					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"score": float32(0.1),
+					},
 				},
 			},
-			want:    boolVariations[1],
-			wantErr: false,
+			want: true,
 		},
 		{
-			name: "evaluate flag should return rule serve",
+			name:   "check in operator with uint attribute",
+			fields: fields{},
 			args: args{
-				fc: rest.FeatureConfig{
-					State:      rest.FeatureStateOn,
-					Variations: boolVariations,
-					Rules: &[]rest.ServingRule{
-						{
-							Clauses: []rest.Clause{
-								{
-									Attribute: identifier,
-									Op:        equalOperator,
-									Values:    []string{harness},
-								},
-							},
-							Serve: rest.Serve{
-								Variation: &boolVariations[0].Value,
-							},
-						},
-					},
+				clause: &rest.Clause{
+					Attribute: "age",
+					Op:        inOperator,
+					Values:    []string{"18", "21"},
 				},
 				target: &Target{
 					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"age": uint8(21),
+					},
 				},
 			},
-			want:    boolVariations[0],
-			wantErr: false,
+			want: true,
 		},
 		{
-			name: "evaluate flag using variationMap and target should return 'true'",
+			name:   "check equal operator with int32 attribute",
+			fields: fields{},
 			args: args{
-				fc: rest.FeatureConfig{
-					State:      rest.FeatureStateOn,
-					Variations: boolVariations,
-					VariationToTargetMap: &[]rest.VariationMap{
-						{
-							Variation: identifierTrue,
-							Targets: &[]rest.TargetMap{
-								{
-									Identifier: &targetIdentifier,
-								},
-							},
-						},
-					},
+				clause: &rest.Clause{
+					Attribute: "age",
+					Op:        equalOperator,
+					Values:    []string{"-7"},
 				},
 				target: &Target{
-					Identifier: targetIdentifier,
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"age": int32(-7),
+					},
 				},
 			},
-			want:    boolVariations[0],
-			wantErr: false,
+			want: true,
 		},
 		{
-			name: "evaluate flag variation returns an error",
+			name:   "check not in operator",
+			fields: fields{},
 			args: args{
-				fc: rest.FeatureConfig{
-					State: rest.FeatureStateOn,
+				clause: &rest.Clause{
+					Attribute: "country",
+					Op:        notInOperator,
+					Values:    []string{"US", "CA"},
 				},
 				target: &Target{
-					Identifier: targetIdentifier,
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"country": "UK",
+					},
 				},
 			},
-			want:    rest.Variation{},
-			wantErr: true,
+			want: true,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			e := Evaluator{
-				query:  tt.fields.query,
-				logger: logger.NewNoOpLogger(),
-			}
-			got, err := e.evaluateFlag(tt.args.fc, tt.args.target)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Evaluator.evaluateFlag() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Evaluator.evaluateFlag() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestEvaluator_isTargetIncludedOrExcludedInSegment(t *testing.T) {
-	type fields struct {
-		query Query
-	}
-	type args struct {
-		segmentList []string
-		target      *Target
-	}
-	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   bool
-	}{
 		{
-			name: "segment list is empty return false",
+			name:   "check not in operator (found) should return false",
+			fields: fields{},
 			args: args{
-				segmentList: nil,
+				clause: &rest.Clause{
+					Attribute: "country",
+					Op:        notInOperator,
+					Values:    []string{"US", "CA"},
+				},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"country": "CA",
+					},
+				},
 			},
 			want: false,
 		},
 		{
-			name: "segment not found should return false",
-			fields: fields{
-				query: testRepo,
-			},
+			name:   "check not in operator with empty values should return false",
+			fields: fields{},
 			args: args{
-				segmentList: []string{"segmentNotFound1000"},
-			},
-			want: false,
-		},
+				clause: &rest.Clause{
+					Attribute: "country",
+					Op:        notInOperator,
+					Values:    []string{},
+				},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"country": "UK",
+					},
+				},
+			},
+			want: false,
+		},
 		{
-			name: "segment in excluded should return false",
-			fields: fields{
-				query: testRepo,
+			name:   "check not equal operator",
+			fields: fields{},
+			args: args{
+				clause: &rest.Clause{
+					Attribute: identifier,
+					Op:        notEqualOperator,
+					Values:    []string{harness},
+				},
+				target: &Target{
+					Identifier: "wings",
+				},
 			},
+			want: true,
+		},
+		{
+			name:   "check not equal operator (equal ignoring case) should return false",
+			fields: fields{},
 			args: args{
-				segmentList: []string{excluded},
+				clause: &rest.Clause{
+					Attribute: identifier,
+					Op:        notEqualOperator,
+					Values:    []string{harness},
+				},
 				target: &Target{
-					Identifier: harness,
+					Identifier: "Harness",
 				},
 			},
 			want: false,
 		},
 		{
-			name: "segment with target identifier should return true",
-			fields: fields{
-				query: testRepo,
-			},
+			name:   "check not equal operator with empty values should return false",
+			fields: fields{},
 			args: args{
-				segmentList: []string{beta},
+				clause: &rest.Clause{
+					Attribute: identifier,
+					Op:        notEqualOperator,
+					Values:    nil,
+				},
 				target: &Target{
-					Identifier: harness,
+					Identifier: "wings",
 				},
 			},
-			want: true,
+			want: false,
 		},
 		{
-			name: "evaluate rule in segment rules should return true",
+			name: "exists operator with a present attribute",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				segmentList: []string{alpha},
+				clause: &rest.Clause{Attribute: "betaOptIn", Op: existsOperator},
 				target: &Target{
 					Identifier: harness,
+					Attributes: &map[string]interface{}{"betaOptIn": false},
 				},
 			},
 			want: true,
 		},
 		{
-			name: "segment rule clause with false result should return false",
+			name: "exists operator with a present but empty attribute",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				segmentList: []string{alpha},
+				clause: &rest.Clause{Attribute: "betaOptIn", Op: existsOperator},
 				target: &Target{
-					Identifier: "no_identifier",
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"betaOptIn": ""},
 				},
 			},
 			want: false,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			e := Evaluator{
-				query:  tt.fields.query,
-				logger: logger.NewNoOpLogger(),
-			}
-			if got := e.isTargetIncludedOrExcludedInSegment(tt.args.segmentList, tt.args.target); got != tt.want {
-				t.Errorf("Evaluator.isTargetIncludedOrExcludedInSegment() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestEvaluator_checkPreRequisite(t *testing.T) {
-	type fields struct {
-		query Query
-	}
-	type args struct {
-		parent *rest.FeatureConfig
-		target *Target
-	}
-	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    bool
-		wantErr bool
-	}{
-		{
-			name: "data provider missing, returns error",
-			args: args{
-				parent: &rest.FeatureConfig{},
-			},
-			want:    true,
-			wantErr: true,
-		},
 		{
-			name: "no prerequities should return true",
+			name: "exists operator with an absent attribute",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				parent: &rest.FeatureConfig{},
+				clause: &rest.Clause{Attribute: "betaOptIn", Op: existsOperator, Values: []string{"ignored"}},
+				target: &Target{
+					Identifier: harness,
+				},
 			},
-			want: true,
+			want: false,
 		},
 		{
-			name: "prereq simple should return true",
+			name: "fuzzy_in operator within the distance",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				parent: &rest.FeatureConfig{
-					State: rest.FeatureStateOn,
-					Prerequisites: &[]rest.Prerequisite{
-						{
-							Feature:    simple,
-							Variations: []string{identifierTrue, identifierFalse},
-						},
-					},
+				clause: &rest.Clause{Attribute: "domain", Op: fuzzyInOperator, Values: []string{"1", "harness", "example"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"domain": "exampl"},
 				},
 			},
 			want: true,
 		},
 		{
-			name: "prereq flag doesn't exists it should return false",
+			name: "fuzzy_in operator beyond the distance",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				parent: &rest.FeatureConfig{
-					State: rest.FeatureStateOn,
-					Prerequisites: &[]rest.Prerequisite{
-						{
-							Feature:    "prereq not found",
-							Variations: []string{identifierTrue, identifierFalse},
-						},
-					},
+				clause: &rest.Clause{Attribute: "domain", Op: fuzzyInOperator, Values: []string{"1", "example"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"domain": "exmpl"},
 				},
 			},
-			want: true,
+			want: false,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			e := Evaluator{
-				query:  tt.fields.query,
-				logger: logger.NewNoOpLogger(),
-			}
-			got, err := e.checkPreRequisite(tt.args.parent, tt.args.target)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Evaluator.checkPreRequisite() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if got != tt.want {
-				t.Errorf("Evaluator.checkPreRequisite() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestEvaluator_evaluate(t *testing.T) {
-	type fields struct {
-		query Query
-	}
-	type args struct {
-		identifier string
-		target     *Target
-		kind       string
-	}
-	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    rest.Variation
-		wantErr bool
-	}{
 		{
-			name:   "data provider missing return error",
-			fields: fields{},
+			name: "fuzzy_in operator with an invalid distance",
+			fields: fields{
+				query: testRepo,
+			},
 			args: args{
-				identifier: simple,
+				clause: &rest.Clause{Attribute: "domain", Op: fuzzyInOperator, Values: []string{"one", "example"}},
 				target: &Target{
 					Identifier: harness,
+					Attributes: &map[string]interface{}{"domain": "example"},
 				},
-				kind: "boolean",
 			},
-			want:    rest.Variation{},
-			wantErr: true,
+			want: false,
 		},
 		{
-			name: "flag doesn't exist",
+			name: "in operator with a list attribute containing a value",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier: "some_test_flag",
-				kind:       "boolean",
+				clause: &rest.Clause{Attribute: "roles", Op: inOperator, Values: []string{"admin"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"roles": []string{"billing", "admin"}},
+				},
 			},
-			want:    rest.Variation{},
-			wantErr: true,
+			want: true,
 		},
 		{
-			name: "flag kind mismatch",
+			name: "in operator with a list attribute without any value",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier: simple,
-				kind:       "string",
+				clause: &rest.Clause{Attribute: "roles", Op: inOperator, Values: []string{"admin", "owner"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"roles": []interface{}{"billing", "support"}},
+				},
 			},
-			want:    rest.Variation{},
-			wantErr: true,
+			want: false,
 		},
 		{
-			name: "prereq flag simple should return true",
+			name: "in operator with an empty list attribute",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier: simpleWithPrereq,
-				kind:       "boolean",
+				clause: &rest.Clause{Attribute: "roles", Op: inOperator, Values: []string{"admin"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"roles": []string{}},
+				},
 			},
-			want: boolVariations[0],
+			want: false,
 		},
 		{
-			name: "error evaluating flag",
+			name: "equal operator with a list attribute",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier: notValidFlag,
-				kind:       "boolean",
+				clause: &rest.Clause{Attribute: "roles", Op: equalOperator, Values: []string{"Admin"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"roles": []string{"billing", "admin"}},
+				},
 			},
-			want:    rest.Variation{},
-			wantErr: true,
+			want: true,
 		},
 		{
-			name: "error evaluating prereq",
+			name: "contains operator with a list attribute",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier: prereqVarNotFound,
-				kind:       "boolean",
+				clause: &rest.Clause{Attribute: "roles", Op: containsOperator, Values: []string{"bill"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"roles": []string{"admin", "billing"}},
+				},
 			},
-			want:    boolVariations[1], // returns off variation
-			wantErr: false,
+			want: true,
 		},
 		{
-			name: "happy path",
+			name: "contains operator with an empty list attribute",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier: simple,
-				kind:       "boolean",
+				clause: &rest.Clause{Attribute: "roles", Op: containsOperator, Values: []string{"bill"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"roles": []string{}},
+				},
 			},
-			want: boolVariations[0],
+			want: false,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			e := Evaluator{
-				query:  tt.fields.query,
-				logger: logger.NewNoOpLogger(),
-			}
-			got, err := e.evaluate(tt.args.identifier, tt.args.target, tt.args.kind)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Evaluator.evaluate() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Evaluator.evaluate() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestEvaluator_BoolVariation(t *testing.T) {
-	type fields struct {
-		query Query
-	}
-	type args struct {
-		identifier   string
-		target       *Target
-		defaultValue bool
-	}
-	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   bool
-	}{
 		{
-			name: "bool flag not found return default value",
+			name: "not_equal operator with a list attribute containing the value",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier:   "flagNotFound1000",
-				target:       nil,
-				defaultValue: false,
+				clause: &rest.Clause{Attribute: "roles", Op: notEqualOperator, Values: []string{"Admin"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"roles": []string{"admin"}},
+				},
 			},
 			want: false,
 		},
 		{
-			name: "bool evaluation of flag 'simple' should return true",
+			name: "not_equal operator with a list attribute without the value",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier:   simple,
-				target:       nil,
-				defaultValue: false,
-			},
+				clause: &rest.Clause{Attribute: "roles", Op: notEqualOperator, Values: []string{"admin"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"roles": []string{"billing", "support"}},
+				},
+			},
 			want: true,
 		},
 		{
-			name: "bool evaluation of flag 'simple' with target 'harness' should return true",
+			name: "in operator normalizes values to the kind of int list elements",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier: simple,
+				clause: &rest.Clause{Attribute: "roles", Op: inOperator, Values: []string{"007"}},
 				target: &Target{
 					Identifier: harness,
+					Attributes: &map[string]interface{}{"roles": []int{7}},
 				},
-				defaultValue: false,
 			},
 			want: true,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			e := Evaluator{
-				query:  tt.fields.query,
-				logger: logger.NewNoOpLogger(),
-			}
-			if got := e.BoolVariation(tt.args.identifier, tt.args.target, tt.args.defaultValue); got != tt.want {
-				t.Errorf("Evaluator.BoolVariation() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestEvaluator_StringVariation(t *testing.T) {
-	type fields struct {
-		query Query
-	}
-	type args struct {
-		identifier   string
-		target       *Target
-		defaultValue string
-	}
-	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   string
-	}{
 		{
-			name: "string flag not found return default value",
+			name: "not_in operator normalizes values to the kind of int list elements",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier:   "flagNotFound1000",
-				target:       nil,
-				defaultValue: darktheme,
+				clause: &rest.Clause{Attribute: "roles", Op: notInOperator, Values: []string{"007"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"roles": []int{7}},
+				},
 			},
-			want: darktheme,
+			want: false,
 		},
 		{
-			name: "string evaluation of flag 'theme' should return lightheme",
+			name: "in operator normalizes values to the kind of mixed list elements",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier:   theme,
-				target:       nil,
-				defaultValue: darktheme,
+				clause: &rest.Clause{Attribute: "roles", Op: inOperator, Values: []string{"True"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"roles": []interface{}{"billing", true}},
+				},
 			},
-			want: lighttheme,
+			want: true,
 		},
 		{
-			name: "string evaluation of flag 'theme' with target 'harness' should return lighttheme",
+			name: "semver_gt operator compares versions numerically",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier: theme,
+				clause: &rest.Clause{Attribute: "version", Op: semverGtOperator, Values: []string{"2.9.0"}},
 				target: &Target{
 					Identifier: harness,
+					Attributes: &map[string]interface{}{"version": "2.14.0"},
 				},
-				defaultValue: darktheme,
 			},
-			want: lighttheme,
+			want: true,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			e := Evaluator{
-				query:  tt.fields.query,
-				logger: logger.NewNoOpLogger(),
-			}
-			if got := e.StringVariation(tt.args.identifier, tt.args.target, tt.args.defaultValue); got != tt.want {
-				t.Errorf("Evaluator.StringVariation() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestEvaluator_IntVariation(t *testing.T) {
-	type fields struct {
-		query Query
-	}
-	type args struct {
-		identifier   string
-		target       *Target
-		defaultValue int
-	}
-	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   int
-	}{
 		{
-			name: "int flag not found return default value",
+			name: "semver_lt operator compares versions numerically",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier:   "flagNotFound1000",
-				target:       nil,
-				defaultValue: 50,
+				clause: &rest.Clause{Attribute: "version", Op: semverLtOperator, Values: []string{"2.9.0"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"version": "2.14.0"},
+				},
 			},
-			want: 50,
+			want: false,
 		},
 		{
-			name: "int evaluation of flag 'size' should return medium",
+			name: "semver_equal operator with equal versions",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier:   size,
-				target:       nil,
-				defaultValue: 50,
+				clause: &rest.Clause{Attribute: "version", Op: semverEqualOperator, Values: []string{"2.14.0"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"version": "v2.14.0"},
+				},
 			},
-			want: 100,
+			want: true,
 		},
 		{
-			name: "not valid int evaluation of flag 'size' should return default value",
+			name: "semver_gt operator with an invalid version",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier:   invalidInt,
-				target:       nil,
-				defaultValue: 50,
+				clause: &rest.Clause{Attribute: "version", Op: semverGtOperator, Values: []string{"2.9.0"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"version": "latest"},
+				},
 			},
-			want: 50,
+			want: false,
 		},
 		{
-			name: "int evaluation of flag 'size' with target 'harness' should return medium",
+			name: "allow_deny operator with an allowed value",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier: size,
+				clause: &rest.Clause{Attribute: "role", Op: allowDenyOperator, Values: []string{"admin", "!suspended"}},
 				target: &Target{
 					Identifier: harness,
+					Attributes: &map[string]interface{}{"role": "admin"},
 				},
-				defaultValue: 50,
 			},
-			want: 100,
+			want: true,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			e := Evaluator{
-				query:  tt.fields.query,
-				logger: logger.NewNoOpLogger(),
-			}
-			if got := e.IntVariation(tt.args.identifier, tt.args.target, tt.args.defaultValue); got != tt.want {
-				t.Errorf("Evaluator.IntVariation() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestEvaluator_NumberVariation(t *testing.T) {
-	type fields struct {
-		query Query
-	}
-	type args struct {
-		identifier   string
-		target       *Target
-		defaultValue float64
-	}
-	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   float64
-	}{
 		{
-			name: "number flag not found return default value",
+			name: "allow_deny operator with a denied value",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier:   "flagNotFound1000",
-				target:       nil,
-				defaultValue: 50.0,
+				clause: &rest.Clause{Attribute: "role", Op: allowDenyOperator, Values: []string{"admin", "!suspended"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"role": "suspended"},
+				},
 			},
-			want: 50.0,
+			want: false,
 		},
 		{
-			name: "number evaluation of flag 'weight' should return heavyWeight",
+			name: "allow_deny operator with a value neither allowed nor denied",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier:   weight,
-				target:       nil,
-				defaultValue: 50.0,
+				clause: &rest.Clause{Attribute: "role", Op: allowDenyOperator, Values: []string{"admin", "!suspended"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"role": "billing"},
+				},
 			},
-			want: 100.0,
+			want: false,
 		},
 		{
-			name: "number evaluation of flag 'weight' should return default value",
+			name: "allow_deny operator with a list holding allowed and denied values",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier:   invalidNumber,
-				target:       nil,
-				defaultValue: 50.0,
+				clause: &rest.Clause{Attribute: "roles", Op: allowDenyOperator, Values: []string{"admin", "!suspended"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"roles": []string{"admin", "suspended"}},
+				},
 			},
-			want: 50.0,
+			want: false,
 		},
 		{
-			name: "number evaluation of flag 'weight' with target 'harness' should return heavyWeight",
+			name: "after operator with an rfc3339 attribute",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier: weight,
+				clause: &rest.Clause{Attribute: "signupDate", Op: afterOperator, Values: []string{"2024-01-01T00:00:00Z"}},
 				target: &Target{
 					Identifier: harness,
+					Attributes: &map[string]interface{}{"signupDate": "2024-02-15T08:30:00Z"},
 				},
-				defaultValue: 50.0,
 			},
-			want: 100.0,
+			want: true,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			e := Evaluator{
-				query:  tt.fields.query,
-				logger: logger.NewNoOpLogger(),
-			}
-			if got := e.NumberVariation(tt.args.identifier, tt.args.target, tt.args.defaultValue); got != tt.want {
-				t.Errorf("Evaluator.NumberVariation() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestEvaluator_JSONVariation(t *testing.T) {
-	defaultValue := map[string]interface{}{
-		"email": "harness@harness.io",
-	}
-	type fields struct {
-		query Query
-	}
-	type args struct {
-		identifier   string
-		target       *Target
-		defaultValue map[string]interface{}
-	}
-	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   map[string]interface{}
-	}{
 		{
-			name: "json flag not found return default value",
+			name: "before operator with an epoch attribute",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier:   "flagNotFound1000",
-				target:       nil,
-				defaultValue: defaultValue,
+				clause: &rest.Clause{Attribute: "signupDate", Op: beforeOperator, Values: []string{"2024-01-01T00:00:00Z"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"signupDate": 1672531200},
+				},
 			},
-			want: defaultValue,
+			want: true,
 		},
 		{
-			name: "json evaluation of flag 'org' should return json2Value",
+			name: "after operator with an unparseable attribute",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier:   org,
-				target:       nil,
-				defaultValue: defaultValue,
-			},
-			want: map[string]interface{}{
-				org: harness2,
+				clause: &rest.Clause{Attribute: "signupDate", Op: afterOperator, Values: []string{"2024-01-01T00:00:00Z"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"signupDate": "last year"},
+				},
 			},
+			want: false,
 		},
 		{
-			name: "json evaluation of flag 'org' should return default value",
+			name: "ip_in_cidr_any operator matching the second range",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier:   invalidJSON,
-				target:       nil,
-				defaultValue: defaultValue,
+				clause: &rest.Clause{Attribute: "ip", Op: ipInCIDRAnyOperator, Values: []string{"10.0.0.0/8", "192.168.0.0/16"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"ip": "192.168.4.20"},
+				},
 			},
-			want: defaultValue,
+			want: true,
 		},
 		{
-			name: "json evaluation of flag 'org' with target 'harness' should return json2",
+			name: "ip_in_cidr_any operator skips invalid ranges",
 			fields: fields{
 				query: testRepo,
 			},
 			args: args{
-				identifier: org,
+				clause: &rest.Clause{Attribute: "ip", Op: ipInCIDRAnyOperator, Values: []string{"office", "2001:db8::/32"}},
 				target: &Target{
 					Identifier: harness,
+					Attributes: &map[string]interface{}{"ip": "2001:db8::1"},
 				},
-				defaultValue: defaultValue,
-			},
-			want: map[string]interface{}{
-				org: harness2,
 			},
+			want: true,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			e := Evaluator{
-				query:  tt.fields.query,
-				logger: logger.NewNoOpLogger(),
-			}
+		{
+			name: "ip_in_cidr_any operator outside every range",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "ip", Op: ipInCIDRAnyOperator, Values: []string{"10.0.0.0/8", "192.168.0.0/16"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"ip": "8.8.8.8"},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "ip_in_cidr_any operator with an invalid ip",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "ip", Op: ipInCIDRAnyOperator, Values: []string{"10.0.0.0/8"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"ip": "10.0.0"},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "attr_equal operator with equal attributes",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "billingCountry", Op: attrEqualOperator, Values: []string{"shippingCountry"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"billingCountry": "ie", "shippingCountry": "IE"},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "attr_equal operator with unequal attributes",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "billingCountry", Op: attrEqualOperator, Values: []string{"shippingCountry"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"billingCountry": "IE", "shippingCountry": "US"},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "attr_equal_sensitive operator with attributes differing in case",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "billingCountry", Op: attrEqualSensitiveOperator, Values: []string{"shippingCountry"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"billingCountry": "ie", "shippingCountry": "IE"},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "attr_equal operator with the other attribute missing",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "billingCountry", Op: attrEqualOperator, Values: []string{"shippingCountry"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"billingCountry": "IE"},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "attr_equal operator with the clause attribute missing",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "billingCountry", Op: attrEqualOperator, Values: []string{"shippingCountry"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"shippingCountry": "IE"},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "in operator with a boolean attribute and mixed case clause values",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "enabled", Op: inOperator, Values: []string{"True"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"enabled": true},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "in operator with a boolean attribute and upper case clause values",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "enabled", Op: inOperator, Values: []string{"TRUE"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"enabled": false},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "in operator with an integer attribute and zero padded clause values",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "age", Op: inOperator, Values: []string{"006", "007"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"age": 7},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "not_in operator with an integer attribute and zero padded clause values",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "age", Op: notInOperator, Values: []string{"007"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"age": 7},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "equal_sensitive operator with a boolean attribute and mixed case clause value",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "enabled", Op: equalSensitiveOperator, Values: []string{"False"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"enabled": false},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "equal_sensitive operator with a string attribute stays case sensitive",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "enabled", Op: equalSensitiveOperator, Values: []string{"False"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"enabled": "false"},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "starts_with operator is case sensitive",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "email", Op: startsWithOperator, Values: []string{"john"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"email": "John@harness.io"},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "starts_with_i operator ignores case and whitespace",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "email", Op: startsWithIOperator, Values: []string{"john"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"email": " John@harness.io"},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "ends_with operator is case sensitive",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "email", Op: endsWithOperator, Values: []string{"@HARNESS.io"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"email": "john@harness.io"},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "ends_with_i operator ignores case",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "email", Op: endsWithIOperator, Values: []string{"@HARNESS.io"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"email": "john@harness.io"},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "contains operator is case sensitive",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "email", Op: containsOperator, Values: []string{"harness"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"email": "john@Harness.io"},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "contains_i operator ignores case",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "email", Op: containsIOperator, Values: []string{"harness"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"email": "john@Harness.io"},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "contains_i operator with a list attribute",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "email", Op: containsIOperator, Values: []string{"harness"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"email": []string{"jane@example.com", "john@Harness.io"}},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "contains_i operator without a match",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "email", Op: containsIOperator, Values: []string{"harness"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"email": "john@example.com"},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "semver_satisfies operator with or'd ranges",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "version", Op: semverSatisfiesOperator, Values: []string{">=1.2.0 <2.0.0 || 3.x"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"version": "3.1.0"},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "semver_satisfies operator with an invalid version",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				clause: &rest.Clause{Attribute: "version", Op: semverSatisfiesOperator, Values: []string{"3.x"}},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{"version": "latest"},
+				},
+			},
+			want: false,
+		},
+		{
+			name:   "negated in operator",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: identifier, Op: inOperator, Values: []string{harness}, Negate: true},
+				target: &Target{Identifier: harness},
+			},
+			want: false,
+		},
+		{
+			name:   "negated in operator without a match",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: identifier, Op: inOperator, Values: []string{beta}, Negate: true},
+				target: &Target{Identifier: harness},
+			},
+			want: true,
+		},
+		{
+			name:   "negated equal operator",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: identifier, Op: equalOperator, Values: []string{"HARNESS"}, Negate: true},
+				target: &Target{Identifier: harness},
+			},
+			want: false,
+		},
+		{
+			name:   "negated equal operator without a match",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: identifier, Op: equalOperator, Values: []string{beta}, Negate: true},
+				target: &Target{Identifier: harness},
+			},
+			want: true,
+		},
+		{
+			name:   "negated segmentMatch operator",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Op: segmentMatchOperator, Values: []string{beta}, Negate: true},
+				target: &Target{Identifier: harness},
+			},
+			want: false,
+		},
+		{
+			name:   "negated segmentMatch operator outside the segment",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Op: segmentMatchOperator, Values: []string{beta}, Negate: true},
+				target: &Target{Identifier: "outsider"},
+			},
+			want: true,
+		},
+		{
+			name:   "negated clause on a missing attribute doesn't match",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "email", Op: equalOperator, Values: []string{harness}, Negate: true},
+				target: &Target{Identifier: harness},
+			},
+			want: false,
+		},
+		{
+			name:   "negated clause with an unknown operator doesn't match",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: identifier, Op: "greaterthan", Values: []string{harness}, Negate: true},
+				target: &Target{Identifier: harness},
+			},
+			want: false,
+		},
+		{
+			name:   "sampled operator samples every target at 100 percent",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: identifier, Op: sampledOperator, Values: []string{"100", "telemetry"}},
+				target: &Target{Identifier: harness},
+			},
+			want: true,
+		},
+		{
+			name:   "sampled operator samples no target at 0 percent",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: identifier, Op: sampledOperator, Values: []string{"0", "telemetry"}},
+				target: &Target{Identifier: harness},
+			},
+			want: false,
+		},
+		{
+			name:   "segment_and_attr operator in the segment with a matching attribute",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "country", Op: segmentAndAttrOperator, Values: []string{beta, inOperator, "US", "CA"}},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"country": "CA"}},
+			},
+			want: true,
+		},
+		{
+			name:   "segment_and_attr operator in the segment with another attribute",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "country", Op: segmentAndAttrOperator, Values: []string{beta, inOperator, "US", "CA"}},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"country": "IE"}},
+			},
+			want: false,
+		},
+		{
+			name:   "segment_and_attr operator outside the segment with a matching attribute",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "country", Op: segmentAndAttrOperator, Values: []string{beta, inOperator, "US", "CA"}},
+				target: &Target{Identifier: "outsider", Attributes: &map[string]interface{}{"country": "US"}},
+			},
+			want: false,
+		},
+		{
+			name:   "segment_and_attr operator without a condition",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "country", Op: segmentAndAttrOperator, Values: []string{beta}},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"country": "US"}},
+			},
+			want: false,
+		},
+		{
+			name:   "weighted_bucket operator matching the assigned bucket",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: identifier, Op: weightedBucketOperator, Values: []string{"arm", "arm:1", "none:0"}},
+				target: &Target{Identifier: harness},
+			},
+			want: true,
+		},
+		{
+			name:   "weighted_bucket operator with another assigned bucket",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: identifier, Op: weightedBucketOperator, Values: []string{"other", "arm:1", "other:0"}},
+				target: &Target{Identifier: harness},
+			},
+			want: false,
+		},
+		{
+			name:   "check gt operator compares float attributes numerically",
+			fields: fields{},
+			args: args{
+				clause: &rest.Clause{
+					Attribute: "score",
+					Op:        gtOperator,
+					Values:    []string{"10.2"},
+				},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"score": 10.25,
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name:   "check gt operator doesn't compare numbers lexicographically",
+			fields: fields{},
+			args: args{
+				clause: &rest.Clause{
+					Attribute: "score",
+					Op:        gtOperator,
+					Values:    []string{"10"},
+				},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"score": 9.5,
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name:   "check gte operator compares an int attribute with a float value",
+			fields: fields{},
+			args: args{
+				clause: &rest.Clause{
+					Attribute: "age",
+					Op:        gteOperator,
+					Values:    []string{"18.0"},
+				},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"age": 18,
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name:   "check lt operator compares a float attribute with an int value",
+			fields: fields{},
+			args: args{
+				clause: &rest.Clause{
+					Attribute: "version",
+					Op:        ltOperator,
+					Values:    []string{"5"},
+				},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"version": 4.9,
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name:   "check lte operator compares a numeric string attribute numerically",
+			fields: fields{},
+			args: args{
+				clause: &rest.Clause{
+					Attribute: "version",
+					Op:        lteOperator,
+					Values:    []string{"5"},
+				},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"version": "10",
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name:   "check gte operator compares a bool attribute as a string",
+			fields: fields{},
+			args: args{
+				clause: &rest.Clause{
+					Attribute: "beta",
+					Op:        gteOperator,
+					Values:    []string{"true"},
+				},
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"beta": true,
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name:   "not_match operator with a matching value",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "email", Op: notMatchOperator, Values: []string{"@harness\\.io$"}},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"email": "john@harness.io"}},
+			},
+			want: false,
+		},
+		{
+			name:   "not_match operator with another value",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "email", Op: notMatchOperator, Values: []string{"@harness\\.io$"}},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"email": "john@example.com"}},
+			},
+			want: true,
+		},
+		{
+			name:   "not_match operator with an invalid pattern doesn't match",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "email", Op: notMatchOperator, Values: []string{"[harness"}},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"email": "john@example.com"}},
+			},
+			want: false,
+		},
+		{
+			name:   "not_contains operator with a list attribute",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "roles", Op: notContainsOperator, Values: []string{"admin"}},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"roles": []string{"viewer", "superadmin"}}},
+			},
+			want: false,
+		},
+		{
+			name:   "not_contains operator without the value",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "email", Op: notContainsOperator, Values: []string{"harness"}},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"email": "john@example.com"}},
+			},
+			want: true,
+		},
+		{
+			name:   "negated not_contains operator",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "email", Op: notContainsOperator, Values: []string{"harness"}, Negate: true},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"email": "john@harness.io"}},
+			},
+			want: true,
+		},
+		{
+			name:   "ip_in_cidr operator with an address in a block",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "ip", Op: ipInCIDROperator, Values: []string{"10.0.0.0/8", "172.16.0.0/12"}},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"ip": "172.20.1.9"}},
+			},
+			want: true,
+		},
+		{
+			name:   "ip_in_cidr operator with an address outside the blocks",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "ip", Op: ipInCIDROperator, Values: []string{"10.0.0.0/8"}},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"ip": "8.8.8.8"}},
+			},
+			want: false,
+		},
+		{
+			name:   "equal operator with a float32 attribute",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "ratio", Op: equalOperator, Values: []string{"0.25"}},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"ratio": float32(0.25)}},
+			},
+			want: true,
+		},
+		{
+			name:   "gt operator with a uint64 attribute",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "seats", Op: gtOperator, Values: []string{"100"}},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"seats": uint64(250)}},
+			},
+			want: true,
+		},
+		{
+			name:   "before operator with a time attribute",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "signupDate", Op: beforeOperator, Values: []string{"2024-01-01T00:00:00Z"}},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"signupDate": time.Date(2023, time.March, 4, 0, 0, 0, 0, time.UTC)}},
+			},
+			want: true,
+		},
+		{
+			name:   "after operator with a time pointer attribute",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "signupDate", Op: afterOperator, Values: []string{"2024-01-01T00:00:00Z"}},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"signupDate": &formatTime}},
+			},
+			want: false,
+		},
+		{
+			name:   "equal_sensitive operator with a list attribute",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "roles", Op: equalSensitiveOperator, Values: []string{"beta"}},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"roles": []string{"admin", "beta"}}},
+			},
+			want: true,
+		},
+		{
+			name:   "equal_sensitive operator with a list attribute differing in case",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "roles", Op: equalSensitiveOperator, Values: []string{"Beta"}},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"roles": []string{"admin", "beta"}}},
+			},
+			want: false,
+		},
+		{
+			name:   "starts_with operator with a list attribute",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "emails", Op: startsWithOperator, Values: []string{"john"}},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"emails": []interface{}{"jane@harness.io", "john@harness.io"}}},
+			},
+			want: true,
+		},
+		{
+			name:   "ends_with operator with a list attribute without a match",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "emails", Op: endsWithOperator, Values: []string{"@example.com"}},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"emails": []string{"jane@harness.io", "john@harness.io"}}},
+			},
+			want: false,
+		},
+		{
+			name:   "match operator with a list attribute",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "roles", Op: matchOperator, Values: []string{"^beta-[0-9]+$"}},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"roles": []string{"admin", "beta-2"}}},
+			},
+			want: true,
+		},
+		{
+			name:   "match operator with an empty list attribute",
+			fields: fields{query: testRepo},
+			args: args{
+				clause: &rest.Clause{Attribute: "roles", Op: matchOperator, Values: []string{".*"}},
+				target: &Target{Identifier: harness, Attributes: &map[string]interface{}{"roles": []string{}}},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Evaluator{
+				query:  tt.fields.query,
+				logger: logger.NewNoOpLogger(),
+			}
+			state := e.newEvaluation(context.Background())
+			if got := state.evaluateClause(tt.args.clause, tt.args.target); got != tt.want {
+				t.Errorf("Evaluator.evaluateClause() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluator_evaluateRules(t *testing.T) {
+	type fields struct {
+		query Query
+	}
+	type args struct {
+		servingRules []rest.ServingRule
+		target       *Target
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   string
+	}{
+		{
+			name: "when rules is nil return \"\"",
+			args: args{
+				servingRules: nil,
+			},
+			want: "",
+		},
+		{
+			name: "when target is nil return \"\"",
+			args: args{
+				target: nil,
+			},
+			want: "",
+		},
+		{
+			name: "evaluate rule",
+			args: args{
+				// both rule clauses are true so it will serve false and true
+				// priority is on second one and should return true
+				servingRules: []rest.ServingRule{
+					{
+						Priority: 2,
+						Clauses: []rest.Clause{
+							{
+								Attribute: identifier,
+								Op:        equalOperator,
+								Values:    []string{harness},
+							},
+						},
+						Serve: rest.Serve{
+							Variation: &identifierFalse,
+						},
+					},
+					{
+						Priority: 1,
+						Clauses: []rest.Clause{
+							{
+								Attribute: identifier,
+								Op:        equalOperator,
+								Values:    []string{harness},
+							},
+						},
+						Serve: rest.Serve{
+							Variation: &identifierTrue,
+						},
+					},
+				},
+				target: &Target{
+					Identifier: harness,
+				},
+			},
+			want: identifierTrue,
+		},
+		{
+			name: "evaluate rule continue in for loop should occur",
+			args: args{
+				// both rule clauses are true so it will serve false and true
+				// priority is on second one and should return true
+				servingRules: []rest.ServingRule{
+					{
+						Priority: 1,
+						Clauses: []rest.Clause{
+							{
+								Attribute: identifier,
+								Op:        equalOperator,
+								Values:    []string{"harnesss"},
+							},
+						},
+						Serve: rest.Serve{
+							Variation: &identifierTrue,
+						},
+					},
+					{
+						Priority: 2,
+						Clauses: []rest.Clause{
+							{
+								Attribute: identifier,
+								Op:        equalOperator,
+								Values:    []string{harness},
+							},
+						},
+						Serve: rest.Serve{
+							Variation: &identifierTrue,
+						},
+					},
+				},
+				target: &Target{
+					Identifier: harness,
+				},
+			},
+			want: identifierTrue,
+		},
+		{
+			name: "evaluate rule by distribution",
+			args: args{
+				servingRules: []rest.ServingRule{
+					{
+						Priority: 1,
+						Clauses: []rest.Clause{
+							{
+								Attribute: identifier,
+								Op:        equalOperator,
+								Values:    []string{harness},
+							},
+						},
+						Serve: rest.Serve{
+							Distribution: &rest.Distribution{
+								BucketBy: identifier,
+								Variations: []rest.WeightedVariation{
+									{Variation: identifierTrue, Weight: 5},
+									{Variation: identifierFalse, Weight: 95},
+								},
+							},
+						},
+					},
+				},
+				target: &Target{
+					Identifier: harness,
+				},
+			},
+			want: identifierFalse,
+		},
+		{
+			name: "evaluate rule (target is nil) return variation identifier empty",
+			args: args{
+				servingRules: []rest.ServingRule{
+					{
+						Priority: 1,
+						Clauses: []rest.Clause{
+							{
+								Attribute: identifier,
+								Op:        equalOperator,
+								Values:    []string{harness},
+							},
+						},
+						Serve: rest.Serve{
+							Variation: &identifierFalse,
+						},
+					},
+				},
+				target: nil,
+			},
+			want: "",
+		},
+		{
+			name: "when rules is empty return \"\"",
+			args: args{
+				servingRules: []rest.ServingRule{},
+				target: &Target{
+					Identifier: harness,
+				},
+			},
+			want: "",
+		},
+		{
+			name: "evaluate rule matching attribute inherited from parent target",
+			args: args{
+				servingRules: []rest.ServingRule{
+					{
+						Priority: 1,
+						Clauses: []rest.Clause{
+							{
+								Attribute: "plan",
+								Op:        equalOperator,
+								Values:    []string{"enterprise"},
+							},
+						},
+						Serve: rest.Serve{
+							Variation: &identifierTrue,
+						},
+					},
+				},
+				target: &Target{
+					Identifier: harness,
+					Parent: &Target{
+						Identifier: org,
+						Attributes: &map[string]interface{}{
+							"plan": "enterprise",
+						},
+					},
+				},
+			},
+			want: identifierTrue,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Evaluator{
+				query:  tt.fields.query,
+				logger: logger.NewNoOpLogger(),
+			}
+			state := e.newEvaluation(context.Background())
+			if got := state.evaluateRules("", tt.args.servingRules, tt.args.target); got != tt.want {
+				t.Errorf("Evaluator.evaluateRules() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluator_evaluateVariationMap(t *testing.T) {
+	type fields struct {
+		query Query
+	}
+	type args struct {
+		variationsMap []rest.VariationMap
+		target        *Target
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   string
+	}{
+		{
+			name: "when variations map is nil return \"\"",
+			args: args{
+				variationsMap: nil,
+			},
+			want: "",
+		},
+		{
+			name: "when target is nil return \"\"",
+			args: args{
+				target: nil,
+			},
+			want: "",
+		},
+		{
+			name: "when target identifier in targets serve true",
+			args: args{
+				variationsMap: []rest.VariationMap{
+					{
+						Variation: identifierTrue,
+						Targets: &[]rest.TargetMap{
+							{
+								Identifier: &targetIdentifier,
+							},
+						},
+					},
+				},
+				target: &Target{
+					Identifier: targetIdentifier,
+				},
+			},
+			want: identifierTrue,
+		},
+		{
+			name: "when all targets in all variation maps is nil then serve \"\"",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				variationsMap: []rest.VariationMap{
+					{
+						Variation:      identifierTrue,
+						TargetSegments: &[]string{beta},
+					},
+				},
+				target: &Target{
+					Identifier: targetIdentifier,
+				},
+			},
+			want: identifierTrue,
+		},
+		{
+			name: "when all targets and segments in all variation maps is nil then serve \"\"",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				target: &Target{
+					Identifier: targetIdentifier,
+				},
+			},
+			want: "",
+		},
+		{
+			name: "target identifier in segments serve true",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				variationsMap: []rest.VariationMap{
+					{
+						Variation:      identifierTrue,
+						TargetSegments: &[]string{beta},
+					},
+				},
+				target: &Target{
+					Identifier: targetIdentifier,
+				},
+			},
+			want: identifierTrue,
+		},
+		{
+			name: "when variations map is empty return \"\"",
+			args: args{
+				variationsMap: []rest.VariationMap{},
+				target: &Target{
+					Identifier: targetIdentifier,
+				},
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Evaluator{
+				query:  tt.fields.query,
+				logger: logger.NewNoOpLogger(),
+			}
+			state := e.newEvaluation(context.Background())
+			if got := state.evaluateVariationMap(tt.args.variationsMap, tt.args.target); got != tt.want {
+				t.Errorf("Evaluator.evaluateVariationMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluator_evaluateFlag(t *testing.T) {
+	type fields struct {
+		query Query
+	}
+	type args struct {
+		fc     rest.FeatureConfig
+		target *Target
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    rest.Variation
+		wantErr bool
+	}{
+		{
+			name: "evaluation of flag when is off state serve off variation",
+			args: args{
+				fc: rest.FeatureConfig{
+					OffVariation: offVariation,
+					State:        rest.FeatureStateOff,
+					Variations:   boolVariations,
+				},
+			},
+			want:    boolVariations[1],
+			wantErr: false,
+		},
+		{
+			name: "evaluation with target when flag is off serve off variation",
+			args: args{
+				fc: rest.FeatureConfig{
+					OffVariation: offVariation,
+					State:        rest.FeatureStateOff,
+					Variations:   boolVariations,
+				},
+				target: &Target{
+					Identifier: harness,
+				},
+			},
+			want:    boolVariations[1],
+			wantErr: false,
+		},
+		{
+			name: "evaluate flag should return default serve variation",
+			args: args{
+				fc: rest.FeatureConfig{
+					State:      rest.FeatureStateOn,
+					Variations: boolVariations,
+					DefaultServe: rest.Serve{
+						Variation: &boolVariations[0].Value,
+					},
+				},
+				target: &Target{
+					Identifier: harness,
+				},
+			},
+			want:    boolVariations[0],
+			wantErr: false,
+		},
+		{
+			name: "evaluate flag should return default serve distribution",
+			args: args{
+				fc: rest.FeatureConfig{
+					State:      rest.FeatureStateOn,
+					Variations: boolVariations,
+					DefaultServe: rest.Serve{
+						Distribution: &rest.Distribution{
+							Variations: []rest.WeightedVariation{
+								{
+									Variation: identifierTrue,
+									Weight:    5,
+								},
+								{
+									Variation: identifierFalse,
+									Weight:    95,
+								},
+							},
+						},
+					},
+				},
+				target: &Target{
+					Identifier: harness,
+				},
+			},
+			want:    boolVariations[1],
+			wantErr: false,
+		},
+		{
+			name: "evaluate flag should return rule serve",
+			args: args{
+				fc: rest.FeatureConfig{
+					State:      rest.FeatureStateOn,
+					Variations: boolVariations,
+					Rules: &[]rest.ServingRule{
+						{
+							Clauses: []rest.Clause{
+								{
+									Attribute: identifier,
+									Op:        equalOperator,
+									Values:    []string{harness},
+								},
+							},
+							Serve: rest.Serve{
+								Variation: &boolVariations[0].Value,
+							},
+						},
+					},
+				},
+				target: &Target{
+					Identifier: harness,
+				},
+			},
+			want:    boolVariations[0],
+			wantErr: false,
+		},
+		{
+			name: "evaluate flag using variationMap and target should return 'true'",
+			args: args{
+				fc: rest.FeatureConfig{
+					State:      rest.FeatureStateOn,
+					Variations: boolVariations,
+					VariationToTargetMap: &[]rest.VariationMap{
+						{
+							Variation: identifierTrue,
+							Targets: &[]rest.TargetMap{
+								{
+									Identifier: &targetIdentifier,
+								},
+							},
+						},
+					},
+				},
+				target: &Target{
+					Identifier: targetIdentifier,
+				},
+			},
+			want:    boolVariations[0],
+			wantErr: false,
+		},
+		{
+			name: "evaluate flag variation returns an error",
+			args: args{
+				fc: rest.FeatureConfig{
+					State: rest.FeatureStateOn,
+				},
+				target: &Target{
+					Identifier: targetIdentifier,
+				},
+			},
+			want:    rest.Variation{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Evaluator{
+				query:  tt.fields.query,
+				logger: logger.NewNoOpLogger(),
+			}
+			got, err := e.newEvaluation(context.Background()).evaluateFlag(tt.args.fc, tt.args.target)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Evaluator.evaluateFlag() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluator.evaluateFlag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluator_evaluateFlagFirstVariationFallback(t *testing.T) {
+	fc := rest.FeatureConfig{
+		Feature:    theme,
+		State:      rest.FeatureStateOn,
+		Variations: stringVariations,
+	}
+	tests := []struct {
+		name    string
+		options []EvaluatorOption
+		want    rest.Variation
+		wantErr bool
+	}{
+		{
+			name:    "empty default serve returns an error by default",
+			want:    rest.Variation{},
+			wantErr: true,
+		},
+		{
+			name:    "empty default serve serves the first variation when fallback is enabled",
+			options: []EvaluatorOption{WithFirstVariationFallback(true)},
+			want:    stringVariations[0],
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger(), tt.options...)
+			got, err := e.newEvaluation(context.Background()).evaluateFlag(fc, &Target{Identifier: harness})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Evaluator.evaluateFlag() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluator.evaluateFlag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluator_isTargetIncludedOrExcludedInSegment(t *testing.T) {
+	type fields struct {
+		query Query
+	}
+	type args struct {
+		segmentList []string
+		target      *Target
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   bool
+	}{
+		{
+			name: "segment list is empty return false",
+			args: args{
+				segmentList: nil,
+			},
+			want: false,
+		},
+		{
+			name: "segment not found should return false",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				segmentList: []string{"segmentNotFound1000"},
+			},
+			want: false,
+		},
+		{
+			name: "segment in excluded should return false",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				segmentList: []string{excluded},
+				target: &Target{
+					Identifier: harness,
+				},
+			},
+			want: false,
+		},
+		{
+			name: "segment with target identifier should return true",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				segmentList: []string{beta},
+				target: &Target{
+					Identifier: harness,
+				},
+			},
+			want: true,
+		},
+		{
+			name: "evaluate rule in segment rules should return true",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				segmentList: []string{alpha},
+				target: &Target{
+					Identifier: harness,
+				},
+			},
+			want: true,
+		},
+		{
+			name: "segment rule clause with false result should return false",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				segmentList: []string{alpha},
+				target: &Target{
+					Identifier: "no_identifier",
+				},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Evaluator{
+				query:  tt.fields.query,
+				logger: logger.NewNoOpLogger(),
+			}
+			state := e.newEvaluation(context.Background())
+			if got := state.isTargetIncludedOrExcludedInSegment(tt.args.segmentList, tt.args.target); got != tt.want {
+				t.Errorf("Evaluator.isTargetIncludedOrExcludedInSegment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluator_isTargetIncludedOrExcludedInSegmentRuleBlocks(t *testing.T) {
+	segment := rest.Segment{
+		Identifier: "rule-blocks",
+		Rules: &[]rest.Clause{
+			{Id: "staff", Attribute: identifier, Op: equalOperator, Values: []string{harness}},
+			{Id: "staff", Attribute: "email", Op: endsWithOperator, Values: []string{"@harness.io"}},
+			{Id: "beta", Attribute: "country", Op: equalOperator, Values: []string{"IE"}},
+			{Id: "beta", Attribute: "plan", Op: equalOperator, Values: []string{"enterprise"}},
+		},
+	}
+	repo := NewTestRepository(nil, map[string]rest.Segment{segment.Identifier: segment})
+	tests := []struct {
+		name       string
+		attributes map[string]interface{}
+		want       bool
+	}{
+		{
+			name:       "only the second rule block matches",
+			attributes: map[string]interface{}{"email": "john@example.com", "country": "IE", "plan": "enterprise"},
+			want:       true,
+		},
+		{
+			name:       "no rule block matches all of its clauses",
+			attributes: map[string]interface{}{"email": "john@example.com", "country": "IE", "plan": "free"},
+			want:       false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Evaluator{
+				query:  repo,
+				logger: logger.NewNoOpLogger(),
+			}
+			target := &Target{Identifier: "john", Attributes: &tt.attributes}
+			state := e.newEvaluation(context.Background())
+			if got := state.isTargetIncludedOrExcludedInSegment([]string{segment.Identifier}, target); got != tt.want {
+				t.Errorf("Evaluator.isTargetIncludedOrExcludedInSegment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// cyclicPrerequisiteFlag returns a boolean flag serving true which requires prerequisite to be true
+func cyclicPrerequisiteFlag(feature, prerequisite string) rest.FeatureConfig {
+	return rest.FeatureConfig{
+		Feature:      feature,
+		State:        rest.FeatureStateOn,
+		Kind:         "boolean",
+		OffVariation: identifierFalse,
+		DefaultServe: rest.Serve{Variation: &identifierTrue},
+		Variations:   boolVariations,
+		Prerequisites: &[]rest.Prerequisite{
+			{Feature: prerequisite, Variations: []string{identifierTrue}},
+		},
+	}
+}
+
+func TestEvaluator_checkPreRequisite(t *testing.T) {
+	type fields struct {
+		query Query
+	}
+	type args struct {
+		parent *rest.FeatureConfig
+		target *Target
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "data provider missing, returns error",
+			args: args{
+				parent: &rest.FeatureConfig{},
+			},
+			want:    true,
+			wantErr: true,
+		},
+		{
+			name: "no prerequities should return true",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				parent: &rest.FeatureConfig{},
+			},
+			want: true,
+		},
+		{
+			name: "prereq simple should return true",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				parent: &rest.FeatureConfig{
+					State: rest.FeatureStateOn,
+					Prerequisites: &[]rest.Prerequisite{
+						{
+							Feature:    simple,
+							Variations: []string{identifierTrue, identifierFalse},
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "prereq flag doesn't exists it should return false",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				parent: &rest.FeatureConfig{
+					State: rest.FeatureStateOn,
+					Prerequisites: &[]rest.Prerequisite{
+						{
+							Feature:    "prereq not found",
+							Variations: []string{identifierTrue, identifierFalse},
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "prereq cycle should return false",
+			fields: fields{
+				query: NewTestRepository(
+					map[string]rest.FeatureConfig{
+						"cycleA": cyclicPrerequisiteFlag("cycleA", "cycleB"),
+						"cycleB": cyclicPrerequisiteFlag("cycleB", "cycleA"),
+					},
+					nil,
+				),
+			},
+			args: args{
+				parent: func() *rest.FeatureConfig {
+					fc := cyclicPrerequisiteFlag("cycleA", "cycleB")
+					return &fc
+				}(),
+				target: &Target{Identifier: harness},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Evaluator{
+				query:  tt.fields.query,
+				logger: logger.NewNoOpLogger(),
+			}
+			check, err := e.newEvaluation(context.Background()).checkPreRequisite(tt.args.parent, tt.args.target)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Evaluator.checkPreRequisite() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got := check.satisfied; got != tt.want {
+				t.Errorf("Evaluator.checkPreRequisite() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluator_evaluate(t *testing.T) {
+	type fields struct {
+		query Query
+	}
+	type args struct {
+		identifier string
+		target     *Target
+		kind       string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    rest.Variation
+		wantErr bool
+	}{
+		{
+			name:   "data provider missing return error",
+			fields: fields{},
+			args: args{
+				identifier: simple,
+				target: &Target{
+					Identifier: harness,
+				},
+				kind: "boolean",
+			},
+			want:    rest.Variation{},
+			wantErr: true,
+		},
+		{
+			name: "flag doesn't exist",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier: "some_test_flag",
+				kind:       "boolean",
+			},
+			want:    rest.Variation{},
+			wantErr: true,
+		},
+		{
+			name: "flag kind mismatch",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier: simple,
+				kind:       "string",
+			},
+			want:    rest.Variation{},
+			wantErr: true,
+		},
+		{
+			name: "prereq flag simple should return true",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier: simpleWithPrereq,
+				kind:       "boolean",
+			},
+			want: boolVariations[0],
+		},
+		{
+			name: "error evaluating flag",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier: notValidFlag,
+				kind:       "boolean",
+			},
+			want:    rest.Variation{},
+			wantErr: true,
+		},
+		{
+			name: "error evaluating prereq",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier: prereqVarNotFound,
+				kind:       "boolean",
+			},
+			want:    boolVariations[1], // returns off variation
+			wantErr: false,
+		},
+		{
+			name: "happy path",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier: simple,
+				kind:       "boolean",
+			},
+			want: boolVariations[0],
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Evaluator{
+				query:  tt.fields.query,
+				logger: logger.NewNoOpLogger(),
+			}
+			got, err := e.newEvaluation(context.Background()).evaluate(tt.args.identifier, tt.args.target, tt.args.kind)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Evaluator.evaluate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluator.evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluator_BoolVariation(t *testing.T) {
+	type fields struct {
+		query Query
+	}
+	type args struct {
+		identifier   string
+		target       *Target
+		defaultValue bool
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   bool
+	}{
+		{
+			name: "bool flag not found return default value",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier:   "flagNotFound1000",
+				target:       nil,
+				defaultValue: false,
+			},
+			want: false,
+		},
+		{
+			name: "bool evaluation of flag 'simple' should return true",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier:   simple,
+				target:       nil,
+				defaultValue: false,
+			},
+			want: true,
+		},
+		{
+			name: "bool evaluation of flag 'simple' with target 'harness' should return true",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier: simple,
+				target: &Target{
+					Identifier: harness,
+				},
+				defaultValue: false,
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Evaluator{
+				query:  tt.fields.query,
+				logger: logger.NewNoOpLogger(),
+			}
+			if got := e.BoolVariation(tt.args.identifier, tt.args.target, tt.args.defaultValue); got != tt.want {
+				t.Errorf("Evaluator.BoolVariation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluator_StringVariation(t *testing.T) {
+	type fields struct {
+		query Query
+	}
+	type args struct {
+		identifier   string
+		target       *Target
+		defaultValue string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   string
+	}{
+		{
+			name: "string flag not found return default value",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier:   "flagNotFound1000",
+				target:       nil,
+				defaultValue: darktheme,
+			},
+			want: darktheme,
+		},
+		{
+			name: "string evaluation of flag 'theme' should return lightheme",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier:   theme,
+				target:       nil,
+				defaultValue: darktheme,
+			},
+			want: lighttheme,
+		},
+		{
+			name: "string evaluation of flag 'theme' with target 'harness' should return lighttheme",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier: theme,
+				target: &Target{
+					Identifier: harness,
+				},
+				defaultValue: darktheme,
+			},
+			want: lighttheme,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Evaluator{
+				query:  tt.fields.query,
+				logger: logger.NewNoOpLogger(),
+			}
+			if got := e.StringVariation(tt.args.identifier, tt.args.target, tt.args.defaultValue); got != tt.want {
+				t.Errorf("Evaluator.StringVariation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluator_IntVariation(t *testing.T) {
+	type fields struct {
+		query Query
+	}
+	type args struct {
+		identifier   string
+		target       *Target
+		defaultValue int
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   int
+	}{
+		{
+			name: "int flag not found return default value",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier:   "flagNotFound1000",
+				target:       nil,
+				defaultValue: 50,
+			},
+			want: 50,
+		},
+		{
+			name: "int evaluation of flag 'size' should return medium",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier:   size,
+				target:       nil,
+				defaultValue: 50,
+			},
+			want: 100,
+		},
+		{
+			name: "not valid int evaluation of flag 'size' should return default value",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier:   invalidInt,
+				target:       nil,
+				defaultValue: 50,
+			},
+			want: 50,
+		},
+		{
+			name: "int evaluation of flag 'size' with target 'harness' should return medium",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier: size,
+				target: &Target{
+					Identifier: harness,
+				},
+				defaultValue: 50,
+			},
+			want: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Evaluator{
+				query:  tt.fields.query,
+				logger: logger.NewNoOpLogger(),
+			}
+			if got := e.IntVariation(tt.args.identifier, tt.args.target, tt.args.defaultValue); got != tt.want {
+				t.Errorf("Evaluator.IntVariation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluator_NumberVariation(t *testing.T) {
+	type fields struct {
+		query Query
+	}
+	type args struct {
+		identifier   string
+		target       *Target
+		defaultValue float64
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   float64
+	}{
+		{
+			name: "number flag not found return default value",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier:   "flagNotFound1000",
+				target:       nil,
+				defaultValue: 50.0,
+			},
+			want: 50.0,
+		},
+		{
+			name: "number evaluation of flag 'weight' should return heavyWeight",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier:   weight,
+				target:       nil,
+				defaultValue: 50.0,
+			},
+			want: 100.0,
+		},
+		{
+			name: "number evaluation of flag 'weight' should return default value",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier:   invalidNumber,
+				target:       nil,
+				defaultValue: 50.0,
+			},
+			want: 50.0,
+		},
+		{
+			name: "number evaluation of flag 'weight' with target 'harness' should return heavyWeight",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier: weight,
+				target: &Target{
+					Identifier: harness,
+				},
+				defaultValue: 50.0,
+			},
+			want: 100.0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Evaluator{
+				query:  tt.fields.query,
+				logger: logger.NewNoOpLogger(),
+			}
+			if got := e.NumberVariation(tt.args.identifier, tt.args.target, tt.args.defaultValue); got != tt.want {
+				t.Errorf("Evaluator.NumberVariation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluator_JSONVariation(t *testing.T) {
+	defaultValue := map[string]interface{}{
+		"email": "harness@harness.io",
+	}
+	type fields struct {
+		query Query
+	}
+	type args struct {
+		identifier   string
+		target       *Target
+		defaultValue map[string]interface{}
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   map[string]interface{}
+	}{
+		{
+			name: "json flag not found return default value",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier:   "flagNotFound1000",
+				target:       nil,
+				defaultValue: defaultValue,
+			},
+			want: defaultValue,
+		},
+		{
+			name: "json evaluation of flag 'org' should return json2Value",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier:   org,
+				target:       nil,
+				defaultValue: defaultValue,
+			},
+			want: map[string]interface{}{
+				org: harness2,
+			},
+		},
+		{
+			name: "json evaluation of flag 'org' should return default value",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier:   invalidJSON,
+				target:       nil,
+				defaultValue: defaultValue,
+			},
+			want: defaultValue,
+		},
+		{
+			name: "json evaluation of flag 'org' with target 'harness' should return json2",
+			fields: fields{
+				query: testRepo,
+			},
+			args: args{
+				identifier: org,
+				target: &Target{
+					Identifier: harness,
+				},
+				defaultValue: defaultValue,
+			},
+			want: map[string]interface{}{
+				org: harness2,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Evaluator{
+				query:  tt.fields.query,
+				logger: logger.NewNoOpLogger(),
+			}
 			if got := e.JSONVariation(tt.args.identifier, tt.args.target, tt.args.defaultValue); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Evaluator.JSONVariation() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestEvaluator_EvaluatePath(t *testing.T) {
+	blue := "blue"
+	red := "red"
+	colorVariations := []rest.Variation{
+		{Identifier: blue, Value: blue},
+		{Identifier: red, Value: red},
+	}
+	repo := NewTestRepository(
+		map[string]rest.FeatureConfig{
+			theme: {
+				Feature: theme,
+				State:   rest.FeatureStateOn,
+				Kind:    "string",
+				VariationToTargetMap: &[]rest.VariationMap{
+					{
+						Variation: red,
+						Targets: &[]rest.TargetMap{
+							{Identifier: &harness1},
+						},
+					},
+				},
+				Rules: &[]rest.ServingRule{
+					{
+						Priority: 1,
+						Clauses: []rest.Clause{
+							{Attribute: "country", Op: equalOperator, Values: []string{"CA"}},
+						},
+						Serve: rest.Serve{Variation: &red},
+					},
+					{
+						Priority: 2,
+						Clauses: []rest.Clause{
+							{Attribute: "country", Op: equalOperator, Values: []string{"US"}},
+						},
+						Serve: rest.Serve{Variation: &blue},
+					},
+				},
+				DefaultServe: rest.Serve{Variation: &red},
+				Variations:   colorVariations,
+			},
+			simple: {
+				Feature:      simple,
+				State:        rest.FeatureStateOff,
+				Kind:         "boolean",
+				OffVariation: identifierFalse,
+				Variations:   boolVariations,
+			},
+		},
+		nil,
+	)
+	tests := []struct {
+		name          string
+		identifier    string
+		target        *Target
+		wantPath      string
+		wantVariation rest.Variation
+		wantErr       bool
+	}{
+		{
+			name:       "rule matched evaluation reports the rule and clause taken",
+			identifier: theme,
+			target: &Target{
+				Identifier: harness,
+				Attributes: &map[string]interface{}{"country": "US"},
+			},
+			wantPath:      "variationMap→miss; rule#1→clause country equal CA miss; rule#2→clause country equal US match; serve blue",
+			wantVariation: colorVariations[0],
+		},
+		{
+			name:          "off flag reports the off variation",
+			identifier:    simple,
+			target:        &Target{Identifier: harness},
+			wantPath:      "off; serve false",
+			wantVariation: boolVariations[1],
+		},
+		{
+			name:       "unknown flag returns an error",
+			identifier: notValidFlag,
+			target:     &Target{Identifier: harness},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := NewEvaluator(repo, nil, logger.NewNoOpLogger())
+			path, variation, err := e.EvaluatePath(tt.identifier, tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Evaluator.EvaluatePath() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if path != tt.wantPath {
+				t.Errorf("Evaluator.EvaluatePath() path = %q, want %q", path, tt.wantPath)
+			}
+			if !reflect.DeepEqual(variation, tt.wantVariation) {
+				t.Errorf("Evaluator.EvaluatePath() variation = %v, want %v", variation, tt.wantVariation)
+			}
+		})
+	}
+}
+
+func TestEvaluator_WithFloatEpsilon(t *testing.T) {
+	clause := &rest.Clause{
+		Attribute: "score",
+		Op:        floatEqualOperator,
+		Values:    []string{"0.3"},
+	}
+	target := &Target{
+		Identifier: harness,
+		Attributes: &map[string]interface{}{
+			"score": "0.3001",
+		},
+	}
+	e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger(), WithFloatEpsilon(1e-3))
+	if !e.newEvaluation(context.Background()).evaluateClause(clause, target) {
+		t.Errorf("Evaluator.evaluateClause() = false, want true with a 1e-3 epsilon")
+	}
+}
+
+type noListQuery struct {
+	Query
+}
+
+func TestEvaluator_EnabledBoolFlags(t *testing.T) {
+	repo := NewTestRepository(
+		map[string]rest.FeatureConfig{
+			"on": {
+				Feature:      "on",
+				State:        rest.FeatureStateOn,
+				Kind:         "boolean",
+				OffVariation: identifierFalse,
+				DefaultServe: rest.Serve{Variation: &identifierTrue},
+				Variations:   boolVariations,
+			},
+			"off": {
+				Feature:      "off",
+				State:        rest.FeatureStateOff,
+				Kind:         "boolean",
+				OffVariation: identifierFalse,
+				DefaultServe: rest.Serve{Variation: &identifierTrue},
+				Variations:   boolVariations,
+			},
+			"onServingFalse": {
+				Feature:      "onServingFalse",
+				State:        rest.FeatureStateOn,
+				Kind:         "boolean",
+				OffVariation: identifierFalse,
+				DefaultServe: rest.Serve{Variation: &identifierFalse},
+				Variations:   boolVariations,
+			},
+			"anotherOn": {
+				Feature:      "anotherOn",
+				State:        rest.FeatureStateOn,
+				Kind:         "boolean",
+				OffVariation: identifierFalse,
+				DefaultServe: rest.Serve{Variation: &identifierTrue},
+				Variations:   boolVariations,
+			},
+			theme: {
+				Feature:      theme,
+				State:        rest.FeatureStateOn,
+				Kind:         "string",
+				DefaultServe: rest.Serve{Variation: &identifierTrue},
+				Variations:   boolVariations,
+			},
+		},
+		nil,
+	)
+	tests := []struct {
+		name    string
+		query   Query
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "only enabled boolean flags are returned",
+			query: repo,
+			want:  []string{"anotherOn", "on"},
+		},
+		{
+			name:    "query provider that can't list flags returns an error",
+			query:   noListQuery{repo},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := NewEvaluator(tt.query, nil, logger.NewNoOpLogger())
+			got, err := e.EnabledBoolFlags(&Target{Identifier: harness})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Evaluator.EnabledBoolFlags() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluator.EnabledBoolFlags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluator_Evaluate(t *testing.T) {
+	repo := NewTestRepository(
+		map[string]rest.FeatureConfig{
+			simple:            testRepo.flags[simple],
+			prereqVarNotFound: testRepo.flags[prereqVarNotFound],
+			notValidFlag:      testRepo.flags[notValidFlag],
+			"off": {
+				Feature:      "off",
+				State:        rest.FeatureStateOff,
+				Kind:         "boolean",
+				OffVariation: identifierFalse,
+				Variations:   boolVariations,
+			},
+			theme: {
+				Feature: theme,
+				State:   rest.FeatureStateOn,
+				Kind:    "string",
+				VariationToTargetMap: &[]rest.VariationMap{
+					{
+						Variation: darktheme,
+						Targets: &[]rest.TargetMap{
+							{Identifier: &harness1},
+						},
+					},
+				},
+				Rules: &[]rest.ServingRule{
+					{
+						RuleId:   "rule2",
+						Priority: 2,
+						Clauses: []rest.Clause{
+							{Attribute: identifier, Op: equalOperator, Values: []string{harness2}},
+						},
+						Serve: rest.Serve{Variation: &darktheme},
+					},
+					{
+						RuleId:   "rule1",
+						Priority: 1,
+						Clauses: []rest.Clause{
+							{Attribute: identifier, Op: equalOperator, Values: []string{"other"}},
+						},
+						Serve: rest.Serve{Variation: &darktheme},
+					},
+				},
+				DefaultServe: rest.Serve{Variation: &lighttheme},
+				Variations:   stringVariations,
+			},
+		},
+		testRepo.segments,
+	)
+	ruleMatch := newEvaluationReason(ReasonRuleMatch)
+	ruleMatch.RuleIndex = 1
+	ruleMatch.RulePriority = 2
+	ruleMatch.RuleID = "rule2"
+	prerequisiteFailed := newEvaluationReason(ReasonPrerequisiteFailed)
+	prerequisiteFailed.Prerequisite = simple
+	prerequisiteFailed.PrerequisiteFailure = PrerequisiteUnmet
+
+	tests := []struct {
+		name          string
+		identifier    string
+		kind          string
+		target        *Target
+		wantVariation rest.Variation
+		wantReason    EvaluationReason
+		wantErr       bool
+	}{
+		{
+			name:          "target mapped to variation",
+			identifier:    theme,
+			kind:          "string",
+			target:        &Target{Identifier: harness1},
+			wantVariation: stringVariations[1],
+			wantReason:    newEvaluationReason(ReasonTargetMatch),
+		},
+		{
+			name:          "serving rule matched",
+			identifier:    theme,
+			kind:          "string",
+			target:        &Target{Identifier: harness2},
+			wantVariation: stringVariations[1],
+			wantReason:    ruleMatch,
+		},
+		{
+			name:          "default serve",
+			identifier:    theme,
+			kind:          "string",
+			target:        &Target{Identifier: harness},
+			wantVariation: stringVariations[0],
+			wantReason:    newEvaluationReason(ReasonDefault),
+		},
+		{
+			name:          "flag is off",
+			identifier:    "off",
+			kind:          "boolean",
+			target:        &Target{Identifier: harness},
+			wantVariation: boolVariations[1],
+			wantReason:    newEvaluationReason(ReasonOff),
+		},
+		{
+			name:          "prerequisite not met",
+			identifier:    prereqVarNotFound,
+			kind:          "boolean",
+			target:        &Target{Identifier: harness},
+			wantVariation: boolVariations[1],
+			wantReason:    prerequisiteFailed,
+		},
+		{
+			name:       "kind mismatch",
+			identifier: theme,
+			kind:       "boolean",
+			target:     &Target{Identifier: harness},
+			wantReason: newEvaluationReason(ReasonError),
+			wantErr:    true,
+		},
+		{
+			name:       "flag can't be evaluated",
+			identifier: notValidFlag,
+			kind:       "boolean",
+			target:     &Target{Identifier: harness},
+			wantReason: newEvaluationReason(ReasonError),
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := NewEvaluator(repo, nil, logger.NewNoOpLogger())
+			variation, reason, err := e.Evaluate(tt.identifier, tt.target, tt.kind)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Evaluator.Evaluate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(variation, tt.wantVariation) {
+				t.Errorf("Evaluator.Evaluate() variation = %v, want %v", variation, tt.wantVariation)
+			}
+			if !reflect.DeepEqual(reason, tt.wantReason) {
+				t.Errorf("Evaluator.Evaluate() reason = %+v, want %+v", reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+// blockingSegmentQuery blocks segment lookups until the context passed to them is done
+type blockingSegmentQuery struct {
+	TestRepository
+}
+
+func (q blockingSegmentQuery) GetSegmentWithContext(ctx context.Context, identifier string) (rest.Segment, error) {
+	<-ctx.Done()
+	return rest.Segment{}, ctx.Err()
+}
+
+func (q blockingSegmentQuery) GetFlagWithContext(ctx context.Context, identifier string) (rest.FeatureConfig, error) {
+	return q.GetFlag(identifier)
+}
+
+func TestEvaluator_VariationCtx(t *testing.T) {
+	segmentFlag := rest.FeatureConfig{
+		Feature:      "segmentFlag",
+		State:        rest.FeatureStateOn,
+		Kind:         "boolean",
+		OffVariation: identifierFalse,
+		Rules: &[]rest.ServingRule{
+			{
+				Clauses: []rest.Clause{
+					{Op: segmentMatchOperator, Values: []string{beta}},
+				},
+				Serve: rest.Serve{Variation: &identifierTrue},
+			},
+		},
+		DefaultServe: rest.Serve{Variation: &identifierFalse},
+		Variations:   boolVariations,
+	}
+	repo := NewTestRepository(
+		map[string]rest.FeatureConfig{
+			simple:              testRepo.flags[simple],
+			theme:               testRepo.flags[theme],
+			segmentFlag.Feature: segmentFlag,
+		},
+		testRepo.segments,
+	)
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Run("cancelled context returns the default value", func(t *testing.T) {
+		e, _ := NewEvaluator(repo, nil, logger.NewNoOpLogger())
+		if got := e.BoolVariationCtx(cancelled, simple, &Target{Identifier: harness}, false); got {
+			t.Errorf("Evaluator.BoolVariationCtx() = %v, want false", got)
+		}
+		if got := e.StringVariationCtx(cancelled, theme, &Target{Identifier: harness}, darktheme); got != darktheme {
+			t.Errorf("Evaluator.StringVariationCtx() = %v, want %v", got, darktheme)
+		}
+	})
+
+	t.Run("live context evaluates the flag", func(t *testing.T) {
+		e, _ := NewEvaluator(repo, nil, logger.NewNoOpLogger())
+		if got := e.BoolVariationCtx(context.Background(), segmentFlag.Feature, &Target{Identifier: harness}, false); !got {
+			t.Errorf("Evaluator.BoolVariationCtx() = %v, want true", got)
+		}
+	})
+
+	t.Run("deadline bounds a blocking segment lookup", func(t *testing.T) {
+		e, _ := NewEvaluator(blockingSegmentQuery{repo}, nil, logger.NewNoOpLogger())
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, _, err := e.EvaluateCtx(ctx, segmentFlag.Feature, &Target{Identifier: harness}, "boolean")
+		if err != context.DeadlineExceeded {
+			t.Errorf("Evaluator.EvaluateCtx() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+}
+
+func TestEvaluator_WithStage(t *testing.T) {
+	clause := rest.Clause{Op: stageInOperator, Values: []string{"beta", "ga"}}
+	tests := []struct {
+		stage string
+		want  bool
+	}{
+		{stage: "", want: false},
+		{stage: "internal", want: false},
+		{stage: "beta", want: true},
+		{stage: "ga", want: true},
+	}
+	for _, tt := range tests {
+		t.Run("stage "+tt.stage, func(t *testing.T) {
+			e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger(), WithStage(tt.stage))
+			state := e.newEvaluation(context.Background())
+			if got := state.evaluateClause(&clause, &Target{Identifier: harness}); got != tt.want {
+				t.Errorf("Evaluator.evaluateClause() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluator_evaluateRulesConcurrently(t *testing.T) {
+	rules := []rest.ServingRule{
+		{
+			RuleId:   "low",
+			Priority: 2,
+			Clauses:  []rest.Clause{{Attribute: identifier, Op: equalOperator, Values: []string{harness}}},
+			Serve:    rest.Serve{Variation: &identifierFalse},
+		},
+		{
+			RuleId:   "high",
+			Priority: 1,
+			Clauses:  []rest.Clause{{Attribute: identifier, Op: equalOperator, Values: []string{harness}}},
+			Serve:    rest.Serve{Variation: &identifierTrue},
+		},
+	}
+	flag := rest.FeatureConfig{
+		Feature:      "concurrentFlag",
+		State:        rest.FeatureStateOn,
+		Kind:         "boolean",
+		OffVariation: identifierFalse,
+		Rules:        &rules,
+		DefaultServe: rest.Serve{Variation: &identifierFalse},
+		Variations:   boolVariations,
+	}
+	repo := NewTestRepository(map[string]rest.FeatureConfig{flag.Feature: flag}, nil)
+	e, _ := NewEvaluator(repo, nil, logger.NewNoOpLogger())
+
+	var wg sync.WaitGroup
+	results := make(chan bool, 50)
+	for i := 0; i < cap(results); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- e.BoolVariation(flag.Feature, &Target{Identifier: harness}, false)
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	for got := range results {
+		if !got {
+			t.Errorf("Evaluator.BoolVariation() = %v, want true", got)
+		}
+	}
+	if rules[0].RuleId != "low" || rules[1].RuleId != "high" {
+		t.Errorf("serving rules were reordered: %v, %v", rules[0].RuleId, rules[1].RuleId)
+	}
+}
+
+func TestEvaluator_WithMaxJSONVariationSize(t *testing.T) {
+	defaultValue := map[string]interface{}{
+		"email": "harness@harness.io",
+	}
+	limit := len(json2Value) - 1
+
+	e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger(), WithMaxJSONVariationSize(limit))
+	if got := e.JSONVariation(org, nil, defaultValue); !reflect.DeepEqual(got, defaultValue) {
+		t.Errorf("Evaluator.JSONVariation() = %v, want %v", got, defaultValue)
+	}
+	_, reason, err := e.Evaluate(org, nil, "json")
+	if !errors.Is(err, ErrVariationTooLarge) {
+		t.Errorf("Evaluator.Evaluate() error = %v, want %v", err, ErrVariationTooLarge)
+	}
+	if reason.Kind != ReasonParseError {
+		t.Errorf("Evaluator.Evaluate() reason = %v, want %v", reason.Kind, ReasonParseError)
+	}
+
+	e, _ = NewEvaluator(testRepo, nil, logger.NewNoOpLogger(), WithMaxJSONVariationSize(len(json2Value)))
+	if got := e.JSONVariation(org, nil, defaultValue); reflect.DeepEqual(got, defaultValue) {
+		t.Errorf("Evaluator.JSONVariation() = %v, want the variation value", got)
+	}
+}
+
+// countingPostEvaluate counts the evaluations it is notified of
+type countingPostEvaluate struct {
+	calls int
+}
+
+func (c *countingPostEvaluate) PostEvaluateProcessor(*PostEvalData) {
+	c.calls++
+}
+
+func TestEvaluator_WithMaxJSONVariationSizeRejectsBeforeServing(t *testing.T) {
+	metrics := &recordingMetrics{}
+	postEvaluate := &countingPostEvaluate{}
+	target := &Target{Identifier: harness}
+	e, _ := NewEvaluator(testRepo, postEvaluate, logger.NewNoOpLogger(),
+		WithMaxJSONVariationSize(len(json2Value)-1), WithMetricsCallback(metrics), WithLastKnownFallback(8))
+
+	if _, _, err := e.Evaluate(org, target, "json"); !errors.Is(err, ErrVariationTooLarge) {
+		t.Fatalf("Evaluator.Evaluate() error = %v, want %v", err, ErrVariationTooLarge)
+	}
+	if postEvaluate.calls != 0 {
+		t.Errorf("post evaluation ran %d times for a rejected variation", postEvaluate.calls)
+	}
+	if len(metrics.records) != 1 || !errors.Is(metrics.records[0].err, ErrVariationTooLarge) ||
+		metrics.records[0].variation != nil {
+		t.Errorf("recorded %+v, want a single evaluation failing with %v", metrics.records, ErrVariationTooLarge)
+	}
+	if variation, ok := e.lastKnown.get(org, "json", target); ok {
+		t.Errorf("rejected variation %v was remembered as the last known one", variation.Identifier)
+	}
+}
+
+func TestEvaluator_EvaluateSegmentMatch(t *testing.T) {
+	segment := rest.Segment{
+		Identifier: beta,
+		Name:       "Beta Testers",
+		Included:   &[]rest.Target{{Identifier: harness}},
+	}
+	flag := rest.FeatureConfig{
+		Feature:      "segmentFlag",
+		State:        rest.FeatureStateOn,
+		Kind:         "boolean",
+		OffVariation: identifierFalse,
+		Rules: &[]rest.ServingRule{
+			{
+				RuleId:   "beta-rule",
+				Priority: 1,
+				Clauses:  []rest.Clause{{Op: segmentMatchOperator, Values: []string{alpha, beta}}},
+				Serve:    rest.Serve{Variation: &identifierTrue},
+			},
+		},
+		DefaultServe: rest.Serve{Variation: &identifierFalse},
+		Variations:   boolVariations,
+	}
+	repo := NewTestRepository(
+		map[string]rest.FeatureConfig{flag.Feature: flag},
+		map[string]rest.Segment{
+			alpha: {Identifier: alpha, Name: "Alpha Testers"},
+			beta:  segment,
+		},
+	)
+	e, _ := NewEvaluator(repo, nil, logger.NewNoOpLogger())
+
+	_, reason, err := e.Evaluate(flag.Feature, &Target{Identifier: harness}, "boolean")
+	if err != nil {
+		t.Fatalf("Evaluator.Evaluate() error = %v", err)
+	}
+	if reason.SegmentID != segment.Identifier || reason.SegmentName != segment.Name {
+		t.Errorf("Evaluator.Evaluate() segment = %q %q, want %q %q",
+			reason.SegmentID, reason.SegmentName, segment.Identifier, segment.Name)
+	}
+
+	path, _, err := e.EvaluatePath(flag.Feature, &Target{Identifier: harness})
+	if err != nil {
+		t.Fatalf("Evaluator.EvaluatePath() error = %v", err)
+	}
+	want := "rule#1→clause segmentMatch alpha,beta match " +
+		"[segment alpha (Alpha Testers) miss, segment beta (Beta Testers) match]; serve true"
+	if path != want {
+		t.Errorf("Evaluator.EvaluatePath() path = %q, want %q", path, want)
+	}
+
+	_, reason, _ = e.Evaluate(flag.Feature, &Target{Identifier: harness1}, "boolean")
+	if reason.SegmentID != "" || reason.SegmentName != "" {
+		t.Errorf("Evaluator.Evaluate() segment = %q %q, want none", reason.SegmentID, reason.SegmentName)
+	}
+}
+
+// countingQuery counts the lookups made against the wrapped repository
+type countingQuery struct {
+	TestRepository
+	lookups map[string]int
+}
+
+func (q countingQuery) GetSegment(identifier string) (rest.Segment, error) {
+	q.lookups["segment "+identifier]++
+	return q.TestRepository.GetSegment(identifier)
+}
+
+func (q countingQuery) GetFlag(identifier string) (rest.FeatureConfig, error) {
+	q.lookups["flag "+identifier]++
+	return q.TestRepository.GetFlag(identifier)
+}
+
+func TestEvaluator_evaluateMemoizesSegments(t *testing.T) {
+	flag := rest.FeatureConfig{
+		Feature:      "sharedSegment",
+		State:        rest.FeatureStateOn,
+		Kind:         "boolean",
+		OffVariation: identifierFalse,
+		Rules: &[]rest.ServingRule{
+			{
+				Priority: 0,
+				Clauses: []rest.Clause{
+					{Op: segmentMatchOperator, Values: []string{beta}},
+					{Attribute: "email", Op: equalOperator, Values: []string{"nobody@harness.io"}},
+				},
+				Serve: rest.Serve{Variation: &identifierFalse},
+			},
+			{
+				Priority: 1,
+				Clauses:  []rest.Clause{{Op: segmentMatchOperator, Values: []string{beta}}},
+				Serve:    rest.Serve{Variation: &identifierTrue},
+			},
+		},
+		DefaultServe: rest.Serve{Variation: &identifierFalse},
+		Variations:   boolVariations,
+	}
+	query := countingQuery{
+		TestRepository: NewTestRepository(map[string]rest.FeatureConfig{flag.Feature: flag}, testRepo.segments),
+		lookups:        map[string]int{},
+	}
+	e, _ := NewEvaluator(query, nil, logger.NewNoOpLogger())
+
+	if got := e.BoolVariation(flag.Feature, &Target{Identifier: harness}, false); !got {
+		t.Errorf("Evaluator.BoolVariation() = %v, want true", got)
+	}
+	if got := query.lookups["segment "+beta]; got != 1 {
+		t.Errorf("Evaluator.BoolVariation() fetched segment %s %d times, want 1", beta, got)
+	}
+
+	// the memo only lives for a single evaluation
+	e.BoolVariation(flag.Feature, &Target{Identifier: harness}, false)
+	if got := query.lookups["segment "+beta]; got != 2 {
+		t.Errorf("Evaluator.BoolVariation() fetched segment %s %d times after two evaluations, want 2", beta, got)
+	}
+}
+
+func TestEvaluator_EvaluateAll(t *testing.T) {
+	segmentFlag := func(feature string) rest.FeatureConfig {
+		return rest.FeatureConfig{
+			Feature:      feature,
+			State:        rest.FeatureStateOn,
+			Kind:         "boolean",
+			OffVariation: identifierFalse,
+			Rules: &[]rest.ServingRule{
+				{
+					Clauses: []rest.Clause{{Op: segmentMatchOperator, Values: []string{beta}}},
+					Serve:   rest.Serve{Variation: &identifierTrue},
+				},
+			},
+			DefaultServe: rest.Serve{Variation: &identifierFalse},
+			Variations:   boolVariations,
+		}
+	}
+	withPrereq := segmentFlag("withPrereq")
+	withPrereq.Prerequisites = &[]rest.Prerequisite{{Feature: "first", Variations: []string{identifierTrue}}}
+	query := countingQuery{
+		TestRepository: NewTestRepository(
+			map[string]rest.FeatureConfig{
+				"first":            segmentFlag("first"),
+				"second":           segmentFlag("second"),
+				withPrereq.Feature: withPrereq,
+				theme:              testRepo.flags[theme],
+			},
+			testRepo.segments,
+		),
+		lookups: map[string]int{},
+	}
+	e, _ := NewEvaluator(query, nil, logger.NewNoOpLogger())
+
+	got, err := e.EvaluateAll(&Target{Identifier: harness})
+	if err != nil {
+		t.Fatalf("Evaluator.EvaluateAll() error = %v", err)
+	}
+	want := map[string]rest.Variation{
+		"first":            boolVariations[0],
+		"second":           boolVariations[0],
+		withPrereq.Feature: boolVariations[0],
+		theme:              stringVariations[0],
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Evaluator.EvaluateAll() = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(query.lookups, map[string]int{"segment " + beta: 1}) {
+		t.Errorf("Evaluator.EvaluateAll() lookups = %v, want a single segment lookup", query.lookups)
+	}
+
+	_, err = Evaluator{query: noListQuery{testRepo}, logger: logger.NewNoOpLogger()}.EvaluateAll(&Target{Identifier: harness})
+	if err != ErrFlagListingUnsupported {
+		t.Errorf("Evaluator.EvaluateAll() error = %v, want %v", err, ErrFlagListingUnsupported)
+	}
+}
+
+func TestEvaluator_EvaluateFlagsForTarget(t *testing.T) {
+	segmentFlag := func(feature string) rest.FeatureConfig {
+		return rest.FeatureConfig{
+			Feature:      feature,
+			State:        rest.FeatureStateOn,
+			Kind:         "boolean",
+			OffVariation: identifierFalse,
+			Rules: &[]rest.ServingRule{
+				{
+					Clauses: []rest.Clause{{Op: segmentMatchOperator, Values: []string{beta}}},
+					Serve:   rest.Serve{Variation: &identifierTrue},
+				},
+			},
+			DefaultServe: rest.Serve{Variation: &identifierFalse},
+			Variations:   boolVariations,
+		}
+	}
+	withPrereq := segmentFlag("withPrereq")
+	withPrereq.Prerequisites = &[]rest.Prerequisite{{Feature: "first", Variations: []string{identifierTrue}}}
+	query := countingQuery{
+		TestRepository: NewTestRepository(
+			map[string]rest.FeatureConfig{
+				"first":            segmentFlag("first"),
+				"second":           segmentFlag("second"),
+				"unrequested":      segmentFlag("unrequested"),
+				withPrereq.Feature: withPrereq,
+			},
+			testRepo.segments,
+		),
+		lookups: map[string]int{},
+	}
+	e, _ := NewEvaluator(query, nil, logger.NewNoOpLogger())
+
+	got, err := e.EvaluateFlagsForTarget([]string{"first", "second", withPrereq.Feature, "missing"},
+		&Target{Identifier: harness})
+	if err != nil {
+		t.Fatalf("Evaluator.EvaluateFlagsForTarget() error = %v", err)
+	}
+	want := map[string]rest.Variation{
+		"first":            boolVariations[0],
+		"second":           boolVariations[0],
+		withPrereq.Feature: boolVariations[0],
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Evaluator.EvaluateFlagsForTarget() = %v, want %v", got, want)
+	}
+	wantLookups := map[string]int{
+		"segment " + beta:            1,
+		"flag first":                 1,
+		"flag second":                1,
+		"flag " + withPrereq.Feature: 1,
+		"flag missing":               1,
+	}
+	if !reflect.DeepEqual(query.lookups, wantLookups) {
+		t.Errorf("Evaluator.EvaluateFlagsForTarget() lookups = %v, want %v", query.lookups, wantLookups)
+	}
+}
+
+func TestEvaluator_WithEmptyDefaultServe(t *testing.T) {
+	// rules exist but none match and the default serve is empty
+	flag := rest.FeatureConfig{
+		Feature:      "emptyDefaultServe",
+		State:        rest.FeatureStateOn,
+		Kind:         "boolean",
+		OffVariation: identifierFalse,
+		Rules: &[]rest.ServingRule{
+			{
+				Clauses: []rest.Clause{{Attribute: identifier, Op: equalOperator, Values: []string{harness1}}},
+				Serve:   rest.Serve{Variation: &identifierTrue},
+			},
+		},
+		Variations: boolVariations,
+	}
+	tests := []struct {
+		name    string
+		options []EvaluatorOption
+		want    rest.Variation
+		wantErr bool
+	}{
+		{
+			name:    "fails by default",
+			wantErr: true,
+		},
+		{
+			name:    "serves the off variation",
+			options: []EvaluatorOption{WithEmptyDefaultServe(EmptyDefaultServeOff, "")},
+			want:    boolVariations[1],
+		},
+		{
+			name:    "serves the configured fallback variation",
+			options: []EvaluatorOption{WithEmptyDefaultServe(EmptyDefaultServeFallback, identifierTrue)},
+			want:    boolVariations[0],
+		},
+		{
+			name:    "unknown fallback variation fails",
+			options: []EvaluatorOption{WithEmptyDefaultServe(EmptyDefaultServeFallback, "unknown")},
+			wantErr: true,
+		},
+		{
+			name: "falls through to the variation configured for the kind",
+			options: []EvaluatorOption{
+				WithFallthroughVariations(map[rest.FeatureConfigKind]string{rest.FeatureConfigKindBoolean: identifierFalse}),
+			},
+			want: boolVariations[1],
+		},
+		{
+			name: "fallthrough variation of another kind fails",
+			options: []EvaluatorOption{
+				WithFallthroughVariations(map[rest.FeatureConfigKind]string{rest.FeatureConfigKindString: lighttheme}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty default serve policy takes precedence over the fallthrough variation",
+			options: []EvaluatorOption{
+				WithEmptyDefaultServe(EmptyDefaultServeFallback, identifierTrue),
+				WithFallthroughVariations(map[rest.FeatureConfigKind]string{rest.FeatureConfigKindBoolean: identifierFalse}),
+			},
+			want: boolVariations[0],
+		},
+		{
+			name:    "first variation when the policy fails",
+			options: []EvaluatorOption{WithFirstVariationFallback(true)},
+			want:    boolVariations[0],
+		},
+		{
+			name: "empty default serve policy takes precedence over the first variation",
+			options: []EvaluatorOption{
+				WithEmptyDefaultServe(EmptyDefaultServeOff, ""),
+				WithFirstVariationFallback(true),
+			},
+			want: boolVariations[1],
+		},
+		{
+			name: "first variation when the policy resolves to no variation",
+			options: []EvaluatorOption{
+				WithEmptyDefaultServe(EmptyDefaultServeFallback, ""),
+				WithFirstVariationFallback(true),
+			},
+			want: boolVariations[0],
+		},
+		{
+			name: "first variation takes precedence over the fallthrough variation",
+			options: []EvaluatorOption{
+				WithFirstVariationFallback(true),
+				WithFallthroughVariations(map[rest.FeatureConfigKind]string{rest.FeatureConfigKindBoolean: identifierFalse}),
+			},
+			want: boolVariations[0],
+		},
+		{
+			name: "empty default serve policy takes precedence over both fallbacks",
+			options: []EvaluatorOption{
+				WithFallthroughVariations(map[rest.FeatureConfigKind]string{rest.FeatureConfigKindBoolean: identifierTrue}),
+				WithFirstVariationFallback(true),
+				WithEmptyDefaultServe(EmptyDefaultServeOff, ""),
+			},
+			want: boolVariations[1],
+		},
+		{
+			name: "fallthrough variation when the policy resolves to no variation",
+			options: []EvaluatorOption{
+				WithEmptyDefaultServe(EmptyDefaultServeFallback, ""),
+				WithFallthroughVariations(map[rest.FeatureConfigKind]string{rest.FeatureConfigKindBoolean: identifierFalse}),
+			},
+			want: boolVariations[1],
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger(), tt.options...)
+			got, err := e.newEvaluation(context.Background()).evaluateFlag(flag, &Target{Identifier: harness})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Evaluator.evaluateFlag() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluator.evaluateFlag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluator_WithAttributeHasher(t *testing.T) {
+	email := "john@harness.io"
+	target := &Target{
+		Identifier: harness,
+		Attributes: &map[string]interface{}{
+			"email": SHA256AttributeHasher(email),
+		},
+	}
+	tests := []struct {
+		name    string
+		options []EvaluatorOption
+		clause  rest.Clause
+		want    bool
+	}{
+		{
+			name:    "raw clause value hashed with the configured scheme",
+			options: []EvaluatorOption{WithAttributeHasher(SHA256AttributeHasher)},
+			clause:  rest.Clause{Attribute: "email", Op: hashedEqualOperator, Values: []string{"jane@harness.io", email}},
+			want:    true,
+		},
+		{
+			name:   "precomputed hashed clause value",
+			clause: rest.Clause{Attribute: "email", Op: hashedEqualOperator, Values: []string{SHA256AttributeHasher(email)}},
+			want:   true,
+		},
+		{
+			name:    "different value",
+			options: []EvaluatorOption{WithAttributeHasher(SHA256AttributeHasher)},
+			clause:  rest.Clause{Attribute: "email", Op: hashedEqualOperator, Values: []string{"jane@harness.io"}},
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger(), tt.options...)
+			if got := e.newEvaluation(context.Background()).evaluateClause(&tt.clause, target); got != tt.want {
+				t.Errorf("Evaluator.evaluateClause() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluator_MatchingRules(t *testing.T) {
+	flag := rest.FeatureConfig{
+		Feature: "overlapping",
+		State:   rest.FeatureStateOn,
+		Kind:    "boolean",
+		Rules: &[]rest.ServingRule{
+			{
+				RuleId:   "harness-staff",
+				Priority: 2,
+				Clauses:  []rest.Clause{{Attribute: "email", Op: endsWithOperator, Values: []string{"@harness.io"}}},
+				Serve:    rest.Serve{Variation: &identifierTrue},
+			},
+			{
+				RuleId:   "other-country",
+				Priority: 3,
+				Clauses:  []rest.Clause{{Attribute: "country", Op: equalOperator, Values: []string{"CA"}}},
+				Serve:    rest.Serve{Variation: &identifierFalse},
+			},
+			{
+				RuleId:   "harness-target",
+				Priority: 1,
+				Clauses:  []rest.Clause{{Attribute: identifier, Op: equalOperator, Values: []string{harness}}},
+				Serve:    rest.Serve{Variation: &identifierTrue},
+			},
+		},
+		DefaultServe: rest.Serve{Variation: &identifierFalse},
+		Variations:   boolVariations,
+	}
+	repo := NewTestRepository(map[string]rest.FeatureConfig{flag.Feature: flag}, nil)
+	e, _ := NewEvaluator(repo, nil, logger.NewNoOpLogger())
+	target := &Target{
+		Identifier: harness,
+		Attributes: &map[string]interface{}{"email": "john@harness.io", "country": "US"},
+	}
+
+	got, err := e.MatchingRules(flag.Feature, target)
+	if err != nil {
+		t.Fatalf("Evaluator.MatchingRules() error = %v", err)
+	}
+	if want := []string{"harness-target", "harness-staff"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Evaluator.MatchingRules() = %v, want %v", got, want)
+	}
+
+	if _, err := e.MatchingRules(notValidFlag, target); err == nil {
+		t.Errorf("Evaluator.MatchingRules() expected an error for an unknown flag")
+	}
+}
+
+func TestEvaluator_VariationWithErr(t *testing.T) {
+	flags := map[string]rest.FeatureConfig{
+		"invalidBool": {
+			Feature:      "invalidBool",
+			State:        rest.FeatureStateOn,
+			Kind:         "boolean",
+			DefaultServe: rest.Serve{Variation: &invalidNumberValue},
+			Variations:   []rest.Variation{{Identifier: invalidNumberValue, Value: invalidNumberValue}},
+		},
+	}
+	for identifier, flag := range testRepo.flags {
+		flags[identifier] = flag
+	}
+	e, _ := NewEvaluator(NewTestRepository(flags, testRepo.segments), nil, logger.NewNoOpLogger())
+	target := &Target{Identifier: harness}
+
+	t.Run("bool", func(t *testing.T) {
+		if got, err := e.BoolVariationWithErr(simple, target, false); err != nil || !got {
+			t.Errorf("Evaluator.BoolVariationWithErr() = %v, %v, want true, nil", got, err)
+		}
+		if got, err := e.BoolVariationWithErr("flagNotFound1000", target, true); err == nil || !got {
+			t.Errorf("Evaluator.BoolVariationWithErr() = %v, %v, want the default and an error", got, err)
+		}
+		if got, err := e.BoolVariationWithErr(theme, target, true); !errors.Is(err, ErrFlagKindMismatch) || !got {
+			t.Errorf("Evaluator.BoolVariationWithErr() = %v, %v, want the default and %v", got, err, ErrFlagKindMismatch)
+		}
+		if got, err := e.BoolVariationWithErr("invalidBool", target, true); !errors.Is(err, ErrInvalidVariationValue) || !got {
+			t.Errorf("Evaluator.BoolVariationWithErr() = %v, %v, want the default and %v", got, err, ErrInvalidVariationValue)
+		}
+		if got := e.BoolVariation("invalidBool", target, true); !got {
+			t.Errorf("Evaluator.BoolVariation() = %v, want the default", got)
+		}
+	})
+
+	t.Run("string", func(t *testing.T) {
+		if got, err := e.StringVariationWithErr(theme, target, darktheme); err != nil || got != lighttheme {
+			t.Errorf("Evaluator.StringVariationWithErr() = %v, %v, want %v, nil", got, err, lighttheme)
+		}
+		if got, err := e.StringVariationWithErr(simple, target, darktheme); !errors.Is(err, ErrFlagKindMismatch) || got != darktheme {
+			t.Errorf("Evaluator.StringVariationWithErr() = %v, %v, want the default and %v", got, err, ErrFlagKindMismatch)
+		}
+	})
+
+	t.Run("int", func(t *testing.T) {
+		if _, err := e.IntVariationWithErr(weight, target, 0); err != nil {
+			t.Errorf("Evaluator.IntVariationWithErr() error = %v, want nil", err)
+		}
+		if got, err := e.IntVariationWithErr(invalidInt, target, 7); !errors.Is(err, ErrInvalidVariationValue) || got != 7 {
+			t.Errorf("Evaluator.IntVariationWithErr() = %v, %v, want the default and %v", got, err, ErrInvalidVariationValue)
+		}
+	})
+
+	t.Run("number", func(t *testing.T) {
+		if _, err := e.NumberVariationWithErr(weight, target, 0); err != nil {
+			t.Errorf("Evaluator.NumberVariationWithErr() error = %v, want nil", err)
+		}
+		if got, err := e.NumberVariationWithErr(invalidNumber, target, 7); !errors.Is(err, ErrInvalidVariationValue) || got != 7 {
+			t.Errorf("Evaluator.NumberVariationWithErr() = %v, %v, want the default and %v", got, err, ErrInvalidVariationValue)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		defaultValue := map[string]interface{}{"email": "harness@harness.io"}
+		if _, err := e.JSONVariationWithErr(org, target, defaultValue); err != nil {
+			t.Errorf("Evaluator.JSONVariationWithErr() error = %v, want nil", err)
+		}
+		got, err := e.JSONVariationWithErr(invalidJSON, target, defaultValue)
+		if !errors.Is(err, ErrInvalidVariationValue) || !reflect.DeepEqual(got, defaultValue) {
+			t.Errorf("Evaluator.JSONVariationWithErr() = %v, %v, want the default and %v", got, err, ErrInvalidVariationValue)
+		}
+	})
+}
+
+func TestEvaluator_BoolVariationLegacyValues(t *testing.T) {
+	on, off := "1", "0"
+	legacy := rest.FeatureConfig{
+		Feature:      "legacyBool",
+		State:        rest.FeatureStateOn,
+		Kind:         "boolean",
+		OffVariation: off,
+		DefaultServe: rest.Serve{Variation: &on},
+		Variations: []rest.Variation{
+			{Identifier: on, Value: " 1 "},
+			{Identifier: off, Value: "0"},
+		},
+	}
+	e, _ := NewEvaluator(NewTestRepository(map[string]rest.FeatureConfig{legacy.Feature: legacy}, nil),
+		nil, logger.NewNoOpLogger())
+	if got := e.BoolVariation(legacy.Feature, &Target{Identifier: harness}, false); !got {
+		t.Errorf("Evaluator.BoolVariation() = %v, want true", got)
+	}
+}
+
+func TestEvaluator_AssertVariation(t *testing.T) {
+	flag := rest.FeatureConfig{
+		Feature:      "gated",
+		State:        rest.FeatureStateOn,
+		Kind:         "boolean",
+		OffVariation: identifierFalse,
+		Rules: &[]rest.ServingRule{
+			{
+				RuleId:   "harness-target",
+				Priority: 1,
+				Clauses:  []rest.Clause{{Attribute: identifier, Op: equalOperator, Values: []string{harness}}},
+				Serve:    rest.Serve{Variation: &identifierFalse},
+			},
+		},
+		DefaultServe: rest.Serve{Variation: &identifierTrue},
+		Variations:   boolVariations,
+	}
+	e, _ := NewEvaluator(NewTestRepository(map[string]rest.FeatureConfig{flag.Feature: flag}, nil),
+		nil, logger.NewNoOpLogger())
+
+	if err := e.AssertVariation(flag.Feature, &Target{Identifier: harness1}, identifierTrue); err != nil {
+		t.Errorf("Evaluator.AssertVariation() error = %v, want nil", err)
+	}
+
+	err := e.AssertVariation(flag.Feature, &Target{Identifier: harness}, identifierTrue)
+	if !errors.Is(err, ErrVariationMismatch) {
+		t.Fatalf("Evaluator.AssertVariation() error = %v, want %v", err, ErrVariationMismatch)
+	}
+	want := "served variation differs from the expected variation: flag gated served false instead of true, " +
+		"reason: RULE_MATCH rule harness-target (priority 1)"
+	if err.Error() != want {
+		t.Errorf("Evaluator.AssertVariation() error = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestEvaluator_ValidateSegments(t *testing.T) {
+	segments := map[string]rest.Segment{
+		beta: {Identifier: beta},
+		"nested": {
+			Identifier: "nested",
+			Rules:      &[]rest.Clause{{Op: segmentMatchOperator, Values: []string{"deletedNested"}}},
+		},
+	}
+	e, _ := NewEvaluator(NewTestRepository(nil, segments), nil, logger.NewNoOpLogger())
+
+	flag := rest.FeatureConfig{
+		Feature: "segmented",
+		VariationToTargetMap: &[]rest.VariationMap{
+			{Variation: identifierTrue, TargetSegments: &[]string{beta}},
+		},
+		Rules: &[]rest.ServingRule{
+			{Clauses: []rest.Clause{{Op: segmentMatchOperator, Values: []string{beta, "deleted"}}}},
+			{Clauses: []rest.Clause{{Op: segmentMatchOperator, Values: []string{"nested"}}}},
+		},
+	}
+	errs := e.ValidateSegments(flag)
+	if len(errs) != 2 {
+		t.Fatalf("Evaluator.ValidateSegments() = %v, want 2 errors", errs)
+	}
+	for i, missing := range []string{"deleted", "deletedNested"} {
+		if !errors.Is(errs[i], ErrSegmentReference) || !strings.Contains(errs[i].Error(), "segment "+missing+":") {
+			t.Errorf("Evaluator.ValidateSegments() error = %v, want a reference error for %s", errs[i], missing)
+		}
+	}
+
+	flag.Rules = &[]rest.ServingRule{{Clauses: []rest.Clause{{Op: segmentMatchOperator, Values: []string{beta}}}}}
+	if errs := e.ValidateSegments(flag); len(errs) != 0 {
+		t.Errorf("Evaluator.ValidateSegments() = %v, want no errors", errs)
+	}
+}
+
+func TestEvaluator_RuleLogic(t *testing.T) {
+	and, or := rest.ServingRuleLogicAnd, rest.ServingRuleLogicOr
+	clauses := []rest.Clause{
+		{Attribute: "country", Op: equalOperator, Values: []string{"US"}},
+		{Attribute: "plan", Op: equalOperator, Values: []string{"pro"}},
+	}
+	target := &Target{
+		Identifier: harness,
+		Attributes: &map[string]interface{}{"country": "IE", "plan": "pro"},
+	}
+	tests := []struct {
+		name  string
+		logic *rest.ServingRuleLogic
+		want  bool
+	}{
+		{name: "clauses are and-ed by default", logic: nil, want: false},
+		{name: "and rule with mixed clause results", logic: &and, want: false},
+		{name: "or rule with mixed clause results", logic: &or, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger())
+			rule := rest.ServingRule{RuleId: "rule", Clauses: clauses, Logic: tt.logic}
+			if got := e.newEvaluation(context.Background()).evaluateRule(&rule, target); got != tt.want {
+				t.Errorf("Evaluator.evaluateRule() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("or rule without any matching clause", func(t *testing.T) {
+		e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger())
+		rule := rest.ServingRule{RuleId: "rule", Clauses: clauses, Logic: &or}
+		if e.newEvaluation(context.Background()).evaluateRule(&rule, &Target{Identifier: harness}) {
+			t.Errorf("Evaluator.evaluateRule() = true, want false")
+		}
+	})
+
+	t.Run("or rule serves its variation", func(t *testing.T) {
+		flag := rest.FeatureConfig{
+			Feature: "orRule",
+			State:   rest.FeatureStateOn,
+			Kind:    "boolean",
+			Rules: &[]rest.ServingRule{{
+				RuleId: "us-ca-or-beta",
+				Clauses: []rest.Clause{
+					{Attribute: "country", Op: inOperator, Values: []string{"US", "CA"}},
+					{Attribute: "betaUser", Op: equalOperator, Values: []string{"true"}},
+				},
+				Logic: &or,
+				Serve: rest.Serve{Variation: &identifierTrue},
+			}},
+			DefaultServe: rest.Serve{Variation: &identifierFalse},
+			Variations:   boolVariations,
+		}
+		e, _ := NewEvaluator(NewTestRepository(map[string]rest.FeatureConfig{flag.Feature: flag}, nil), nil,
+			logger.NewNoOpLogger())
+		for _, attributes := range []map[string]interface{}{
+			{"country": "CA", "betaUser": false},
+			{"country": "IE", "betaUser": true},
+		} {
+			attributes := attributes
+			if !e.BoolVariation(flag.Feature, &Target{Identifier: harness, Attributes: &attributes}, false) {
+				t.Errorf("Evaluator.BoolVariation() = false for %v, want true", attributes)
+			}
+		}
+		attributes := map[string]interface{}{"country": "IE", "betaUser": false}
+		if e.BoolVariation(flag.Feature, &Target{Identifier: harness, Attributes: &attributes}, true) {
+			t.Errorf("Evaluator.BoolVariation() = true for %v, want false", attributes)
+		}
+	})
+
+	segmentOr := rest.SegmentLogicOr
+	segments := map[string]rest.Segment{
+		"andSegment": {Identifier: "andSegment", Rules: &clauses},
+		"orSegment":  {Identifier: "orSegment", Rules: &clauses, Logic: &segmentOr},
+	}
+	e, _ := NewEvaluator(NewTestRepository(nil, segments), nil, logger.NewNoOpLogger())
+	if e.newEvaluation(context.Background()).isTargetIncludedOrExcludedInSegment([]string{"andSegment"}, target) {
+		t.Errorf("Evaluator.isTargetIncludedOrExcludedInSegment() = true, want false for an and segment")
+	}
+	if !e.newEvaluation(context.Background()).isTargetIncludedOrExcludedInSegment([]string{"orSegment"}, target) {
+		t.Errorf("Evaluator.isTargetIncludedOrExcludedInSegment() = false, want true for an or segment")
+	}
+}
+
+func TestEvaluator_WithOperatorAliases(t *testing.T) {
+	clause := &rest.Clause{Attribute: "country", Op: "eq", Values: []string{"US"}}
+	target := &Target{
+		Identifier: harness,
+		Attributes: &map[string]interface{}{"country": "US"},
+	}
+
+	e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger())
+	if e.newEvaluation(context.Background()).evaluateClause(clause, target) {
+		t.Errorf("Evaluator.evaluateClause() = true, want false for an unknown operator")
+	}
+
+	e, _ = NewEvaluator(testRepo, nil, logger.NewNoOpLogger(),
+		WithOperatorAliases(map[string]string{"eq": equalOperator, "ne": notEqualOperator}))
+	if !e.newEvaluation(context.Background()).evaluateClause(clause, target) {
+		t.Errorf("Evaluator.evaluateClause() = false, want true for an aliased operator")
+	}
+	clause.Op = "ne"
+	if e.newEvaluation(context.Background()).evaluateClause(clause, target) {
+		t.Errorf("Evaluator.evaluateClause() = true, want false for an aliased operator")
+	}
+}
+
+func TestEvaluator_WithMissingAttributePolicy(t *testing.T) {
+	or := rest.ServingRuleLogicOr
+	missing := rest.Clause{Attribute: "email", Op: equalOperator, Values: []string{"john@harness.io"}}
+	matching := rest.Clause{Attribute: "country", Op: equalOperator, Values: []string{"US"}}
+	failing := rest.Clause{Attribute: "country", Op: equalOperator, Values: []string{"IE"}}
+	target := &Target{
+		Identifier: harness,
+		Attributes: &map[string]interface{}{"country": "US"},
+	}
+
+	tests := []struct {
+		name   string
+		policy MissingAttributePolicy
+		rule   rest.ServingRule
+		want   bool
+	}{
+		{name: "fail only missing", policy: MissingAttributeFail,
+			rule: rest.ServingRule{Clauses: []rest.Clause{missing}}, want: false},
+		{name: "fail with matching clause", policy: MissingAttributeFail,
+			rule: rest.ServingRule{Clauses: []rest.Clause{missing, matching}}, want: false},
+		{name: "fail or with failing clause", policy: MissingAttributeFail,
+			rule: rest.ServingRule{Clauses: []rest.Clause{missing, failing}, Logic: &or}, want: false},
+		{name: "pass only missing", policy: MissingAttributePass,
+			rule: rest.ServingRule{Clauses: []rest.Clause{missing}}, want: true},
+		{name: "pass with matching clause", policy: MissingAttributePass,
+			rule: rest.ServingRule{Clauses: []rest.Clause{missing, matching}}, want: true},
+		{name: "pass or with failing clause", policy: MissingAttributePass,
+			rule: rest.ServingRule{Clauses: []rest.Clause{missing, failing}, Logic: &or}, want: true},
+		{name: "skip only missing", policy: MissingAttributeSkip,
+			rule: rest.ServingRule{Clauses: []rest.Clause{missing}}, want: false},
+		{name: "skip with matching clause", policy: MissingAttributeSkip,
+			rule: rest.ServingRule{Clauses: []rest.Clause{missing, matching}}, want: true},
+		{name: "skip with failing clause", policy: MissingAttributeSkip,
+			rule: rest.ServingRule{Clauses: []rest.Clause{missing, failing}}, want: false},
+		{name: "skip or with failing clause", policy: MissingAttributeSkip,
+			rule: rest.ServingRule{Clauses: []rest.Clause{missing, failing}, Logic: &or}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger(), WithMissingAttributePolicy(tt.policy))
+			if got, _ := e.newEvaluation(context.Background()).evaluateRuleClauses(&tt.rule, target); got != tt.want {
+				t.Errorf("Evaluator.evaluateRuleClauses() = %v, want %v", got, tt.want)
+			}
+			if got := e.newEvaluation(context.Background()).evaluateRule(&tt.rule, target); got != tt.want {
+				t.Errorf("Evaluator.evaluateRule() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluator_nestedSegments(t *testing.T) {
+	references := func(identifier string, referenced ...string) rest.Segment {
+		return rest.Segment{
+			Identifier: identifier,
+			Name:       identifier,
+			Rules:      &[]rest.Clause{{Op: segmentMatchOperator, Values: referenced}},
+		}
+	}
+	segments := map[string]rest.Segment{
+		beta:      testRepo.segments[beta],
+		"parent":  references("parent", beta),
+		"grandpa": references("grandpa", "parent"),
+		"self":    references("self", "self"),
+		"cycleA":  references("cycleA", "cycleB"),
+		"cycleB":  references("cycleB", "cycleA"),
+		"escape":  references("escape", "escape", beta),
+	}
+	e, _ := NewEvaluator(NewTestRepository(nil, segments), nil, logger.NewNoOpLogger())
+	target := &Target{Identifier: harness}
+
+	tests := []struct {
+		segment string
+		want    bool
+	}{
+		{segment: "parent", want: true},
+		{segment: "grandpa", want: true},
+		{segment: "self", want: false},
+		{segment: "cycleA", want: false},
+		{segment: "escape", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.segment, func(t *testing.T) {
+			state := e.newEvaluation(context.Background())
+			if got := state.isTargetIncludedOrExcludedInSegment([]string{tt.segment}, target); got != tt.want {
+				t.Errorf("Evaluator.isTargetIncludedOrExcludedInSegment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	var deep []string
+	for i := 0; i <= maxSegmentDepth; i++ {
+		identifier := fmt.Sprintf("depth%d", i)
+		deep = append(deep, identifier)
+		segments[identifier] = references(identifier, fmt.Sprintf("depth%d", i+1))
+	}
+	segments[fmt.Sprintf("depth%d", maxSegmentDepth+1)] = testRepo.segments[beta]
+	if !e.newEvaluation(context.Background()).isTargetIncludedOrExcludedInSegment(deep[2:3], target) {
+		t.Errorf("Evaluator.isTargetIncludedOrExcludedInSegment() = false, want true for %d nested segments",
+			maxSegmentDepth)
+	}
+	if e.newEvaluation(context.Background()).isTargetIncludedOrExcludedInSegment(deep[:1], target) {
+		t.Errorf("Evaluator.isTargetIncludedOrExcludedInSegment() = true, want false beyond %d nested segments",
+			maxSegmentDepth)
+	}
+}
+
+type evaluationRecord struct {
+	flag      string
+	variation *rest.Variation
+	err       error
+}
+
+// recordingMetrics records every evaluation it is notified of
+type recordingMetrics struct {
+	records []evaluationRecord
+}
+
+func (m *recordingMetrics) RecordEvaluation(flagIdentifier string, _ *Target, variation *rest.Variation, err error) {
+	m.records = append(m.records, evaluationRecord{flag: flagIdentifier, variation: variation, err: err})
+}
+
+func TestEvaluator_WithMetricsCallback(t *testing.T) {
+	metrics := &recordingMetrics{}
+	e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger(), WithMetricsCallback(metrics))
+	target := &Target{Identifier: harness}
+
+	if got := e.BoolVariation(simple, target, false); !got {
+		t.Errorf("Evaluator.BoolVariation() = %v, want true", got)
+	}
+	if got := e.BoolVariation("missing", target, true); !got {
+		t.Errorf("Evaluator.BoolVariation() = %v, want the default true", got)
+	}
+	if got := e.StringVariation(simple, target, "default"); got != "default" {
+		t.Errorf("Evaluator.StringVariation() = %v, want the default", got)
+	}
+
+	if len(metrics.records) != 3 {
+		t.Fatalf("recorded %d evaluations, want 3", len(metrics.records))
+	}
+	success := metrics.records[0]
+	if success.flag != simple || success.err != nil || success.variation == nil ||
+		success.variation.Identifier != identifierTrue {
+		t.Errorf("recorded %+v for a successful evaluation", success)
+	}
+	notFound := metrics.records[1]
+	if notFound.flag != "missing" || notFound.variation != nil || notFound.err == nil {
+		t.Errorf("recorded %+v for a missing flag", notFound)
+	}
+	mismatch := metrics.records[2]
+	if mismatch.flag != simple || mismatch.variation != nil || !errors.Is(mismatch.err, ErrFlagKindMismatch) {
+		t.Errorf("recorded %+v for a kind mismatch", mismatch)
+	}
+}
+
+func TestEvaluator_DisabledFlags(t *testing.T) {
+	disabled := func(flag rest.FeatureConfig, offVariation string) rest.FeatureConfig {
+		flag.State = rest.FeatureStateOff
+		flag.OffVariation = offVariation
+		return flag
+	}
+	repo := NewTestRepository(map[string]rest.FeatureConfig{
+		size: disabled(testRepo.flags[size], smallSize),
+		org:  disabled(testRepo.flags[org], json1),
+	}, nil)
+	e, _ := NewEvaluator(repo, nil, logger.NewNoOpLogger())
+	target := &Target{Identifier: harness}
+
+	t.Run("int", func(t *testing.T) {
+		want := 50
+		got, err := e.IntVariationWithErr(size, target, 100)
+		if !errors.Is(err, ErrFlagDisabled) || got != want {
+			t.Errorf("Evaluator.IntVariationWithErr() = %v, %v, want %v and %v", got, err, want, ErrFlagDisabled)
+		}
+		if got := e.IntVariation(size, target, 100); got != want {
+			t.Errorf("Evaluator.IntVariation() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		want := map[string]interface{}{"org": harness1}
+		defaultValue := map[string]interface{}{"email": "harness@harness.io"}
+		got, err := e.JSONVariationWithErr(org, target, defaultValue)
+		if !errors.Is(err, ErrFlagDisabled) || !reflect.DeepEqual(got, want) {
+			t.Errorf("Evaluator.JSONVariationWithErr() = %v, %v, want %v and %v", got, err, want, ErrFlagDisabled)
+		}
+		if got := e.JSONVariation(org, target, defaultValue); !reflect.DeepEqual(got, want) {
+			t.Errorf("Evaluator.JSONVariation() = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestEvaluator_WithMaxAttributeLength(t *testing.T) {
+	clause := &rest.Clause{Attribute: "email", Op: matchOperator, Values: []string{"^.*@harness\\.io$"}}
+	oversized := strings.Repeat("a", 1024) + "@harness.io"
+	tests := []struct {
+		name   string
+		length int
+		email  interface{}
+		want   bool
+	}{
+		{name: "without limit", length: 0, email: oversized, want: true},
+		{name: "within limit", length: 64, email: "john@harness.io", want: true},
+		{name: "oversized", length: 64, email: oversized, want: false},
+		{name: "oversized list element", length: 64, email: []string{"john@harness.io", oversized}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger(), WithMaxAttributeLength(tt.length))
+			target := &Target{
+				Identifier: harness,
+				Attributes: &map[string]interface{}{"email": tt.email},
+			}
+			if got := e.newEvaluation(context.Background()).evaluateClause(clause, target); got != tt.want {
+				t.Errorf("Evaluator.evaluateClause() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluator_MatchClauseAndRule(t *testing.T) {
+	or := rest.ServingRuleLogicOr
+	clauses := []rest.Clause{
+		{Attribute: "email", Op: endsWithOperator, Values: []string{"@harness.io"}},
+		{Attribute: "country", Op: inOperator, Values: []string{"IE", "US"}},
+		{Op: segmentMatchOperator, Values: []string{beta}},
+		{Attribute: "age", Op: gteOperator, Values: []string{"21"}},
+	}
+	targets := []*Target{
+		{Identifier: harness, Attributes: &map[string]interface{}{"email": "john@harness.io", "country": "IE", "age": 30}},
+		{Identifier: "other", Attributes: &map[string]interface{}{"email": "john@example.com", "country": "DE", "age": 18}},
+		nil,
+	}
+	e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger())
+
+	for _, target := range targets {
+		for i := range clauses {
+			state := e.newEvaluation(context.Background())
+			if got, want := e.MatchClause(&clauses[i], target), state.evaluateClause(&clauses[i], target); got != want {
+				t.Errorf("Evaluator.MatchClause(%v) = %v, want %v", clauses[i], got, want)
+			}
+		}
+		for _, rule := range []rest.ServingRule{{Clauses: clauses}, {Clauses: clauses, Logic: &or}} {
+			state := e.newEvaluation(context.Background())
+			if got, want := e.MatchRule(&rule, target), state.evaluateRule(&rule, target); got != want {
+				t.Errorf("Evaluator.MatchRule(%v) = %v, want %v", rule, got, want)
+			}
+		}
+	}
+	if !e.MatchRule(&rest.ServingRule{Clauses: clauses}, targets[0]) {
+		t.Errorf("Evaluator.MatchRule() = false, want true")
+	}
+}
+
+func TestEvaluator_WithMixedComparison(t *testing.T) {
+	clause := &rest.Clause{Attribute: "size", Op: gtOperator, Values: []string{"abc"}}
+	target := &Target{
+		Identifier: harness,
+		Attributes: &map[string]interface{}{"size": "5"},
+	}
+	tests := []struct {
+		policy MixedComparisonPolicy
+		op     string
+		want   bool
+	}{
+		{policy: MixedComparisonLexicographic, op: gtOperator, want: false},
+		{policy: MixedComparisonNumericFirst, op: gtOperator, want: false},
+		{policy: MixedComparisonNumericFirst, op: ltOperator, want: true},
+		{policy: MixedComparisonFail, op: gtOperator, want: false},
+		{policy: MixedComparisonFail, op: ltOperator, want: false},
+		{policy: MixedComparisonFail, op: lteOperator, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d %s", tt.policy, tt.op), func(t *testing.T) {
+			e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger(), WithMixedComparison(tt.policy))
+			clause.Op = tt.op
+			if got := e.newEvaluation(context.Background()).evaluateClause(clause, target); got != tt.want {
+				t.Errorf("Evaluator.evaluateClause() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluator_NumberVariationKinds(t *testing.T) {
+	answer, pi := "42", "3.14"
+	numberFlag := func(kind rest.FeatureConfigKind, value string) rest.FeatureConfig {
+		return rest.FeatureConfig{
+			Feature:      string(kind) + "Flag",
+			State:        rest.FeatureStateOn,
+			Kind:         kind,
+			DefaultServe: rest.Serve{Variation: &value},
+			Variations:   []rest.Variation{{Identifier: value, Value: value}},
+		}
+	}
+	intFlag := numberFlag(rest.FeatureConfigKindInt, answer)
+	floatFlag := numberFlag("number", pi)
+	e, _ := NewEvaluator(NewTestRepository(map[string]rest.FeatureConfig{
+		intFlag.Feature:   intFlag,
+		floatFlag.Feature: floatFlag,
+		simple:            testRepo.flags[simple],
+	}, nil), nil, logger.NewNoOpLogger())
+	target := &Target{Identifier: harness}
+
+	if got, err := e.NumberVariationWithErr(intFlag.Feature, target, 0); err != nil || got != 42 {
+		t.Errorf("Evaluator.NumberVariationWithErr() = %v, %v, want 42, nil", got, err)
+	}
+	if got, err := e.NumberVariationWithErr(floatFlag.Feature, target, 0); err != nil || got != 3.14 {
+		t.Errorf("Evaluator.NumberVariationWithErr() = %v, %v, want 3.14, nil", got, err)
+	}
+	if got, err := e.NumberVariationWithErr(simple, target, 1); !errors.Is(err, ErrFlagKindMismatch) || got != 1 {
+		t.Errorf("Evaluator.NumberVariationWithErr() = %v, %v, want the default and %v", got, err, ErrFlagKindMismatch)
+	}
+	// int variations stay restricted to int flags
+	if got, err := e.IntVariationWithErr(floatFlag.Feature, target, 1); !errors.Is(err, ErrFlagKindMismatch) || got != 1 {
+		t.Errorf("Evaluator.IntVariationWithErr() = %v, %v, want the default and %v", got, err, ErrFlagKindMismatch)
+	}
+}
+
+func TestEvaluator_EvaluateRolloutVariations(t *testing.T) {
+	distribution := &rest.Distribution{
+		BucketBy: identifier,
+		Variations: []rest.WeightedVariation{
+			{Variation: json1, Weight: 50},
+			{Variation: json2, Weight: 30},
+			{Variation: "json3", Weight: 20},
+		},
+	}
+	flag := rest.FeatureConfig{
+		Feature:      "experiment",
+		State:        rest.FeatureStateOn,
+		Kind:         "json",
+		DefaultServe: rest.Serve{Distribution: distribution},
+		Variations:   append(jsonVariations, rest.Variation{Identifier: "json3", Value: "{}"}),
+	}
+	e, _ := NewEvaluator(NewTestRepository(map[string]rest.FeatureConfig{flag.Feature: flag}, nil),
+		nil, logger.NewNoOpLogger())
+	target := &Target{Identifier: harness}
+
+	variation, reason, err := e.Evaluate(flag.Feature, target, "json")
+	if err != nil {
+		t.Fatalf("Evaluator.Evaluate() error = %v", err)
+	}
+	if want := evaluateDistribution(distribution, target); variation.Identifier != want {
+		t.Errorf("Evaluator.Evaluate() variation = %v, want %v", variation.Identifier, want)
+	}
+	if !reflect.DeepEqual(reason.RolloutVariations, distribution.Variations) {
+		t.Errorf("Evaluator.Evaluate() rollout variations = %v, want %v", reason.RolloutVariations, distribution.Variations)
+	}
+	if want := distributionWeight(distribution, variation.Identifier); reason.RolloutWeight != want {
+		t.Errorf("Evaluator.Evaluate() rollout weight = %v, want %v", reason.RolloutWeight, want)
+	}
+
+	// the reported slate doesn't share the flag configuration
+	reason.RolloutVariations[0].Weight = 0
+	if distribution.Variations[0].Weight != 50 {
+		t.Errorf("Evaluator.Evaluate() rollout variations share the flag distribution")
+	}
+}
+
+// warnRecorder is a logger that records the warnings it is given
+type warnRecorder struct {
+	logger.NoOpLogger
+	warnings []string
+}
+
+func (w *warnRecorder) Warnf(template string, args ...interface{}) {
+	w.warnings = append(w.warnings, fmt.Sprintf(template, args...))
+}
+
+func TestEvaluator_WithInvalidPatternPolicy(t *testing.T) {
+	flag := rest.FeatureConfig{
+		Feature: "invalidPattern",
+		State:   rest.FeatureStateOn,
+		Kind:    "boolean",
+		Rules: &[]rest.ServingRule{{
+			Clauses: []rest.Clause{{Attribute: "email", Op: matchOperator, Values: []string{"[harness"}}},
+			Serve:   rest.Serve{Variation: &identifierTrue},
+		}},
+		DefaultServe: rest.Serve{Variation: &identifierFalse},
+		Variations:   boolVariations,
+	}
+	repo := NewTestRepository(map[string]rest.FeatureConfig{flag.Feature: flag}, nil)
+	target := &Target{Identifier: harness, Attributes: &map[string]interface{}{"email": "john@harness.io"}}
+
+	tests := []struct {
+		name    string
+		options []EvaluatorOption
+		want    bool
+	}{
+		{name: "fail by default", want: false},
+		{name: "fail", options: []EvaluatorOption{WithInvalidPatternPolicy(InvalidPatternFail)}, want: false},
+		{name: "pass", options: []EvaluatorOption{WithInvalidPatternPolicy(InvalidPatternPass)}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &warnRecorder{}
+			e, _ := NewEvaluator(repo, nil, log, tt.options...)
+			if got := e.BoolVariation(flag.Feature, target, !tt.want); got != tt.want {
+				t.Errorf("Evaluator.BoolVariation() = %v, want %v", got, tt.want)
+			}
+			if len(log.warnings) != 1 {
+				t.Fatalf("expected one warning, got %v", log.warnings)
+			}
+			if warning := log.warnings[0]; !strings.Contains(warning, flag.Feature) || !strings.Contains(warning, "email") {
+				t.Errorf("warning %q doesn't name the flag and attribute", warning)
+			}
+		})
+	}
+}
+
+func TestEvaluator_WithStrictMode(t *testing.T) {
+	ruleFlag := func(feature string, clause rest.Clause) rest.FeatureConfig {
+		return rest.FeatureConfig{
+			Feature: feature,
+			State:   rest.FeatureStateOn,
+			Kind:    "boolean",
+			Rules: &[]rest.ServingRule{{
+				Clauses: []rest.Clause{clause},
+				Serve:   rest.Serve{Variation: &identifierTrue},
+			}},
+			DefaultServe: rest.Serve{Variation: &identifierFalse},
+			Variations:   boolVariations,
+		}
+	}
+	flags := map[string]rest.FeatureConfig{
+		"unknownOperator": ruleFlag("unknownOperator", rest.Clause{Attribute: identifier, Op: "statswith", Values: []string{"har"}}),
+		"emptyValues":     ruleFlag("emptyValues", rest.Clause{Attribute: identifier, Op: equalOperator, Values: []string{}}),
+		"valid":           ruleFlag("valid", rest.Clause{Attribute: identifier, Op: startsWithOperator, Values: []string{"har"}}),
+	}
+	repo := NewTestRepository(flags, nil)
+	target := &Target{Identifier: harness}
+
+	tests := []struct {
+		name          string
+		identifier    string
+		strict        bool
+		wantVariation string
+		wantErr       error
+	}{
+		{name: "lenient unknown operator", identifier: "unknownOperator", wantVariation: identifierFalse},
+		{name: "lenient empty values", identifier: "emptyValues", wantVariation: identifierFalse},
+		{name: "strict unknown operator", identifier: "unknownOperator", strict: true, wantErr: ErrInvalidClause},
+		{name: "strict empty values", identifier: "emptyValues", strict: true, wantErr: ErrInvalidClause},
+		{name: "strict valid clause", identifier: "valid", strict: true, wantVariation: identifierTrue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := NewEvaluator(repo, nil, logger.NewNoOpLogger(), WithStrictMode(tt.strict))
+			variation, _, err := e.Evaluate(tt.identifier, target, "boolean")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Evaluator.Evaluate() error = %v, want %v", err, tt.wantErr)
+			}
+			if variation.Identifier != tt.wantVariation {
+				t.Errorf("Evaluator.Evaluate() variation = %v, want %v", variation.Identifier, tt.wantVariation)
+			}
+		})
+	}
+}
+
+func TestEvaluator_EntryPointsHonourStrictModeAndMetrics(t *testing.T) {
+	flags := map[string]rest.FeatureConfig{
+		"unknownOperator": {
+			Feature: "unknownOperator",
+			State:   rest.FeatureStateOn,
+			Kind:    "boolean",
+			Rules: &[]rest.ServingRule{{
+				Clauses: []rest.Clause{{Attribute: identifier, Op: "statswith", Values: []string{"har"}}},
+				Serve:   rest.Serve{Variation: &identifierTrue},
+			}},
+			DefaultServe: rest.Serve{Variation: &identifierFalse},
+			Variations:   boolVariations,
+		},
+	}
+	repo := NewTestRepository(flags, nil)
+	target := &Target{Identifier: harness}
+
+	tests := []struct {
+		name     string
+		evaluate func(e Evaluator) error
+	}{
+		{name: "Evaluate", evaluate: func(e Evaluator) error {
+			_, _, err := e.Evaluate("unknownOperator", target, "boolean")
+			return err
+		}},
+		{name: "EvaluateAll", evaluate: func(e Evaluator) error {
+			variations, _ := e.EvaluateAll(target)
+			if _, ok := variations["unknownOperator"]; ok {
+				return nil
+			}
+			return ErrInvalidClause
+		}},
+		{name: "EvaluateFlagsForTarget", evaluate: func(e Evaluator) error {
+			variations, _ := e.EvaluateFlagsForTarget([]string{"unknownOperator"}, target)
+			if _, ok := variations["unknownOperator"]; ok {
+				return nil
+			}
+			return ErrInvalidClause
+		}},
+		{name: "EvaluateInSession", evaluate: func(e Evaluator) error {
+			_, err := e.EvaluateInSession(SessionCache{}, "unknownOperator", target, "boolean")
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := &recordingMetrics{}
+			e, _ := NewEvaluator(repo, nil, logger.NewNoOpLogger(), WithStrictMode(true), WithMetricsCallback(metrics))
+			if err := tt.evaluate(*e); !errors.Is(err, ErrInvalidClause) {
+				t.Errorf("%s error = %v, want %v", tt.name, err, ErrInvalidClause)
+			}
+			if len(metrics.records) != 1 || !errors.Is(metrics.records[0].err, ErrInvalidClause) {
+				t.Errorf("%s recorded %+v, want a single failed evaluation", tt.name, metrics.records)
+			}
+		})
+	}
+}
+
+func TestEvaluator_EvaluateFlagVersion(t *testing.T) {
+	version := int64(3)
+	flag := testRepo.flags[simple]
+	flag.Version = &version
+	flags := map[string]rest.FeatureConfig{simple: flag, theme: testRepo.flags[theme]}
+	e, _ := NewEvaluator(NewTestRepository(flags, nil), nil, logger.NewNoOpLogger())
+	target := &Target{Identifier: harness}
+
+	if _, reason, err := e.Evaluate(simple, target, "boolean"); err != nil || reason.FlagVersion != 3 {
+		t.Errorf("Evaluator.Evaluate() version = %v, %v, want 3", reason.FlagVersion, err)
+	}
+
+	bumped := int64(4)
+	flag.Version = &bumped
+	flags[simple] = flag
+	if _, reason, err := e.Evaluate(simple, target, "boolean"); err != nil || reason.FlagVersion != 4 {
+		t.Errorf("Evaluator.Evaluate() version after a bump = %v, %v, want 4", reason.FlagVersion, err)
+	}
+
+	if _, reason, err := e.Evaluate(theme, target, "string"); err != nil || reason.FlagVersion != 0 {
+		t.Errorf("Evaluator.Evaluate() version without one = %v, %v, want 0", reason.FlagVersion, err)
+	}
+}
+
+func TestEvaluator_beforeAfterNow(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger(), WithClock(func() time.Time { return now }))
+	target := &Target{
+		Identifier: harness,
+		Attributes: &map[string]interface{}{
+			"signedUp": "2024-01-15T09:30:00Z",
+			"trialEnd": strconv.FormatInt(now.Add(time.Hour).Unix()*1000, 10),
+		},
+	}
+	tests := []struct {
+		clause rest.Clause
+		want   bool
+	}{
+		{clause: rest.Clause{Attribute: "signedUp", Op: beforeOperator, Values: []string{nowValue}}, want: true},
+		{clause: rest.Clause{Attribute: "signedUp", Op: afterOperator, Values: []string{nowValue}}, want: false},
+		{clause: rest.Clause{Attribute: "trialEnd", Op: afterOperator, Values: []string{nowValue}}, want: true},
+		{clause: rest.Clause{Attribute: "trialEnd", Op: beforeOperator, Values: []string{nowValue}}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.clause.Attribute+" "+tt.clause.Op, func(t *testing.T) {
+			if got := e.newEvaluation(context.Background()).evaluateClause(&tt.clause, target); got != tt.want {
+				t.Errorf("Evaluator.evaluateClause() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package evaluation
+
+import (
+	"context"
+
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+// Explanation describes how a flag was evaluated for a target
+type Explanation struct {
+	Flag      string
+	Variation rest.Variation
+	Reason    EvaluationReason
+	// Steps are the decisions taken in evaluation order, for example the variation map lookup
+	// followed by every serving rule examined
+	Steps []ExplanationStep
+}
+
+// ExplanationStep is a single decision taken while evaluating a flag
+type ExplanationStep struct {
+	// Kind is one of "off", "prerequisite", "variationMap", "rule", "clause", "segment" or "defaultServe"
+	Kind string
+	// Label describes the decision, for example "clause country equal US" or "rule#2"
+	Label   string
+	Matched bool
+	// Steps are the decisions this one depended on, such as the clauses of a rule or the segments of
+	// a segmentMatch clause
+	Steps []ExplanationStep
+}
+
+// Explain evaluates the flag for the target without any post evaluation processing and returns every
+// prerequisite, rule, clause and segment examined with its outcome. When the evaluation fails the
+// steps taken until then are returned together with the error.
+func (e Evaluator) Explain(identifier string, target *Target) (Explanation, error) {
+	explanation := Explanation{Flag: identifier, Reason: newEvaluationReason(ReasonError)}
+	state := e.newEvaluation(context.Background())
+	flag, err := state.flag(identifier)
+	if err != nil {
+		return explanation, err
+	}
+
+	state.trace = &evaluationTrace{}
+	variation, err := state.evaluateFeature(flag, target)
+	explanation.Steps = explanationSteps(state.trace.roots)
+	if err != nil {
+		return explanation, err
+	}
+	state.reason.setFlagVersion(flag.Version)
+	explanation.Variation = variation
+	explanation.Reason = *state.reason
+	return explanation, nil
+}
+
+func explanationSteps(nodes []*traceNode) []ExplanationStep {
+	if len(nodes) == 0 {
+		return nil
+	}
+	steps := make([]ExplanationStep, 0, len(nodes))
+	for _, node := range nodes {
+		steps = append(steps, ExplanationStep{
+			Kind:    node.kind,
+			Label:   node.label,
+			Matched: node.matched,
+			Steps:   explanationSteps(node.children),
+		})
+	}
+	return steps
+}
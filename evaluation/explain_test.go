@@ -0,0 +1,85 @@
+package evaluation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+func TestEvaluator_Explain(t *testing.T) {
+	version := int64(3)
+	repo := NewTestRepository(
+		map[string]rest.FeatureConfig{
+			theme: {
+				Feature: theme,
+				State:   rest.FeatureStateOn,
+				Kind:    "string",
+				Rules: &[]rest.ServingRule{
+					{
+						RuleId:   "canada",
+						Priority: 1,
+						Clauses: []rest.Clause{
+							{Attribute: "country", Op: equalOperator, Values: []string{"CA"}},
+						},
+						Serve: rest.Serve{Variation: &lighttheme},
+					},
+					{
+						RuleId:   "beta",
+						Priority: 2,
+						Clauses: []rest.Clause{
+							{Op: segmentMatchOperator, Values: []string{"beta"}},
+						},
+						Serve: rest.Serve{Variation: &darktheme},
+					},
+				},
+				DefaultServe: rest.Serve{Variation: &lighttheme},
+				Variations:   stringVariations,
+				Version:      &version,
+			},
+		},
+		map[string]rest.Segment{
+			"beta": {
+				Identifier: "beta",
+				Name:       "Beta testers",
+				Rules: &[]rest.Clause{
+					{Attribute: "country", Op: equalOperator, Values: []string{"US"}},
+				},
+			},
+		},
+	)
+	e, _ := NewEvaluator(repo, nil, logger.NewNoOpLogger())
+	target := &Target{Identifier: harness, Attributes: &map[string]interface{}{"country": "US"}}
+
+	got, err := e.Explain(theme, target)
+	if err != nil {
+		t.Fatalf("Evaluator.Explain() error = %v", err)
+	}
+	want := []ExplanationStep{
+		{Kind: traceRule, Label: "rule#1", Steps: []ExplanationStep{
+			{Kind: traceClause, Label: "clause country equal CA"},
+		}},
+		{Kind: traceRule, Label: "rule#2", Matched: true, Steps: []ExplanationStep{
+			{Kind: traceClause, Label: "clause segmentMatch beta", Matched: true, Steps: []ExplanationStep{
+				{Kind: traceSegment, Label: "segment beta (Beta testers)", Matched: true, Steps: []ExplanationStep{
+					{Kind: traceClause, Label: "clause country equal US", Matched: true},
+				}},
+			}},
+		}},
+	}
+	if !reflect.DeepEqual(got.Steps, want) {
+		t.Errorf("Evaluator.Explain() steps = %+v, want %+v", got.Steps, want)
+	}
+	if got.Variation.Identifier != darktheme {
+		t.Errorf("Evaluator.Explain() variation = %v, want %v", got.Variation.Identifier, darktheme)
+	}
+	if got.Reason.Kind != ReasonRuleMatch || got.Reason.RuleID != "beta" || got.Reason.FlagVersion != version {
+		t.Errorf("Evaluator.Explain() reason = %+v, want rule beta of version %d", got.Reason, version)
+	}
+
+	missing, err := e.Explain("missing", target)
+	if err == nil || missing.Reason.Kind != ReasonError || len(missing.Steps) != 0 {
+		t.Errorf("Evaluator.Explain() = %+v, %v, want an error for a missing flag", missing, err)
+	}
+}
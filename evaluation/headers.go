@@ -0,0 +1,36 @@
+package evaluation
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+)
+
+// headerAttributePrefix prefixes clause attributes which reference request headers,
+// for example "header:X-Country"
+const headerAttributePrefix = "header:"
+
+type headersKey struct{}
+
+// ContextWithHeaders returns a copy of ctx carrying request headers which clauses can reference with
+// attributes of the form "header:X-Country" when the context is passed to a context aware evaluation
+func ContextWithHeaders(ctx context.Context, headers http.Header) context.Context {
+	return context.WithValue(ctx, headersKey{}, headers)
+}
+
+// headerAttrValue returns the first value of the named header carried by ctx, or an invalid value
+// when ctx carries no such header
+func headerAttrValue(ctx context.Context, name string) reflect.Value {
+	if ctx == nil {
+		return reflect.Value{}
+	}
+	headers, ok := ctx.Value(headersKey{}).(http.Header)
+	if !ok {
+		return reflect.Value{}
+	}
+	values := headers.Values(name)
+	if len(values) == 0 {
+		return reflect.Value{}
+	}
+	return reflect.ValueOf(values[0])
+}
@@ -0,0 +1,64 @@
+package evaluation
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+func TestEvaluator_HeaderAttributes(t *testing.T) {
+	flag := rest.FeatureConfig{
+		Feature:      "headerFlag",
+		State:        rest.FeatureStateOn,
+		Kind:         "boolean",
+		OffVariation: identifierFalse,
+		Rules: &[]rest.ServingRule{
+			{
+				Clauses: []rest.Clause{{Attribute: "header:X-Country", Op: equalOperator, Values: []string{"IE"}}},
+				Serve:   rest.Serve{Variation: &identifierTrue},
+			},
+		},
+		DefaultServe: rest.Serve{Variation: &identifierFalse},
+		Variations:   boolVariations,
+	}
+	e, _ := NewEvaluator(NewTestRepository(map[string]rest.FeatureConfig{flag.Feature: flag}, nil),
+		nil, logger.NewNoOpLogger())
+	target := &Target{Identifier: harness}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want bool
+	}{
+		{
+			name: "matching header",
+			ctx:  ContextWithHeaders(context.Background(), http.Header{"X-Country": []string{"IE"}}),
+			want: true,
+		},
+		{
+			name: "equal ignores the header value case",
+			ctx:  ContextWithHeaders(context.Background(), http.Header{"X-Country": []string{"ie"}}),
+			want: true,
+		},
+		{
+			name: "different header value",
+			ctx:  ContextWithHeaders(context.Background(), http.Header{"X-Country": []string{"US"}}),
+			want: false,
+		},
+		{
+			name: "no headers in the context",
+			ctx:  context.Background(),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := e.BoolVariationCtx(tt.ctx, flag.Feature, target, false); got != tt.want {
+				t.Errorf("Evaluator.BoolVariationCtx() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
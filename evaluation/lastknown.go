@@ -0,0 +1,67 @@
+package evaluation
+
+import (
+	"github.com/harness/ff-golang-server-sdk/rest"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// lastKnownCache keeps the variation last served per flag, kind and target so it can be served
+// when the flag can't be retrieved. It is safe for concurrent use and all methods are safe to
+// call on a nil cache.
+type lastKnownCache struct {
+	variations *lru.Cache
+}
+
+// newLastKnownCache returns a cache of at most size variations, or nil for a non positive size
+func newLastKnownCache(size int) *lastKnownCache {
+	variations, err := lru.New(size)
+	if err != nil {
+		return nil
+	}
+	return &lastKnownCache{variations: variations}
+}
+
+type lastKnownKey struct {
+	identifier string
+	kind       string
+	target     string
+}
+
+func newLastKnownKey(identifier, kind string, target *Target) lastKnownKey {
+	key := lastKnownKey{identifier: identifier, kind: kind}
+	if target != nil {
+		key.target = target.Identifier
+	}
+	return key
+}
+
+func (c *lastKnownCache) get(identifier, kind string, target *Target) (rest.Variation, bool) {
+	if c == nil {
+		return rest.Variation{}, false
+	}
+	cached, ok := c.variations.Get(newLastKnownKey(identifier, kind, target))
+	if !ok {
+		return rest.Variation{}, false
+	}
+	variation, ok := cached.(rest.Variation)
+	return variation, ok
+}
+
+func (c *lastKnownCache) set(identifier, kind string, target *Target, variation rest.Variation) {
+	if c != nil {
+		c.variations.Add(newLastKnownKey(identifier, kind, target), variation)
+	}
+}
+
+// forget drops the variations last served for the flag, for example when the flag is deleted
+func (c *lastKnownCache) forget(identifier string) {
+	if c == nil {
+		return
+	}
+	for _, key := range c.variations.Keys() {
+		if k, ok := key.(lastKnownKey); ok && k.identifier == identifier {
+			c.variations.Remove(key)
+		}
+	}
+}
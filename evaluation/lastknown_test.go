@@ -0,0 +1,116 @@
+package evaluation
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/pkg/repository"
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+var errQueryUnavailable = errors.New("query unavailable")
+
+// failingQuery fails flag lookups once failing is set
+type failingQuery struct {
+	TestRepository
+	failing *bool
+}
+
+func (q failingQuery) GetFlag(identifier string) (rest.FeatureConfig, error) {
+	if *q.failing {
+		return rest.FeatureConfig{}, errQueryUnavailable
+	}
+	return q.TestRepository.GetFlag(identifier)
+}
+
+func TestEvaluator_WithLastKnownFallback(t *testing.T) {
+	failing := false
+	query := failingQuery{TestRepository: testRepo, failing: &failing}
+	target := &Target{Identifier: harness}
+
+	e, _ := NewEvaluator(query, nil, logger.NewNoOpLogger(), WithLastKnownFallback(10))
+	if got := e.StringVariation(theme, target, darktheme); got != lighttheme {
+		t.Fatalf("Evaluator.StringVariation() = %v, want %v", got, lighttheme)
+	}
+
+	failing = true
+	variation, reason, err := e.Evaluate(theme, target, "string")
+	if err != nil {
+		t.Fatalf("Evaluator.Evaluate() error = %v", err)
+	}
+	if variation.Value != lighttheme || reason.Kind != ReasonLastKnown {
+		t.Errorf("Evaluator.Evaluate() = %v, %v, want %v, %v", variation.Value, reason.Kind, lighttheme, ReasonLastKnown)
+	}
+	if _, _, err := e.Evaluate(theme, &Target{Identifier: "other"}, "string"); !errors.Is(err, errQueryUnavailable) {
+		t.Errorf("Evaluator.Evaluate() for a target never served error = %v, want %v", err, errQueryUnavailable)
+	}
+	if _, _, err := e.Evaluate(simple, target, "boolean"); !errors.Is(err, errQueryUnavailable) {
+		t.Errorf("Evaluator.Evaluate() for a flag never served error = %v, want %v", err, errQueryUnavailable)
+	}
+
+	disabled, _ := NewEvaluator(query, nil, logger.NewNoOpLogger())
+	if got := disabled.StringVariation(theme, target, darktheme); got != darktheme {
+		t.Errorf("Evaluator.StringVariation() without the fallback = %v, want %v", got, darktheme)
+	}
+}
+
+// deletingQuery reports flags as not found once deleted is set, like the repository does after the
+// flag is deleted
+type deletingQuery struct {
+	TestRepository
+	deleted *bool
+}
+
+func (q deletingQuery) GetFlag(identifier string) (rest.FeatureConfig, error) {
+	if *q.deleted {
+		return rest.FeatureConfig{}, fmt.Errorf("%w with identifier: %s", repository.ErrFeatureConfigNotFound, identifier)
+	}
+	return q.TestRepository.GetFlag(identifier)
+}
+
+func TestEvaluator_WithLastKnownFallbackDeletedFlag(t *testing.T) {
+	target := &Target{Identifier: harness}
+
+	deleted := false
+	e, _ := NewEvaluator(deletingQuery{TestRepository: testRepo, deleted: &deleted}, nil, logger.NewNoOpLogger(),
+		WithLastKnownFallback(10))
+	if got := e.StringVariation(theme, target, darktheme); got != lighttheme {
+		t.Fatalf("Evaluator.StringVariation() = %v, want %v", got, lighttheme)
+	}
+	deleted = true
+	if _, _, err := e.Evaluate(theme, target, "string"); !errors.Is(err, repository.ErrFeatureConfigNotFound) {
+		t.Errorf("Evaluator.Evaluate() for a deleted flag error = %v, want %v", err,
+			repository.ErrFeatureConfigNotFound)
+	}
+
+	// a flag deleted through the repository callback isn't served even when the lookup fails otherwise
+	failing := false
+	e, _ = NewEvaluator(failingQuery{TestRepository: testRepo, failing: &failing}, nil, logger.NewNoOpLogger(),
+		WithLastKnownFallback(10))
+	if got := e.StringVariation(theme, target, darktheme); got != lighttheme {
+		t.Fatalf("Evaluator.StringVariation() = %v, want %v", got, lighttheme)
+	}
+	e.OnFlagDeleted(theme)
+	failing = true
+	if _, _, err := e.Evaluate(theme, target, "string"); !errors.Is(err, errQueryUnavailable) {
+		t.Errorf("Evaluator.Evaluate() after OnFlagDeleted error = %v, want %v", err, errQueryUnavailable)
+	}
+}
+
+func Test_lastKnownCacheBounded(t *testing.T) {
+	cache := newLastKnownCache(1)
+	first, second := &Target{Identifier: "first"}, &Target{Identifier: "second"}
+	cache.set(theme, "string", first, stringVariations[0])
+	cache.set(theme, "string", second, stringVariations[1])
+	if _, ok := cache.get(theme, "string", first); ok {
+		t.Errorf("lastKnownCache.get() found an evicted variation")
+	}
+	if got, ok := cache.get(theme, "string", second); !ok || got != stringVariations[1] {
+		t.Errorf("lastKnownCache.get() = %v, %v, want %v, true", got, ok, stringVariations[1])
+	}
+	if newLastKnownCache(0) != nil {
+		t.Errorf("newLastKnownCache(0) should disable the cache")
+	}
+}
@@ -0,0 +1,114 @@
+package evaluation
+
+import (
+	"sync"
+	"time"
+)
+
+// ListProvider loads the values of the named lists referenced by in_list clauses, for example large
+// allowlists which are too big to embed in the clause values
+type ListProvider interface {
+	GetList(name string) ([]string, error)
+}
+
+// listErrorBackoff is how long a list which failed to load is reported as failing before it is
+// loaded again, unless the list ttl is shorter
+const listErrorBackoff = 5 * time.Second
+
+// listCache caches the lists loaded from a ListProvider for ttl and is safe for concurrent use.
+// Lists are loaded outside the lock and concurrent lookups of a list being loaded wait for that load.
+type listCache struct {
+	provider ListProvider
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	lists   map[string]cachedList
+	loading map[string]*listLoad
+}
+
+type cachedList struct {
+	values  map[string]struct{}
+	err     error
+	expires time.Time
+}
+
+// listLoad is a list load in flight, done is closed once values and err are set
+type listLoad struct {
+	done   chan struct{}
+	values map[string]struct{}
+	err    error
+}
+
+func newListCache(provider ListProvider, ttl time.Duration) *listCache {
+	return &listCache{
+		provider: provider,
+		ttl:      ttl,
+		lists:    make(map[string]cachedList),
+		loading:  make(map[string]*listLoad),
+	}
+}
+
+// list returns the values of the named list, loading it from the provider when it isn't cached or
+// its entry expired by now. Load errors are cached for listErrorBackoff.
+func (c *listCache) list(name string, now time.Time) (map[string]struct{}, error) {
+	c.mu.Lock()
+	if cached, ok := c.lists[name]; ok && now.Before(cached.expires) {
+		c.mu.Unlock()
+		return cached.values, cached.err
+	}
+	if load, ok := c.loading[name]; ok {
+		c.mu.Unlock()
+		<-load.done
+		return load.values, load.err
+	}
+	load := &listLoad{done: make(chan struct{})}
+	c.loading[name] = load
+	c.mu.Unlock()
+
+	defer func() {
+		ttl := c.ttl
+		if load.err != nil && listErrorBackoff < ttl {
+			ttl = listErrorBackoff
+		}
+		c.mu.Lock()
+		c.lists[name] = cachedList{values: load.values, err: load.err, expires: now.Add(ttl)}
+		delete(c.loading, name)
+		c.mu.Unlock()
+		close(load.done)
+	}()
+	load.values, load.err = c.load(name)
+	return load.values, load.err
+}
+
+func (c *listCache) load(name string) (map[string]struct{}, error) {
+	loaded, err := c.provider.GetList(name)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]struct{}, len(loaded))
+	for _, value := range loaded {
+		values[value] = struct{}{}
+	}
+	return values, nil
+}
+
+// inList reports whether any of the attribute values is in one of the named lists
+func (e Evaluator) inList(names []string, attrValues []string) bool {
+	if e.lists == nil {
+		e.logger.Warnf("Clause operator %s requires a list provider, the clause doesn't match", inListOperator)
+		return false
+	}
+	for _, name := range names {
+		values, err := e.lists.list(name, e.now())
+		if err != nil {
+			e.logger.Warnf("List %s couldn't be loaded, err: %v", name, err)
+			continue
+		}
+		for _, attrValue := range attrValues {
+			if _, ok := values[attrValue]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
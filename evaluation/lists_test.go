@@ -0,0 +1,150 @@
+package evaluation
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+// countingListProvider serves lists from memory and counts how often each is loaded
+type countingListProvider struct {
+	lists map[string][]string
+	loads map[string]int
+}
+
+func (p *countingListProvider) GetList(name string) ([]string, error) {
+	p.loads[name]++
+	list, ok := p.lists[name]
+	if !ok {
+		return nil, errors.New("list not found " + name)
+	}
+	return list, nil
+}
+
+func TestEvaluator_inListOperator(t *testing.T) {
+	provider := &countingListProvider{
+		lists: map[string][]string{"allowlist": {"harness", "acme"}},
+		loads: map[string]int{},
+	}
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger(),
+		WithListProvider(provider, time.Minute), WithClock(func() time.Time { return now }))
+	clause := &rest.Clause{Attribute: identifier, Op: inListOperator, Values: []string{"allowlist"}}
+
+	if !e.newEvaluation(context.Background()).evaluateClause(clause, &Target{Identifier: harness}) {
+		t.Errorf("Evaluator.evaluateClause() = false for a listed target")
+	}
+	if e.newEvaluation(context.Background()).evaluateClause(clause, &Target{Identifier: "outsider"}) {
+		t.Errorf("Evaluator.evaluateClause() = true for a target missing from the list")
+	}
+	if provider.loads["allowlist"] != 1 {
+		t.Errorf("list loaded %d times within its ttl, want 1", provider.loads["allowlist"])
+	}
+
+	provider.lists["allowlist"] = []string{"acme"}
+	now = now.Add(2 * time.Minute)
+	if e.newEvaluation(context.Background()).evaluateClause(clause, &Target{Identifier: harness}) {
+		t.Errorf("Evaluator.evaluateClause() = true for a target removed from the reloaded list")
+	}
+	if provider.loads["allowlist"] != 2 {
+		t.Errorf("list loaded %d times after its ttl, want 2", provider.loads["allowlist"])
+	}
+
+	missing := &rest.Clause{Attribute: identifier, Op: inListOperator, Values: []string{"missing"}}
+	if e.newEvaluation(context.Background()).evaluateClause(missing, &Target{Identifier: harness}) {
+		t.Errorf("Evaluator.evaluateClause() = true for a list which can't be loaded")
+	}
+
+	withoutProvider, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger())
+	if withoutProvider.newEvaluation(context.Background()).evaluateClause(clause, &Target{Identifier: "acme"}) {
+		t.Errorf("Evaluator.evaluateClause() = true without a list provider")
+	}
+}
+
+// blockingListProvider signals started when it begins loading the list named "slow" and serves it
+// once release is closed, lists named "failing" fail to load
+type blockingListProvider struct {
+	started chan struct{}
+	release chan struct{}
+
+	mu    sync.Mutex
+	loads map[string]int
+}
+
+func (p *blockingListProvider) GetList(name string) ([]string, error) {
+	p.mu.Lock()
+	p.loads[name]++
+	p.mu.Unlock()
+	if name == "failing" {
+		return nil, errors.New("list unavailable")
+	}
+	if name == "slow" {
+		p.started <- struct{}{}
+		<-p.release
+	}
+	return []string{harness}, nil
+}
+
+func (p *blockingListProvider) loaded(name string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.loads[name]
+}
+
+func Test_listCache_slowProvider(t *testing.T) {
+	provider := &blockingListProvider{
+		started: make(chan struct{}, 3),
+		release: make(chan struct{}),
+		loads:   map[string]int{},
+	}
+	cache := newListCache(provider, time.Minute)
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if values, err := cache.list("slow", now); err != nil || len(values) != 1 {
+				t.Errorf("listCache.list() = %v, %v, want the slow list", values, err)
+			}
+		}()
+	}
+
+	// other lists load while the slow list is still loading
+	<-provider.started
+	if _, err := cache.list("fast", now); err != nil {
+		t.Errorf("listCache.list() error = %v", err)
+	}
+	close(provider.release)
+	wg.Wait()
+	if loads := provider.loaded("slow"); loads != 1 {
+		t.Errorf("slow list loaded %d times by concurrent lookups, want 1", loads)
+	}
+}
+
+func Test_listCache_failingProvider(t *testing.T) {
+	provider := &blockingListProvider{loads: map[string]int{}}
+	cache := newListCache(provider, time.Minute)
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, at := range []time.Duration{0, time.Second, listErrorBackoff - time.Millisecond} {
+		if _, err := cache.list("failing", now.Add(at)); err == nil {
+			t.Errorf("listCache.list() error = nil for a failing list")
+		}
+	}
+	if loads := provider.loaded("failing"); loads != 1 {
+		t.Errorf("failing list loaded %d times within the backoff, want 1", loads)
+	}
+	if _, err := cache.list("failing", now.Add(listErrorBackoff)); err == nil {
+		t.Errorf("listCache.list() error = nil for a failing list")
+	}
+	if loads := provider.loaded("failing"); loads != 2 {
+		t.Errorf("failing list loaded %d times after the backoff, want 2", loads)
+	}
+}
@@ -0,0 +1,47 @@
+package evaluation
+
+import (
+	"reflect"
+	"strings"
+)
+
+// NumericLocale describes the separators used in numeric string attribute values, which are
+// normalized to a '.' decimal separator without grouping before numeric operators parse them.
+// The zero value leaves attributes untouched.
+type NumericLocale struct {
+	Decimal  rune
+	Grouping rune
+}
+
+var (
+	// EnglishNumericLocale formats numbers as 1,234.56
+	EnglishNumericLocale = NumericLocale{Decimal: '.', Grouping: ','}
+	// EuropeanNumericLocale formats numbers as 1.234,56
+	EuropeanNumericLocale = NumericLocale{Decimal: ',', Grouping: '.'}
+)
+
+// normalize rewrites value formatted in the locale to the format expected by strconv
+func (l NumericLocale) normalize(value string) string {
+	if l == (NumericLocale{}) {
+		return value
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case l.Grouping:
+			return -1
+		case l.Decimal:
+			return '.'
+		default:
+			return r
+		}
+	}, strings.TrimSpace(value))
+}
+
+// normalizeAttr normalizes string attribute values, numbers of other kinds are already formatted
+// by formatAttrValue the way strconv parses them and are returned unchanged
+func (l NumericLocale) normalizeAttr(kind reflect.Kind, value string) string {
+	if kind != reflect.String {
+		return value
+	}
+	return l.normalize(value)
+}
@@ -0,0 +1,74 @@
+package evaluation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+func TestNumericLocale_normalize(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale NumericLocale
+		value  string
+		want   string
+	}{
+		{name: "zero locale", locale: NumericLocale{}, value: "1.234,56", want: "1.234,56"},
+		{name: "european", locale: EuropeanNumericLocale, value: "1.234,56", want: "1234.56"},
+		{name: "european decimal", locale: EuropeanNumericLocale, value: " 3,14 ", want: "3.14"},
+		{name: "english", locale: EnglishNumericLocale, value: "1,234.56", want: "1234.56"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.locale.normalize(tt.value); got != tt.want {
+				t.Errorf("NumericLocale.normalize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluator_WithNumericLocale(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale NumericLocale
+		value  interface{}
+		clause rest.Clause
+		want   bool
+	}{
+		{name: "gt european", locale: EuropeanNumericLocale, value: "1.234,56",
+			clause: rest.Clause{Attribute: "amount", Op: gtOperator, Values: []string{"1000"}}, want: true},
+		{name: "lt european", locale: EuropeanNumericLocale, value: "1.234,56",
+			clause: rest.Clause{Attribute: "amount", Op: ltOperator, Values: []string{"1234.5"}}, want: false},
+		{name: "float_equal european", locale: EuropeanNumericLocale, value: "1.234,56",
+			clause: rest.Clause{Attribute: "amount", Op: floatEqualOperator, Values: []string{"1234.56"}}, want: true},
+		{name: "float_equal without locale", locale: NumericLocale{}, value: "1.234,56",
+			clause: rest.Clause{Attribute: "amount", Op: floatEqualOperator, Values: []string{"1234.56"}}, want: false},
+		{name: "gt english string", locale: EnglishNumericLocale, value: "1,234.56",
+			clause: rest.Clause{Attribute: "amount", Op: gtOperator, Values: []string{"1000"}}, want: true},
+		{name: "float_equal english string", locale: EnglishNumericLocale, value: "3.14",
+			clause: rest.Clause{Attribute: "amount", Op: floatEqualOperator, Values: []string{"3.14"}}, want: true},
+		{name: "gt european float", locale: EuropeanNumericLocale, value: 3.14,
+			clause: rest.Clause{Attribute: "amount", Op: gtOperator, Values: []string{"100"}}, want: false},
+		{name: "lt european float", locale: EuropeanNumericLocale, value: 3.14,
+			clause: rest.Clause{Attribute: "amount", Op: ltOperator, Values: []string{"100"}}, want: true},
+		{name: "float_equal european float", locale: EuropeanNumericLocale, value: 3.14,
+			clause: rest.Clause{Attribute: "amount", Op: floatEqualOperator, Values: []string{"3.14"}}, want: true},
+		{name: "gt english float", locale: EnglishNumericLocale, value: 1234.56,
+			clause: rest.Clause{Attribute: "amount", Op: gtOperator, Values: []string{"1000"}}, want: true},
+		{name: "float_equal english float", locale: EnglishNumericLocale, value: 3.14,
+			clause: rest.Clause{Attribute: "amount", Op: floatEqualOperator, Values: []string{"3.14"}}, want: true},
+		{name: "gte european int", locale: EuropeanNumericLocale, value: 1234,
+			clause: rest.Clause{Attribute: "amount", Op: gteOperator, Values: []string{"1234"}}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := &Target{Identifier: harness, Attributes: &map[string]interface{}{"amount": tt.value}}
+			e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger(), WithNumericLocale(tt.locale))
+			if got := e.newEvaluation(context.Background()).evaluateClause(&tt.clause, target); got != tt.want {
+				t.Errorf("Evaluator.evaluateClause() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
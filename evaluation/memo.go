@@ -0,0 +1,76 @@
+package evaluation
+
+import "github.com/harness/ff-golang-server-sdk/rest"
+
+// evaluationMemo caches flags, segments and prerequisite evaluations resolved while
+// evaluating many flags for the same target so they are only resolved once.
+// All methods are safe to call on a nil memo.
+type evaluationMemo struct {
+	flags         map[string]rest.FeatureConfig
+	segments      map[string]rest.Segment
+	prerequisites map[string]rest.Variation
+}
+
+func newEvaluationMemo(flags []rest.FeatureConfig) *evaluationMemo {
+	memo := &evaluationMemo{
+		flags:         make(map[string]rest.FeatureConfig, len(flags)),
+		segments:      make(map[string]rest.Segment),
+		prerequisites: make(map[string]rest.Variation),
+	}
+	for _, flag := range flags {
+		memo.flags[flag.Feature] = flag
+	}
+	return memo
+}
+
+// forTarget returns a memo sharing the flags and segments of m but with its own prerequisite
+// evaluations, which depend on the target
+func (m *evaluationMemo) forTarget() *evaluationMemo {
+	return &evaluationMemo{
+		flags:         m.flags,
+		segments:      m.segments,
+		prerequisites: make(map[string]rest.Variation),
+	}
+}
+
+func (m *evaluationMemo) flag(identifier string) (rest.FeatureConfig, bool) {
+	if m == nil {
+		return rest.FeatureConfig{}, false
+	}
+	flag, ok := m.flags[identifier]
+	return flag, ok
+}
+
+func (m *evaluationMemo) setFlag(flag rest.FeatureConfig) {
+	if m != nil {
+		m.flags[flag.Feature] = flag
+	}
+}
+
+func (m *evaluationMemo) segment(identifier string) (rest.Segment, bool) {
+	if m == nil {
+		return rest.Segment{}, false
+	}
+	segment, ok := m.segments[identifier]
+	return segment, ok
+}
+
+func (m *evaluationMemo) setSegment(segment rest.Segment) {
+	if m != nil {
+		m.segments[segment.Identifier] = segment
+	}
+}
+
+func (m *evaluationMemo) prerequisite(identifier string) (rest.Variation, bool) {
+	if m == nil {
+		return rest.Variation{}, false
+	}
+	variation, ok := m.prerequisites[identifier]
+	return variation, ok
+}
+
+func (m *evaluationMemo) setPrerequisite(identifier string, variation rest.Variation) {
+	if m != nil {
+		m.prerequisites[identifier] = variation
+	}
+}
@@ -0,0 +1,104 @@
+package evaluation
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/harness/ff-golang-server-sdk/rest"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// ReasonMessages maps reason kinds to text/template messages explaining an evaluation to the
+// target, templates are executed with MessageData. They can be used to localize the messages.
+type ReasonMessages map[EvaluationReasonKind]string
+
+// reasonTemplateCacheSize bounds the number of parsed reason message templates kept in memory
+const reasonTemplateCacheSize = 256
+
+// defaultReasonMessages are the English messages used for reason kinds missing from ReasonMessages
+var defaultReasonMessages = ReasonMessages{
+	ReasonTargetMatch: "You have been selected for {{.Flag}}",
+	ReasonRuleMatch: "{{if .Reason.RolloutWeight}}You are in the {{.Reason.RolloutWeight}}% rollout for {{.Flag}}" +
+		"{{else}}You match the targeting rules of {{.Flag}}{{end}}",
+	ReasonDefault: "{{if .Reason.RolloutWeight}}You are in the {{.Reason.RolloutWeight}}% rollout for {{.Flag}}" +
+		"{{else}}You get the default experience of {{.Flag}}{{end}}",
+	ReasonPrerequisiteFailed: "{{.Flag}} requires {{.Reason.Prerequisite}} which isn't enabled for you",
+	ReasonOff:                "{{.Flag}} is turned off",
+	ReasonError:              "{{.Flag}} is not available right now",
+	ReasonParseError:         "{{.Flag}} is not available right now",
+	ReasonLastKnown:          "You get your last known experience of {{.Flag}}",
+}
+
+// reasonTemplates holds parsed reason message templates keyed by their text, templates which fail
+// to parse are cached with their parse error. Parsed templates are safe to execute concurrently.
+var reasonTemplates, _ = lru.New(reasonTemplateCacheSize)
+
+// DefaultReasonMessages returns a copy of the English messages used for reason kinds missing from
+// ReasonMessages, for example to localize only some of them
+func DefaultReasonMessages() ReasonMessages {
+	messages := make(ReasonMessages, len(defaultReasonMessages))
+	for kind, text := range defaultReasonMessages {
+		messages[kind] = text
+	}
+	return messages
+}
+
+// MessageData is what reason message templates are executed with
+type MessageData struct {
+	Flag      string
+	Variation rest.Variation
+	Reason    EvaluationReason
+}
+
+// EvaluateMessage is like Evaluate but also renders the reason into a message for the target, for
+// example "You are in the 20% rollout for checkout". Messages for reason kinds missing from
+// messages, which may be nil, are taken from DefaultReasonMessages. The message is rendered even
+// when the evaluation fails.
+func (e Evaluator) EvaluateMessage(identifier string, target *Target, kind string,
+	messages ReasonMessages) (string, rest.Variation, error) {
+	variation, reason, err := e.Evaluate(identifier, target, kind)
+	message, renderErr := renderReasonMessage(messages, MessageData{
+		Flag:      identifier,
+		Variation: variation,
+		Reason:    reason,
+	})
+	if err != nil {
+		return message, variation, err
+	}
+	return message, variation, renderErr
+}
+
+func renderReasonMessage(messages ReasonMessages, data MessageData) (string, error) {
+	text, ok := messages[data.Reason.Kind]
+	if !ok {
+		text = defaultReasonMessages[data.Reason.Kind]
+	}
+	tmpl, err := parseReasonMessage(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrInvalidReasonMessage, data.Reason.Kind, err)
+	}
+	var message strings.Builder
+	if err := tmpl.Execute(&message, data); err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrInvalidReasonMessage, data.Reason.Kind, err)
+	}
+	return message.String(), nil
+}
+
+// parseReasonMessage parses the message template reusing previously parsed templates
+func parseReasonMessage(text string) (*template.Template, error) {
+	if cached, ok := reasonTemplates.Get(text); ok {
+		if err, isErr := cached.(error); isErr {
+			return nil, err
+		}
+		tmpl, _ := cached.(*template.Template)
+		return tmpl, nil
+	}
+	tmpl, err := template.New("reason").Parse(text)
+	if err != nil {
+		reasonTemplates.Add(text, err)
+		return nil, err
+	}
+	reasonTemplates.Add(text, tmpl)
+	return tmpl, nil
+}
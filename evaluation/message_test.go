@@ -0,0 +1,109 @@
+package evaluation
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+func TestEvaluator_EvaluateMessage(t *testing.T) {
+	flag := rest.FeatureConfig{
+		Feature:      "checkout",
+		State:        rest.FeatureStateOn,
+		Kind:         "boolean",
+		OffVariation: identifierFalse,
+		DefaultServe: rest.Serve{
+			Distribution: &rest.Distribution{
+				BucketBy: identifier,
+				Variations: []rest.WeightedVariation{
+					{Variation: identifierTrue, Weight: 20},
+					{Variation: identifierFalse, Weight: 80},
+				},
+			},
+		},
+		Variations: boolVariations,
+	}
+	e, _ := NewEvaluator(NewTestRepository(map[string]rest.FeatureConfig{flag.Feature: flag}, nil),
+		nil, logger.NewNoOpLogger())
+
+	// find a target bucketed into the 20% rollout
+	var target *Target
+	for i := 0; target == nil; i++ {
+		candidate := fmt.Sprintf("target%d", i)
+		if getNormalizedNumber(candidate, identifier) <= 20 {
+			target = &Target{Identifier: candidate}
+		}
+	}
+
+	tests := []struct {
+		name     string
+		messages ReasonMessages
+		want     string
+		wantErr  error
+	}{
+		{
+			name: "default english message",
+			want: "You are in the 20% rollout for checkout",
+		},
+		{
+			name:     "localized message",
+			messages: ReasonMessages{ReasonDefault: "Sie sind im {{.Reason.RolloutWeight}}%-Rollout für {{.Flag}}"},
+			want:     "Sie sind im 20%-Rollout für checkout",
+		},
+		{
+			name:     "messages for other kinds fall back to the default",
+			messages: ReasonMessages{ReasonOff: "{{.Flag}} est désactivé"},
+			want:     "You are in the 20% rollout for checkout",
+		},
+		{
+			name:     "invalid template",
+			messages: ReasonMessages{ReasonDefault: "{{.Flag"},
+			wantErr:  ErrInvalidReasonMessage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, variation, err := e.EvaluateMessage(flag.Feature, target, "boolean", tt.messages)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Evaluator.EvaluateMessage() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Evaluator.EvaluateMessage() = %q, want %q", got, tt.want)
+			}
+			if variation.Identifier != identifierTrue {
+				t.Errorf("Evaluator.EvaluateMessage() variation = %v, want %v", variation.Identifier, identifierTrue)
+			}
+		})
+	}
+}
+
+func TestDefaultReasonMessages(t *testing.T) {
+	messages := DefaultReasonMessages()
+	if messages[ReasonOff] != "{{.Flag}} is turned off" {
+		t.Fatalf("DefaultReasonMessages()[ReasonOff] = %q", messages[ReasonOff])
+	}
+	messages[ReasonOff] = "{{.Flag}} est désactivé"
+	if got := DefaultReasonMessages()[ReasonOff]; got != "{{.Flag}} is turned off" {
+		t.Errorf("DefaultReasonMessages()[ReasonOff] = %q after modifying a copy, want the default", got)
+	}
+}
+
+func Test_parseReasonMessage(t *testing.T) {
+	first, err := parseReasonMessage("{{.Flag}} is turned off")
+	if err != nil {
+		t.Fatalf("parseReasonMessage() error = %v", err)
+	}
+	second, _ := parseReasonMessage("{{.Flag}} is turned off")
+	if first != second {
+		t.Errorf("parseReasonMessage() parsed the template again, want the cached template")
+	}
+	if _, err := parseReasonMessage("{{.Flag"); err == nil {
+		t.Errorf("parseReasonMessage() error = nil, want the parse error")
+	}
+	if _, err := parseReasonMessage("{{.Flag"); err == nil {
+		t.Errorf("parseReasonMessage() error = nil for the cached invalid template, want the parse error")
+	}
+}
@@ -0,0 +1,129 @@
+package evaluation
+
+import (
+	"fmt"
+	"sync"
+)
+
+// OperatorFunc matches a target attribute value against the values of a clause using a custom
+// operator
+type OperatorFunc func(attr string, clauseValues []string) bool
+
+// builtinOperators can't be overridden by custom operators
+var builtinOperators = map[string]struct{}{
+	segmentMatchOperator:       {},
+	matchOperator:              {},
+	inOperator:                 {},
+	notInOperator:              {},
+	equalOperator:              {},
+	notEqualOperator:           {},
+	gtOperator:                 {},
+	gteOperator:                {},
+	ltOperator:                 {},
+	lteOperator:                {},
+	startsWithOperator:         {},
+	endsWithOperator:           {},
+	containsOperator:           {},
+	equalSensitiveOperator:     {},
+	globAnyCIOperator:          {},
+	floatEqualOperator:         {},
+	stageInOperator:            {},
+	existsOperator:             {},
+	fuzzyInOperator:            {},
+	hashedEqualOperator:        {},
+	semverGtOperator:           {},
+	semverLtOperator:           {},
+	semverEqualOperator:        {},
+	allowDenyOperator:          {},
+	beforeOperator:             {},
+	afterOperator:              {},
+	ipInCIDROperator:           {},
+	ipInCIDRAnyOperator:        {},
+	attrEqualOperator:          {},
+	attrEqualSensitiveOperator: {},
+	scheduleOperator:           {},
+	startsWithIOperator:        {},
+	endsWithIOperator:          {},
+	containsIOperator:          {},
+	semverSatisfiesOperator:    {},
+	sampledOperator:            {},
+	segmentAndAttrOperator:     {},
+	weightedBucketOperator:     {},
+	inListOperator:             {},
+	notMatchOperator:           {},
+	notContainsOperator:        {},
+}
+
+// negatedOperators maps operators to the operator whose result they invert
+var negatedOperators = map[string]string{
+	notMatchOperator:    matchOperator,
+	notContainsOperator: containsOperator,
+}
+
+// operatorRegistry holds custom operators and is safe for concurrent use
+type operatorRegistry struct {
+	mu        sync.RWMutex
+	operators map[string]OperatorFunc
+}
+
+// defaultOperators holds the operators registered with RegisterOperator, they are consulted by
+// every evaluator after its own operators
+var defaultOperators = &operatorRegistry{operators: make(map[string]OperatorFunc)}
+
+func (r *operatorRegistry) lookup(name string) (OperatorFunc, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	fn, ok := r.operators[name]
+	return fn, ok
+}
+
+func (r *operatorRegistry) register(name string, fn OperatorFunc) error {
+	if name == "" || fn == nil {
+		return fmt.Errorf("%w: operator name and function are required", ErrInvalidOperator)
+	}
+	if _, ok := builtinOperators[name]; ok {
+		return fmt.Errorf("%w: %s is a built in operator", ErrInvalidOperator, name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.operators[name] = fn
+	return nil
+}
+
+// customOperator returns the custom operator registered under name, operators registered with
+// the evaluator take precedence over those registered with RegisterOperator
+func (e Evaluator) customOperator(name string) (OperatorFunc, bool) {
+	if fn, ok := e.operators.lookup(name); ok {
+		return fn, true
+	}
+	return defaultOperators.lookup(name)
+}
+
+// knownOperator reports whether the operator is built in or registered as a custom operator
+func (e Evaluator) knownOperator(name string) bool {
+	if _, ok := builtinOperators[name]; ok {
+		return true
+	}
+	_, ok := e.customOperator(name)
+	return ok
+}
+
+// RegisterOperator registers a custom clause operator for every evaluator, for example a geo
+// distance or tenant tier ranking, which is consulted for operators the SDK doesn't support itself.
+// Built in operators can't be overridden. Operators should be registered before flags are evaluated.
+func RegisterOperator(name string, fn OperatorFunc) error {
+	return defaultOperators.register(name, fn)
+}
+
+// RegisterOperator registers a custom clause operator, for example a CIDR range match, which is
+// consulted for operators the SDK doesn't support itself. Built in operators can't be overridden.
+// Operators should be registered before the evaluator is used.
+func (e *Evaluator) RegisterOperator(name string, fn OperatorFunc) error {
+	if e.operators == nil {
+		e.operators = &operatorRegistry{operators: make(map[string]OperatorFunc)}
+	}
+	return e.operators.register(name, fn)
+}
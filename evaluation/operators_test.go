@@ -0,0 +1,122 @@
+package evaluation
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+func cidrMatch(attr string, clauseValues []string) bool {
+	ip := net.ParseIP(attr)
+	if ip == nil {
+		return false
+	}
+	for _, value := range clauseValues {
+		if _, network, err := net.ParseCIDR(value); err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEvaluator_RegisterOperator(t *testing.T) {
+	flag := rest.FeatureConfig{
+		Feature:      "internalNetwork",
+		State:        rest.FeatureStateOn,
+		Kind:         "boolean",
+		OffVariation: identifierFalse,
+		Rules: &[]rest.ServingRule{
+			{
+				Clauses: []rest.Clause{{Attribute: "ip", Op: "cidr_match", Values: []string{"10.0.0.0/8", "192.168.0.0/16"}}},
+				Serve:   rest.Serve{Variation: &identifierTrue},
+			},
+		},
+		DefaultServe: rest.Serve{Variation: &identifierFalse},
+		Variations:   boolVariations,
+	}
+	e, _ := NewEvaluator(NewTestRepository(map[string]rest.FeatureConfig{flag.Feature: flag}, nil),
+		nil, logger.NewNoOpLogger())
+	internal := &Target{Identifier: harness, Attributes: &map[string]interface{}{"ip": "10.1.2.3"}}
+	external := &Target{Identifier: harness, Attributes: &map[string]interface{}{"ip": "8.8.8.8"}}
+
+	if e.BoolVariation(flag.Feature, internal, false) {
+		t.Errorf("Evaluator.BoolVariation() = true, want false before the operator is registered")
+	}
+
+	if err := e.RegisterOperator("cidr_match", cidrMatch); err != nil {
+		t.Fatalf("Evaluator.RegisterOperator() error = %v", err)
+	}
+	if !e.BoolVariation(flag.Feature, internal, false) {
+		t.Errorf("Evaluator.BoolVariation() = false, want true for an internal ip")
+	}
+	if e.BoolVariation(flag.Feature, external, false) {
+		t.Errorf("Evaluator.BoolVariation() = true, want false for an external ip")
+	}
+
+	if err := e.RegisterOperator(equalOperator, cidrMatch); !errors.Is(err, ErrInvalidOperator) {
+		t.Errorf("Evaluator.RegisterOperator() error = %v, want %v when overriding a built in operator", err, ErrInvalidOperator)
+	}
+	if err := e.RegisterOperator("nil_operator", nil); !errors.Is(err, ErrInvalidOperator) {
+		t.Errorf("Evaluator.RegisterOperator() error = %v, want %v for a nil function", err, ErrInvalidOperator)
+	}
+}
+
+func TestRegisterOperator(t *testing.T) {
+	tiers := map[string]int{"free": 0, "team": 1, "enterprise": 2}
+	tierAtLeast := func(attr string, clauseValues []string) bool {
+		tier, ok := tiers[attr]
+		return ok && len(clauseValues) > 0 && tier >= tiers[clauseValues[0]]
+	}
+	flag := rest.FeatureConfig{
+		Feature:      "auditLog",
+		State:        rest.FeatureStateOn,
+		Kind:         "boolean",
+		OffVariation: identifierFalse,
+		Rules: &[]rest.ServingRule{
+			{
+				Clauses: []rest.Clause{{Attribute: "tier", Op: "tier_at_least", Values: []string{"team"}}},
+				Serve:   rest.Serve{Variation: &identifierTrue},
+			},
+		},
+		DefaultServe: rest.Serve{Variation: &identifierFalse},
+		Variations:   boolVariations,
+	}
+	e, _ := NewEvaluator(NewTestRepository(map[string]rest.FeatureConfig{flag.Feature: flag}, nil),
+		nil, logger.NewNoOpLogger())
+	enterprise := &Target{Identifier: harness, Attributes: &map[string]interface{}{"tier": "enterprise"}}
+	free := &Target{Identifier: harness, Attributes: &map[string]interface{}{"tier": "free"}}
+
+	if err := RegisterOperator("tier_at_least", tierAtLeast); err != nil {
+		t.Fatalf("RegisterOperator() error = %v", err)
+	}
+	defer func() {
+		defaultOperators.mu.Lock()
+		delete(defaultOperators.operators, "tier_at_least")
+		defaultOperators.mu.Unlock()
+	}()
+
+	if !e.BoolVariation(flag.Feature, enterprise, false) {
+		t.Errorf("Evaluator.BoolVariation() = false, want true for an enterprise tier")
+	}
+	if e.BoolVariation(flag.Feature, free, false) {
+		t.Errorf("Evaluator.BoolVariation() = true, want false for a free tier")
+	}
+
+	never := func(string, []string) bool { return false }
+	if err := e.RegisterOperator("tier_at_least", never); err != nil {
+		t.Fatalf("Evaluator.RegisterOperator() error = %v", err)
+	}
+	if e.BoolVariation(flag.Feature, enterprise, false) {
+		t.Errorf("Evaluator.BoolVariation() = true, want the evaluator's own operator to take precedence")
+	}
+
+	if err := RegisterOperator(inOperator, tierAtLeast); !errors.Is(err, ErrInvalidOperator) {
+		t.Errorf("RegisterOperator() error = %v, want %v when overriding a built in operator", err, ErrInvalidOperator)
+	}
+	if err := RegisterOperator("", tierAtLeast); !errors.Is(err, ErrInvalidOperator) {
+		t.Errorf("RegisterOperator() error = %v, want %v for an empty name", err, ErrInvalidOperator)
+	}
+}
@@ -0,0 +1,69 @@
+package evaluation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// VariationParser parses the value of a variation of a flag kind
+type VariationParser func(value string) (interface{}, error)
+
+// builtinVariationParsers parse the values of the built-in flag kinds the same way the typed
+// variation methods do, they can't be replaced by registered parsers
+var builtinVariationParsers = map[string]VariationParser{
+	"boolean": func(value string) (interface{}, error) {
+		return parseBool(value)
+	},
+	"string": func(value string) (interface{}, error) {
+		return value, nil
+	},
+	"int": func(value string) (interface{}, error) {
+		return strconv.Atoi(value)
+	},
+	"number": func(value string) (interface{}, error) {
+		return strconv.ParseFloat(value, 64)
+	},
+	"json": func(value string) (interface{}, error) {
+		val := make(map[string]interface{})
+		err := json.Unmarshal([]byte(value), &val)
+		return val, err
+	},
+}
+
+func (e Evaluator) variationParser(kind string) (VariationParser, bool) {
+	if parser, ok := builtinVariationParsers[kind]; ok {
+		return parser, true
+	}
+	parser, ok := e.variationParsers[kind]
+	return parser, ok
+}
+
+// VariationValue evaluates the flag for the target whatever its kind and returns the served value
+// parsed by the parser of the flag kind, together with the kind. Custom kinds need a parser
+// registered with WithVariationParser. When the flag is turned off the value of its off variation
+// is returned together with ErrFlagDisabled.
+func (e Evaluator) VariationValue(identifier string, target *Target) (interface{}, string, error) {
+	state := e.newEvaluation(context.Background())
+	flag, err := state.flag(identifier)
+	if err != nil {
+		return nil, "", err
+	}
+	kind := string(flag.Kind)
+	parser, ok := e.variationParser(kind)
+	if !ok {
+		return nil, kind, fmt.Errorf("%w: %s", ErrVariationParserMissing, kind)
+	}
+
+	// the flag was already retrieved and is memoized so the evaluation reuses it
+	variation, reason, err := state.evaluateWithReason(identifier, target, kind)
+	if err != nil {
+		return nil, kind, err
+	}
+	value, err := parser(variation.Value)
+	if err != nil {
+		return nil, kind, fmt.Errorf("%w: %s: %v", ErrInvalidVariationValue, identifier, err)
+	}
+	return value, kind, disabledErr(identifier, reason)
+}
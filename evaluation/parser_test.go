@@ -0,0 +1,96 @@
+package evaluation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+func TestEvaluator_VariationValue(t *testing.T) {
+	timeout, invalid := "1m30s", "soon"
+	durationFlag := func(feature, value string) rest.FeatureConfig {
+		return rest.FeatureConfig{
+			Feature:      feature,
+			State:        rest.FeatureStateOn,
+			Kind:         "duration",
+			DefaultServe: rest.Serve{Variation: &value},
+			Variations:   []rest.Variation{{Identifier: value, Value: value}},
+		}
+	}
+	flags := map[string]rest.FeatureConfig{
+		"timeout": durationFlag("timeout", timeout),
+		"invalid": durationFlag("invalid", invalid),
+	}
+	for identifier, flag := range testRepo.flags {
+		flags[identifier] = flag
+	}
+	repo := NewTestRepository(flags, testRepo.segments)
+	parseDuration := func(value string) (interface{}, error) {
+		return time.ParseDuration(value)
+	}
+	target := &Target{Identifier: harness}
+
+	tests := []struct {
+		name       string
+		options    []EvaluatorOption
+		identifier string
+		want       interface{}
+		wantKind   string
+		wantErr    error
+	}{
+		{
+			name:       "registered parser",
+			options:    []EvaluatorOption{WithVariationParser("duration", parseDuration)},
+			identifier: "timeout",
+			want:       90 * time.Second,
+			wantKind:   "duration",
+		},
+		{
+			name:       "registered parser failing",
+			options:    []EvaluatorOption{WithVariationParser("duration", parseDuration)},
+			identifier: "invalid",
+			wantKind:   "duration",
+			wantErr:    ErrInvalidVariationValue,
+		},
+		{
+			name:       "no registered parser",
+			identifier: "timeout",
+			wantKind:   "duration",
+			wantErr:    ErrVariationParserMissing,
+		},
+		{
+			name:       "built-in boolean kind",
+			identifier: simple,
+			want:       true,
+			wantKind:   "boolean",
+		},
+		{
+			name: "built-in kinds can't be replaced",
+			options: []EvaluatorOption{WithVariationParser("json", func(value string) (interface{}, error) {
+				return value, nil
+			})},
+			identifier: org,
+			want:       map[string]interface{}{"org": harness2},
+			wantKind:   "json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := NewEvaluator(repo, nil, logger.NewNoOpLogger(), tt.options...)
+			got, kind, err := e.VariationValue(tt.identifier, target)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Evaluator.VariationValue() error = %v, want %v", err, tt.wantErr)
+			}
+			if kind != tt.wantKind {
+				t.Errorf("Evaluator.VariationValue() kind = %v, want %v", kind, tt.wantKind)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluator.VariationValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
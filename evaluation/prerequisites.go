@@ -0,0 +1,116 @@
+package evaluation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+// PrerequisiteFailure describes why a prerequisite wasn't satisfied
+type PrerequisiteFailure string
+
+const (
+	// PrerequisiteUnmet the prerequisite flag served a variation the parent doesn't require, for
+	// example because it is turned off
+	PrerequisiteUnmet PrerequisiteFailure = "UNMET"
+	// PrerequisiteUnavailable the prerequisite flag couldn't be retrieved or evaluated, such
+	// prerequisites are treated as satisfied unless PrerequisiteErrorFail is configured
+	PrerequisiteUnavailable PrerequisiteFailure = "UNAVAILABLE"
+	// PrerequisiteCycle the prerequisite flag requires, directly or not, the flag itself
+	PrerequisiteCycle PrerequisiteFailure = "CYCLE"
+	// PrerequisiteTooDeep the prerequisite flag is nested deeper than the maximum prerequisite depth
+	PrerequisiteTooDeep PrerequisiteFailure = "TOO_DEEP"
+)
+
+// PrerequisiteError is returned together with the off variation of a flag whose prerequisites
+// couldn't be checked because they form a cycle or are nested too deep. It wraps ErrPrerequisiteCycle
+// or ErrPrerequisiteDepth.
+type PrerequisiteError struct {
+	// Flag is the evaluated flag
+	Flag string
+	// Prerequisite is the prerequisite of Flag whose check failed
+	Prerequisite string
+	Failure      PrerequisiteFailure
+}
+
+func (e PrerequisiteError) Error() string {
+	return fmt.Sprintf("%v: flag %s prerequisite %s", e.Unwrap(), e.Flag, e.Prerequisite)
+}
+
+// Unwrap returns the sentinel error matching the failure
+func (e PrerequisiteError) Unwrap() error {
+	if e.Failure == PrerequisiteTooDeep {
+		return ErrPrerequisiteDepth
+	}
+	return ErrPrerequisiteCycle
+}
+
+// prerequisiteErr returns a PrerequisiteError when the reason is a prerequisite cycle or a too deeply
+// nested prerequisite, misconfigurations which are reported as errors rather than plain failures
+func prerequisiteErr(identifier string, reason EvaluationReason) error {
+	if reason.Kind != ReasonPrerequisiteFailed {
+		return nil
+	}
+	if reason.PrerequisiteFailure != PrerequisiteCycle && reason.PrerequisiteFailure != PrerequisiteTooDeep {
+		return nil
+	}
+	return PrerequisiteError{Flag: identifier, Prerequisite: reason.Prerequisite, Failure: reason.PrerequisiteFailure}
+}
+
+// prerequisiteCheck is the outcome of checking the prerequisites of a flag, feature and failure
+// name the prerequisite which decided it unless every prerequisite was met
+type prerequisiteCheck struct {
+	satisfied bool
+	feature   string
+	failure   PrerequisiteFailure
+}
+
+// PrerequisiteResult describes a prerequisite checked while evaluating a flag
+type PrerequisiteResult struct {
+	// Parent is the flag requiring the prerequisite
+	Parent string
+	// Feature is the prerequisite flag
+	Feature string
+	// Variation is the variation the prerequisite flag evaluated to
+	Variation rest.Variation
+	// Satisfied is true when Variation is one of the variations required by Parent
+	Satisfied bool
+}
+
+// prerequisiteChain collects the prerequisites checked by a single evaluation in the order they
+// were checked. All methods are safe to call on a nil chain.
+type prerequisiteChain struct {
+	results []PrerequisiteResult
+}
+
+func (c *prerequisiteChain) record(parent, feature string, variation rest.Variation, satisfied bool) {
+	if c == nil {
+		return
+	}
+	c.results = append(c.results, PrerequisiteResult{
+		Parent:    parent,
+		Feature:   feature,
+		Variation: variation,
+		Satisfied: satisfied,
+	})
+}
+
+// EvaluateWithPrerequisites evaluates the flag for the target without any post evaluation processing
+// and returns the served variation together with every prerequisite checked on the way, in order.
+// Checking stops at the first unsatisfied prerequisite, which is the link that blocked serving.
+func (e Evaluator) EvaluateWithPrerequisites(identifier string, target *Target) (rest.Variation,
+	[]PrerequisiteResult, error) {
+	state := e.newEvaluation(context.Background())
+	flag, err := state.flag(identifier)
+	if err != nil {
+		return rest.Variation{}, nil, err
+	}
+
+	state.prerequisites = &prerequisiteChain{}
+	variation, err := state.evaluateFeature(flag, target)
+	if err != nil {
+		return rest.Variation{}, state.prerequisites.results, err
+	}
+	return variation, state.prerequisites.results, nil
+}
@@ -0,0 +1,233 @@
+package evaluation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+func TestEvaluator_EvaluateWithPrerequisites(t *testing.T) {
+	leafOn := rest.FeatureConfig{
+		Feature:      "leaf",
+		State:        rest.FeatureStateOn,
+		Kind:         "boolean",
+		OffVariation: identifierFalse,
+		DefaultServe: rest.Serve{Variation: &identifierTrue},
+		Variations:   boolVariations,
+	}
+	leafOff := leafOn
+	leafOff.State = rest.FeatureStateOff
+
+	tests := []struct {
+		name          string
+		leaf          rest.FeatureConfig
+		want          rest.Variation
+		prerequisites []PrerequisiteResult
+	}{
+		{
+			name: "both links satisfied",
+			leaf: leafOn,
+			want: boolVariations[0],
+			prerequisites: []PrerequisiteResult{
+				{Parent: "root", Feature: "middle", Variation: boolVariations[0], Satisfied: true},
+				{Parent: "middle", Feature: "leaf", Variation: boolVariations[0], Satisfied: true},
+			},
+		},
+		{
+			name: "second link blocks serving",
+			leaf: leafOff,
+			want: boolVariations[1],
+			prerequisites: []PrerequisiteResult{
+				{Parent: "root", Feature: "middle", Variation: boolVariations[0], Satisfied: true},
+				{Parent: "middle", Feature: "leaf", Variation: boolVariations[1], Satisfied: false},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewTestRepository(map[string]rest.FeatureConfig{
+				"root":   cyclicPrerequisiteFlag("root", "middle"),
+				"middle": cyclicPrerequisiteFlag("middle", "leaf"),
+				"leaf":   tt.leaf,
+			}, nil)
+			e, _ := NewEvaluator(repo, nil, logger.NewNoOpLogger())
+
+			got, prerequisites, err := e.EvaluateWithPrerequisites("root", &Target{Identifier: harness})
+			if err != nil {
+				t.Fatalf("Evaluator.EvaluateWithPrerequisites() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluator.EvaluateWithPrerequisites() variation = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(prerequisites, tt.prerequisites) {
+				t.Errorf("Evaluator.EvaluateWithPrerequisites() prerequisites = %v, want %v", prerequisites, tt.prerequisites)
+			}
+		})
+	}
+}
+
+func TestEvaluator_EvaluatePrerequisiteFailure(t *testing.T) {
+	offFlag := cyclicPrerequisiteFlag("offPrereq", "")
+	offFlag.State = rest.FeatureStateOff
+	offFlag.Prerequisites = nil
+	flags := map[string]rest.FeatureConfig{
+		"requiresOff":     cyclicPrerequisiteFlag("requiresOff", offFlag.Feature),
+		"requiresMissing": cyclicPrerequisiteFlag("requiresMissing", "missingPrereq"),
+		offFlag.Feature:   offFlag,
+	}
+	e, _ := NewEvaluator(NewTestRepository(flags, nil), nil, logger.NewNoOpLogger())
+	target := &Target{Identifier: harness}
+
+	tests := []struct {
+		name          string
+		identifier    string
+		wantVariation string
+		wantKind      EvaluationReasonKind
+		wantFailure   PrerequisiteFailure
+	}{
+		{name: "prerequisite is off", identifier: "requiresOff", wantVariation: identifierFalse,
+			wantKind: ReasonPrerequisiteFailed, wantFailure: PrerequisiteUnmet},
+		{name: "prerequisite can't be retrieved", identifier: "requiresMissing", wantVariation: identifierTrue,
+			wantKind: ReasonDefault, wantFailure: PrerequisiteUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variation, reason, err := e.Evaluate(tt.identifier, target, "boolean")
+			if err != nil {
+				t.Fatalf("Evaluator.Evaluate() error = %v", err)
+			}
+			if variation.Identifier != tt.wantVariation {
+				t.Errorf("Evaluator.Evaluate() variation = %v, want %v", variation.Identifier, tt.wantVariation)
+			}
+			if reason.Kind != tt.wantKind || reason.PrerequisiteFailure != tt.wantFailure {
+				t.Errorf("Evaluator.Evaluate() reason = %+v, want %v with %v", reason, tt.wantKind, tt.wantFailure)
+			}
+			if want := flags[tt.identifier].Prerequisites; reason.Prerequisite != (*want)[0].Feature {
+				t.Errorf("Evaluator.Evaluate() reason prerequisite = %v, want %v", reason.Prerequisite, (*want)[0].Feature)
+			}
+		})
+	}
+}
+
+func TestEvaluator_WithPrerequisiteErrorPolicy(t *testing.T) {
+	// broken has an empty default serve so it fails to evaluate
+	broken := rest.FeatureConfig{
+		Feature:    "broken",
+		State:      rest.FeatureStateOn,
+		Kind:       "boolean",
+		Variations: boolVariations,
+	}
+	flags := map[string]rest.FeatureConfig{
+		"requiresBroken":  cyclicPrerequisiteFlag("requiresBroken", broken.Feature),
+		"requiresMissing": cyclicPrerequisiteFlag("requiresMissing", "missingPrereq"),
+		broken.Feature:    broken,
+	}
+	target := &Target{Identifier: harness}
+
+	tests := []struct {
+		name          string
+		identifier    string
+		policy        PrerequisiteErrorPolicy
+		wantVariation string
+		wantKind      EvaluationReasonKind
+	}{
+		{name: "pass on evaluation error", identifier: "requiresBroken", policy: PrerequisiteErrorPass,
+			wantVariation: identifierTrue, wantKind: ReasonDefault},
+		{name: "fail on evaluation error", identifier: "requiresBroken", policy: PrerequisiteErrorFail,
+			wantVariation: identifierFalse, wantKind: ReasonPrerequisiteFailed},
+		{name: "pass on retrieval error", identifier: "requiresMissing", policy: PrerequisiteErrorPass,
+			wantVariation: identifierTrue, wantKind: ReasonDefault},
+		{name: "fail on retrieval error", identifier: "requiresMissing", policy: PrerequisiteErrorFail,
+			wantVariation: identifierFalse, wantKind: ReasonPrerequisiteFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := NewEvaluator(NewTestRepository(flags, nil), nil, logger.NewNoOpLogger(),
+				WithPrerequisiteErrorPolicy(tt.policy))
+			variation, reason, err := e.Evaluate(tt.identifier, target, "boolean")
+			if err != nil {
+				t.Fatalf("Evaluator.Evaluate() error = %v", err)
+			}
+			if variation.Identifier != tt.wantVariation || reason.Kind != tt.wantKind {
+				t.Errorf("Evaluator.Evaluate() = %v, %v, want %v, %v", variation.Identifier, reason.Kind,
+					tt.wantVariation, tt.wantKind)
+			}
+			if reason.PrerequisiteFailure != PrerequisiteUnavailable {
+				t.Errorf("Evaluator.Evaluate() reason failure = %v, want %v", reason.PrerequisiteFailure,
+					PrerequisiteUnavailable)
+			}
+		})
+	}
+}
+
+// prerequisiteLadder returns flags step0 to stepN where each step requires the next one to be true
+func prerequisiteLadder(n int) map[string]rest.FeatureConfig {
+	flags := make(map[string]rest.FeatureConfig, n+1)
+	for i := 0; i < n; i++ {
+		flags[fmt.Sprintf("step%d", i)] = cyclicPrerequisiteFlag(fmt.Sprintf("step%d", i), fmt.Sprintf("step%d", i+1))
+	}
+	last := fmt.Sprintf("step%d", n)
+	flags[last] = rest.FeatureConfig{
+		Feature:      last,
+		State:        rest.FeatureStateOn,
+		Kind:         "boolean",
+		OffVariation: identifierFalse,
+		DefaultServe: rest.Serve{Variation: &identifierTrue},
+		Variations:   boolVariations,
+	}
+	return flags
+}
+
+func TestEvaluator_prerequisiteCycleAndDepth(t *testing.T) {
+	cycle := NewTestRepository(map[string]rest.FeatureConfig{
+		"cycleA": cyclicPrerequisiteFlag("cycleA", "cycleB"),
+		"cycleB": cyclicPrerequisiteFlag("cycleB", "cycleA"),
+	}, nil)
+	ladder := NewTestRepository(prerequisiteLadder(4), nil)
+
+	tests := []struct {
+		name        string
+		query       Query
+		options     []EvaluatorOption
+		identifier  string
+		want        bool
+		wantFailure PrerequisiteFailure
+		wantErr     error
+	}{
+		{name: "cycle serves the off variation", query: cycle, identifier: "cycleA",
+			wantFailure: PrerequisiteCycle, wantErr: ErrPrerequisiteCycle},
+		{name: "prerequisites within the default depth", query: ladder, identifier: "step0", want: true},
+		{name: "prerequisites as deep as the maximum depth", query: ladder, identifier: "step0",
+			options: []EvaluatorOption{WithMaxPrerequisiteDepth(4)}, want: true},
+		{name: "prerequisites deeper than the maximum depth", query: ladder, identifier: "step0",
+			options: []EvaluatorOption{WithMaxPrerequisiteDepth(3)}, wantFailure: PrerequisiteTooDeep,
+			wantErr: ErrPrerequisiteDepth},
+		{name: "non positive maximum depth uses the default", query: ladder, identifier: "step0",
+			options: []EvaluatorOption{WithMaxPrerequisiteDepth(0)}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := NewEvaluator(tt.query, nil, logger.NewNoOpLogger(), tt.options...)
+			got, detail := e.BoolVariationDetail(tt.identifier, &Target{Identifier: harness}, !tt.want)
+			if got != tt.want {
+				t.Errorf("Evaluator.BoolVariationDetail() = %v, want %v", got, tt.want)
+			}
+			if detail.Reason.PrerequisiteFailure != tt.wantFailure {
+				t.Errorf("Evaluator.BoolVariationDetail() failure = %q, want %q", detail.Reason.PrerequisiteFailure,
+					tt.wantFailure)
+			}
+			if !errors.Is(detail.Error, tt.wantErr) {
+				t.Errorf("Evaluator.BoolVariationDetail() error = %v, want %v", detail.Error, tt.wantErr)
+			}
+			var prerequisiteErr PrerequisiteError
+			if tt.wantErr != nil && (!errors.As(detail.Error, &prerequisiteErr) || prerequisiteErr.Flag != tt.identifier) {
+				t.Errorf("Evaluator.BoolVariationDetail() error = %#v, want a PrerequisiteError for %s",
+					detail.Error, tt.identifier)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package evaluation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+// EvaluationReasonKind describes which step of the evaluation decided the served variation
+type EvaluationReasonKind string
+
+const (
+	// ReasonTargetMatch the target was explicitly mapped to the variation
+	ReasonTargetMatch EvaluationReasonKind = "TARGET_MATCH"
+	// ReasonRuleMatch a serving rule matched the target
+	ReasonRuleMatch EvaluationReasonKind = "RULE_MATCH"
+	// ReasonPrerequisiteFailed a prerequisite wasn't met so the off variation was served
+	ReasonPrerequisiteFailed EvaluationReasonKind = "PREREQUISITE_FAILED"
+	// ReasonDefault no target mapping or rule matched so the default serve was used
+	ReasonDefault EvaluationReasonKind = "DEFAULT"
+	// ReasonOff the flag is turned off so the off variation was served
+	ReasonOff EvaluationReasonKind = "OFF"
+	// ReasonError the flag couldn't be evaluated
+	ReasonError EvaluationReasonKind = "ERROR"
+	// ReasonLastKnown the flag couldn't be retrieved so the variation last served to the target was
+	// served again
+	ReasonLastKnown EvaluationReasonKind = "LAST_KNOWN"
+	// ReasonParseError the served variation value couldn't be accepted, for example because it
+	// exceeds the configured size limit
+	ReasonParseError EvaluationReasonKind = "PARSE_ERROR"
+)
+
+// EvaluationReason records the decision path of an evaluation
+type EvaluationReason struct {
+	Kind EvaluationReasonKind
+	// RuleIndex is the position of the matched rule in priority order, -1 unless Kind is ReasonRuleMatch
+	RuleIndex int
+	// RulePriority is the priority of the matched rule
+	RulePriority int
+	// RuleID is the identifier of the matched rule
+	RuleID string
+	// Prerequisite is the identifier of the prerequisite flag which wasn't met, or which couldn't
+	// be resolved and was treated as met
+	Prerequisite string
+	// PrerequisiteFailure tells why Prerequisite wasn't met
+	PrerequisiteFailure PrerequisiteFailure
+	// SegmentID and SegmentName identify the segment which included the target when a
+	// segmentMatch clause drove the matched rule
+	SegmentID   string
+	SegmentName string
+	// RolloutWeight is the percentage of targets served the variation when it was picked by a
+	// percentage rollout of the matched rule or default serve, 0 otherwise
+	RolloutWeight int
+	// RolloutVariations are all the variations of that percentage rollout with their weights, the
+	// served one included
+	RolloutVariations []rest.WeightedVariation
+	// FlagVersion is the version of the evaluated flag configuration, callers caching variations can
+	// compare it to tell when to invalidate. It is 0 when the configuration has no version.
+	FlagVersion int64
+}
+
+func newEvaluationReason(kind EvaluationReasonKind) EvaluationReason {
+	return EvaluationReason{Kind: kind, RuleIndex: -1}
+}
+
+// set replaces the recorded reason, it is a no-op when reasons are not collected
+func (r *EvaluationReason) set(reason EvaluationReason) {
+	if r != nil {
+		*r = reason
+	}
+}
+
+// setPrerequisite records a prerequisite which couldn't be resolved on the reason of an otherwise
+// successful evaluation, it is a no-op when reasons are not collected
+func (r *EvaluationReason) setPrerequisite(feature string, failure PrerequisiteFailure) {
+	if r != nil {
+		r.Prerequisite = feature
+		r.PrerequisiteFailure = failure
+	}
+}
+
+// setFlagVersion records the version of the evaluated flag configuration, it is a no-op when reasons
+// are not collected
+func (r *EvaluationReason) setFlagVersion(version *int64) {
+	if r != nil && version != nil {
+		r.FlagVersion = *version
+	}
+}
+
+// setRollout records the percentage rollout the variation was picked from
+func (r *EvaluationReason) setRollout(distribution *rest.Distribution, variation string) {
+	r.RolloutWeight = distributionWeight(distribution, variation)
+	r.RolloutVariations = append([]rest.WeightedVariation(nil), distribution.Variations...)
+}
+
+// String describes the reason, for example "RULE_MATCH rule us-users (priority 2)"
+func (r EvaluationReason) String() string {
+	parts := []string{string(r.Kind)}
+	if r.Kind == ReasonRuleMatch {
+		parts = append(parts, fmt.Sprintf("rule %s (priority %d)", r.RuleID, r.RulePriority))
+	}
+	if r.RolloutWeight > 0 {
+		parts = append(parts, fmt.Sprintf("rollout %d%%", r.RolloutWeight))
+	}
+	if r.SegmentID != "" {
+		parts = append(parts, fmt.Sprintf("segment %s (%s)", r.SegmentID, r.SegmentName))
+	}
+	if r.Prerequisite != "" {
+		parts = append(parts, "prerequisite "+r.Prerequisite)
+		if r.PrerequisiteFailure != "" {
+			parts = append(parts, strings.ToLower(string(r.PrerequisiteFailure)))
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,41 @@
+package evaluation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	// flagReferencePrefix marks string variation values which are resolved by evaluating another
+	// string flag for the same target, for example "@flag:checkoutTheme"
+	flagReferencePrefix = "@flag:"
+	// maxFlagReferenceDepth limits how many flags a string variation value may be resolved through
+	maxFlagReferenceDepth = 8
+)
+
+// resolveFlagReference follows the flag references of the value served by the flag identifier
+// until it resolves to a plain value, failing on cycles and chains deeper than maxFlagReferenceDepth
+func (e Evaluator) resolveFlagReference(ctx context.Context, identifier string, target *Target,
+	value string) (string, error) {
+	visited := map[string]struct{}{identifier: {}}
+	for depth := 0; strings.HasPrefix(value, flagReferencePrefix); depth++ {
+		referenced := strings.TrimPrefix(value, flagReferencePrefix)
+		if depth == maxFlagReferenceDepth {
+			return "", fmt.Errorf("%w: %s references more than %d flags", ErrFlagReference, identifier,
+				maxFlagReferenceDepth)
+		}
+		if _, ok := visited[referenced]; ok {
+			return "", fmt.Errorf("%w: %s references %s which is already being resolved", ErrFlagReference,
+				identifier, referenced)
+		}
+		visited[referenced] = struct{}{}
+
+		variation, _, err := e.EvaluateCtx(ctx, referenced, target, "string")
+		if err != nil {
+			return "", fmt.Errorf("%w: %s references %s: %v", ErrFlagReference, identifier, referenced, err)
+		}
+		value = variation.Value
+	}
+	return value, nil
+}
@@ -0,0 +1,68 @@
+package evaluation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+func TestEvaluator_StringVariationFlagReference(t *testing.T) {
+	stringFlag := func(identifier, value string) rest.FeatureConfig {
+		return rest.FeatureConfig{
+			Feature:      identifier,
+			State:        rest.FeatureStateOn,
+			Kind:         "string",
+			DefaultServe: rest.Serve{Variation: &identifier},
+			Variations:   []rest.Variation{{Identifier: identifier, Value: value}},
+		}
+	}
+	flags := map[string]rest.FeatureConfig{
+		"a":       stringFlag("a", "@flag:b"),
+		"b":       stringFlag("b", darktheme),
+		"self":    stringFlag("self", "@flag:self"),
+		"cycleA":  stringFlag("cycleA", "@flag:cycleB"),
+		"cycleB":  stringFlag("cycleB", "@flag:cycleA"),
+		"missing": stringFlag("missing", "@flag:flagNotFound"),
+		"boolean": stringFlag("boolean", "@flag:"+simple),
+		simple:    testRepo.flags[simple],
+	}
+	// a chain of references one longer than allowed
+	for i := 0; i <= maxFlagReferenceDepth; i++ {
+		identifier := "chain" + string(rune('a'+i))
+		flags[identifier] = stringFlag(identifier, "@flag:chain"+string(rune('a'+i+1)))
+	}
+	last := "chain" + string(rune('a'+maxFlagReferenceDepth+1))
+	flags[last] = stringFlag(last, lighttheme)
+
+	e, _ := NewEvaluator(NewTestRepository(flags, nil), nil, logger.NewNoOpLogger())
+	target := &Target{Identifier: harness}
+
+	tests := []struct {
+		name       string
+		identifier string
+		want       string
+		wantErr    error
+	}{
+		{name: "resolves through another flag", identifier: "a", want: darktheme},
+		{name: "plain value", identifier: "b", want: darktheme},
+		{name: "self reference", identifier: "self", want: "default", wantErr: ErrFlagReference},
+		{name: "cycle", identifier: "cycleA", want: "default", wantErr: ErrFlagReference},
+		{name: "missing flag", identifier: "missing", want: "default", wantErr: ErrFlagReference},
+		{name: "flag of another kind", identifier: "boolean", want: "default", wantErr: ErrFlagReference},
+		{name: "too deep", identifier: "chaina", want: "default", wantErr: ErrFlagReference},
+		{name: "within the depth limit", identifier: "chainb", want: lighttheme},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := e.StringVariationWithErr(tt.identifier, target, "default")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Evaluator.StringVariationWithErr() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Evaluator.StringVariationWithErr() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
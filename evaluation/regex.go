@@ -0,0 +1,61 @@
+package evaluation
+
+import (
+	"regexp"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// regexCacheSize bounds the number of compiled match operator patterns kept in memory
+const regexCacheSize = 1024
+
+// regexCache holds compiled match operator patterns keyed by pattern, patterns which fail to
+// compile are cached with their compile error so they aren't recompiled either. All methods are
+// safe to call on a nil regexCache, patterns are then compiled on every call.
+type regexCache struct {
+	patterns *lru.Cache
+}
+
+// newRegexCache creates an empty regexCache, lru.New only fails for a non positive size
+func newRegexCache() *regexCache {
+	patterns, _ := lru.New(regexCacheSize)
+	return &regexCache{patterns: patterns}
+}
+
+// compile compiles the pattern reusing previously compiled patterns
+func (c *regexCache) compile(pattern string) (*regexp.Regexp, error) {
+	if c == nil {
+		return regexp.Compile(pattern)
+	}
+	if cached, ok := c.patterns.Get(pattern); ok {
+		if err, isErr := cached.(error); isErr {
+			return nil, err
+		}
+		re, _ := cached.(*regexp.Regexp)
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		c.patterns.Add(pattern, err)
+		return nil, err
+	}
+	c.patterns.Add(pattern, re)
+	return re, nil
+}
+
+// match reports whether value matches the pattern, invalid patterns never match and
+// return the compile error
+func (c *regexCache) match(pattern, value string) (bool, error) {
+	re, err := c.compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(value), nil
+}
+
+// evict drops the compiled pattern
+func (c *regexCache) evict(pattern string) {
+	if c != nil {
+		c.patterns.Remove(pattern)
+	}
+}
@@ -0,0 +1,135 @@
+package evaluation
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+func Test_regexCache_match(t *testing.T) {
+	cache := newRegexCache()
+	tests := []struct {
+		name    string
+		cache   *regexCache
+		pattern string
+		value   string
+		want    bool
+		wantErr bool
+	}{
+		{name: "matching value", cache: cache, pattern: "^[a-z]+@harness\\.io$", value: "john@harness.io", want: true},
+		{name: "cached pattern is reused", cache: cache, pattern: "^[a-z]+@harness\\.io$", value: "john@example.com", want: false},
+		{name: "invalid pattern never matches", cache: cache, pattern: "[", value: "[", want: false, wantErr: true},
+		{name: "cached invalid pattern never matches", cache: cache, pattern: "[", value: "[", want: false, wantErr: true},
+		{name: "nil cache compiles the pattern", pattern: "^[a-z]+@harness\\.io$", value: "john@harness.io", want: true},
+		{name: "nil cache invalid pattern never matches", pattern: "[", value: "[", want: false, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cache.match(tt.pattern, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("regexCache.match() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("regexCache.match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_regexCache_bound(t *testing.T) {
+	cache := newRegexCache()
+	for i := 0; i <= regexCacheSize; i++ {
+		if _, err := cache.compile(fmt.Sprintf("^user-%d$", i)); err != nil {
+			t.Fatalf("regexCache.compile() error = %v", err)
+		}
+	}
+	if cache.patterns.Len() != regexCacheSize {
+		t.Errorf("regexCache holds %d patterns, want %d", cache.patterns.Len(), regexCacheSize)
+	}
+	if cache.patterns.Contains("^user-0$") {
+		t.Errorf("regexCache holds the least recently used pattern, want it evicted")
+	}
+}
+
+const (
+	benchmarkPattern = "^(john|jane)\\.[a-z]+@(harness|example)\\.(io|com)$"
+	benchmarkValue   = "jane.doe@harness.io"
+)
+
+func BenchmarkMatchRegexCached(b *testing.B) {
+	cache := newRegexCache()
+	for i := 0; i < b.N; i++ {
+		_, _ = cache.match(benchmarkPattern, benchmarkValue)
+	}
+}
+
+func BenchmarkMatchRegexUncached(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _ = regexp.MatchString(benchmarkPattern, benchmarkValue)
+	}
+}
+
+func TestEvaluator_OnFlagStored(t *testing.T) {
+	const (
+		oldPattern     = "^[a-z]+@harness\\.io$"
+		newPattern     = "^[a-z]+@example\\.com$"
+		segmentPattern = "^[a-z]+@segment\\.io$"
+	)
+	emailFlag := func(pattern string) rest.FeatureConfig {
+		return rest.FeatureConfig{
+			Feature:      "emailRollout",
+			State:        rest.FeatureStateOn,
+			Kind:         "boolean",
+			OffVariation: identifierFalse,
+			DefaultServe: rest.Serve{Variation: &identifierFalse},
+			Variations:   boolVariations,
+			Rules: &[]rest.ServingRule{
+				{RuleId: "email", Priority: 1, Serve: rest.Serve{Variation: &identifierTrue}, Clauses: []rest.Clause{
+					{Attribute: "email", Op: matchOperator, Values: []string{pattern}},
+				}},
+				{RuleId: "segment", Priority: 2, Serve: rest.Serve{Variation: &identifierTrue}, Clauses: []rest.Clause{
+					{Op: segmentMatchOperator, Values: []string{beta}},
+				}},
+			},
+		}
+	}
+	flags := map[string]rest.FeatureConfig{"emailRollout": emailFlag(oldPattern)}
+	segments := map[string]rest.Segment{beta: {Identifier: beta, Rules: &[]rest.Clause{
+		{Attribute: "email", Op: matchOperator, Values: []string{segmentPattern}},
+	}}}
+	e, _ := NewEvaluator(NewTestRepository(flags, segments), nil, logger.NewNoOpLogger())
+	target := &Target{Identifier: harness, Attributes: &map[string]interface{}{"email": "john@example.com"}}
+
+	if e.BoolVariation("emailRollout", target, true) {
+		t.Fatalf("Evaluator.BoolVariation() = true before the pattern changes, want false")
+	}
+	if !e.regexes.patterns.Contains(oldPattern) || !e.regexes.patterns.Contains(segmentPattern) {
+		t.Fatalf("Evaluator caches %v, want the flag and segment patterns", e.regexes.patterns.Keys())
+	}
+
+	flags["emailRollout"] = emailFlag(newPattern)
+	e.OnFlagStored("emailRollout")
+	if e.regexes.patterns.Contains(oldPattern) {
+		t.Errorf("Evaluator caches the pattern the flag no longer uses")
+	}
+	if !e.regexes.patterns.Contains(segmentPattern) {
+		t.Errorf("Evaluator evicted the segment pattern when the flag was stored")
+	}
+	if !e.BoolVariation("emailRollout", target, false) {
+		t.Errorf("Evaluator.BoolVariation() = false after the pattern changed, want true")
+	}
+	if !e.regexes.patterns.Contains(newPattern) {
+		t.Errorf("Evaluator doesn't cache the new pattern of the flag")
+	}
+
+	e.OnSegmentStored(beta)
+	if e.regexes.patterns.Contains(segmentPattern) {
+		t.Errorf("Evaluator caches the segment pattern after the segment was stored")
+	}
+	if !e.regexes.patterns.Contains(newPattern) {
+		t.Errorf("Evaluator evicted the flag pattern when the segment was stored")
+	}
+}
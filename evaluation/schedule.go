@@ -0,0 +1,201 @@
+package evaluation
+
+import (
+	"strings"
+	"time"
+
+	"github.com/harness/ff-golang-server-sdk/rest"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// scheduleCacheSize bounds the number of parsed schedule windows and loaded time zones kept in memory
+const scheduleCacheSize = 1024
+
+var scheduleWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// scheduleWindow is a time of day range on a set of weekdays, from is inclusive and to exclusive.
+// Windows ending before they start span midnight, they start on their days and end the day after.
+type scheduleWindow struct {
+	days     [7]bool
+	from, to time.Duration
+}
+
+// parseScheduleWindow parses windows such as "mon-fri 09:00-17:00", "sat,sun 10:00-14:00" or the
+// overnight "fri 22:00-02:00", the days are optional and default to every day
+func parseScheduleWindow(value string) (scheduleWindow, bool) {
+	var window scheduleWindow
+	fields := strings.Fields(strings.ToLower(value))
+	switch len(fields) {
+	case 1:
+		for day := range window.days {
+			window.days[day] = true
+		}
+	case 2:
+		if !parseScheduleDays(fields[0], &window.days) {
+			return window, false
+		}
+	default:
+		return window, false
+	}
+
+	hours := strings.Split(fields[len(fields)-1], "-")
+	if len(hours) != 2 {
+		return window, false
+	}
+	from, ok := parseTimeOfDay(hours[0])
+	if !ok {
+		return window, false
+	}
+	to, ok := parseTimeOfDay(hours[1])
+	if !ok || to == from || from == 24*time.Hour {
+		return window, false
+	}
+	window.from, window.to = from, to
+	return window, true
+}
+
+// parseScheduleDays parses comma separated days or day ranges, ranges may wrap around the week
+func parseScheduleDays(value string, days *[7]bool) bool {
+	for _, part := range strings.Split(value, ",") {
+		bounds := strings.Split(part, "-")
+		first, ok := scheduleWeekdays[bounds[0]]
+		if !ok || len(bounds) > 2 {
+			return false
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, ok = scheduleWeekdays[bounds[1]]; !ok {
+				return false
+			}
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			days[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+	return true
+}
+
+// parseTimeOfDay parses a HH:MM time of day, 24:00 is accepted as the end of the day
+func parseTimeOfDay(value string) (time.Duration, bool) {
+	if value == "24:00" {
+		return 24 * time.Hour, true
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, true
+}
+
+func (w scheduleWindow) contains(t time.Time) bool {
+	timeOfDay := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second
+	if w.from < w.to {
+		return w.days[t.Weekday()] && timeOfDay >= w.from && timeOfDay < w.to
+	}
+	if timeOfDay >= w.from {
+		return w.days[t.Weekday()]
+	}
+	// past midnight the window belongs to the day before
+	return timeOfDay < w.to && w.days[(t.Weekday()+6)%7]
+}
+
+// scheduleCache holds schedule windows keyed by clause value and time zones keyed by name, invalid
+// windows and unknown time zones are cached too so they aren't parsed again. All methods are safe
+// to call on a nil scheduleCache, values are then parsed on every call.
+type scheduleCache struct {
+	windows   *lru.Cache
+	locations *lru.Cache
+}
+
+// cachedScheduleWindow is a parsed schedule window, ok is false for invalid windows
+type cachedScheduleWindow struct {
+	window scheduleWindow
+	ok     bool
+}
+
+// newScheduleCache creates an empty scheduleCache, lru.New only fails for a non positive size
+func newScheduleCache() *scheduleCache {
+	windows, _ := lru.New(scheduleCacheSize)
+	locations, _ := lru.New(scheduleCacheSize)
+	return &scheduleCache{windows: windows, locations: locations}
+}
+
+// window parses the schedule window reusing previously parsed windows
+func (c *scheduleCache) window(value string) (scheduleWindow, bool) {
+	if c == nil {
+		return parseScheduleWindow(value)
+	}
+	if cached, ok := c.windows.Get(value); ok {
+		window, _ := cached.(cachedScheduleWindow)
+		return window.window, window.ok
+	}
+	window, ok := parseScheduleWindow(value)
+	c.windows.Add(value, cachedScheduleWindow{window: window, ok: ok})
+	return window, ok
+}
+
+// evictWindow drops the parsed schedule window
+func (c *scheduleCache) evictWindow(value string) {
+	if c != nil {
+		c.windows.Remove(value)
+	}
+}
+
+// location loads the time zone reusing previously loaded time zones
+func (c *scheduleCache) location(name string) (*time.Location, error) {
+	if c == nil {
+		return time.LoadLocation(name)
+	}
+	if cached, ok := c.locations.Get(name); ok {
+		if err, isErr := cached.(error); isErr {
+			return nil, err
+		}
+		location, _ := cached.(*time.Location)
+		return location, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		c.locations.Add(name, err)
+		return nil, err
+	}
+	c.locations.Add(name, location)
+	return location, nil
+}
+
+// matchSchedule reports whether the current time falls within any of the schedule windows of the
+// clause, invalid windows are skipped. The clause attribute optionally names a target attribute
+// holding an IANA time zone, the evaluator location is used when the target has no valid one.
+func (e *evaluationState) matchSchedule(clause *rest.Clause, target *Target) bool {
+	location := e.location
+	if location == nil {
+		location = time.UTC
+	}
+	if clause.Attribute != "" {
+		if name := formatAttrValue(e.getAttrValue(target, clause.Attribute)); name != "" {
+			if targetLocation, err := e.schedules.location(name); err == nil {
+				location = targetLocation
+			}
+		}
+	}
+	now := e.now().In(location)
+	for _, value := range clause.Values {
+		e.recordDependency(scheduleDependency, value)
+		if window, ok := e.schedules.window(value); ok && window.contains(now) {
+			return true
+		}
+	}
+	return false
+}
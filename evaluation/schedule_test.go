@@ -0,0 +1,164 @@
+package evaluation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+func Test_parseScheduleWindow(t *testing.T) {
+	weekdays := [7]bool{false, true, true, true, true, true, false}
+	everyDay := [7]bool{true, true, true, true, true, true, true}
+	tests := []struct {
+		value  string
+		want   scheduleWindow
+		wantOk bool
+	}{
+		{value: "mon-fri 09:00-17:00", want: scheduleWindow{days: weekdays, from: 9 * time.Hour, to: 17 * time.Hour}, wantOk: true},
+		{value: "Mon,Tue,Wed,Thu,Fri 09:00-17:00", want: scheduleWindow{days: weekdays, from: 9 * time.Hour, to: 17 * time.Hour}, wantOk: true},
+		{value: "fri-mon 00:00-24:00", want: scheduleWindow{days: [7]bool{true, true, false, false, false, true, true}, to: 24 * time.Hour}, wantOk: true},
+		{value: "12:30-13:15", want: scheduleWindow{days: everyDay, from: 12*time.Hour + 30*time.Minute, to: 13*time.Hour + 15*time.Minute}, wantOk: true},
+		{value: "fri 22:00-02:00", want: scheduleWindow{days: [7]bool{5: true}, from: 22 * time.Hour, to: 2 * time.Hour}, wantOk: true},
+		{value: "mon-fri 09:00-09:00"},
+		{value: "24:00-02:00"},
+		{value: "weekdays 09:00-17:00"},
+		{value: "mon 9-17"},
+		{value: "mon 09:00-17:00 utc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parseScheduleWindow(tt.value)
+			if ok != tt.wantOk || (ok && got != tt.want) {
+				t.Errorf("parseScheduleWindow() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestEvaluator_scheduleOperator(t *testing.T) {
+	// Wednesday 3 January 2024
+	wednesday := func(hour, minute int) time.Time {
+		return time.Date(2024, time.January, 3, hour, minute, 0, 0, time.UTC)
+	}
+	businessHours := rest.Clause{Op: scheduleOperator, Values: []string{"invalid", "mon-fri 09:00-17:00"}}
+	wednesdayNight := rest.Clause{Op: scheduleOperator, Values: []string{"wed 22:00-02:00"}}
+	tests := []struct {
+		name     string
+		now      time.Time
+		location *time.Location
+		clause   rest.Clause
+		target   *Target
+		want     bool
+	}{
+		{name: "inside business hours", now: wednesday(10, 30), clause: businessHours, want: true},
+		{name: "start of business hours", now: wednesday(9, 0), clause: businessHours, want: true},
+		{name: "end of business hours", now: wednesday(17, 0), clause: businessHours, want: false},
+		{name: "outside business hours", now: wednesday(20, 0), clause: businessHours, want: false},
+		{name: "weekend", now: wednesday(10, 30).AddDate(0, 0, 3), clause: businessHours, want: false},
+		{name: "overnight before midnight", now: wednesday(23, 0), clause: wednesdayNight, want: true},
+		{name: "overnight after midnight", now: wednesday(1, 30).AddDate(0, 0, 1), clause: wednesdayNight, want: true},
+		{name: "overnight end", now: wednesday(2, 0).AddDate(0, 0, 1), clause: wednesdayNight, want: false},
+		{name: "overnight on the previous day", now: wednesday(1, 30), clause: wednesdayNight, want: false},
+		{name: "overnight before start", now: wednesday(21, 59), clause: wednesdayNight, want: false},
+		{
+			name:     "evaluator location",
+			now:      wednesday(10, 30),
+			location: time.FixedZone("UTC+9", 9*60*60),
+			clause:   businessHours,
+			want:     false,
+		},
+		{
+			name:     "target time zone",
+			now:      wednesday(18, 30),
+			location: time.FixedZone("UTC+9", 9*60*60),
+			clause:   rest.Clause{Attribute: "timezone", Op: scheduleOperator, Values: businessHours.Values},
+			target: &Target{
+				Identifier: harness,
+				Attributes: &map[string]interface{}{"timezone": "Etc/GMT+5"},
+			},
+			want: true,
+		},
+		{
+			name:   "invalid target time zone",
+			now:    wednesday(18, 30),
+			clause: rest.Clause{Attribute: "timezone", Op: scheduleOperator, Values: businessHours.Values},
+			target: &Target{
+				Identifier: harness,
+				Attributes: &map[string]interface{}{"timezone": "Nowhere/Special"},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := tt.now
+			e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger(),
+				WithClock(func() time.Time { return now }), WithLocation(tt.location))
+			target := tt.target
+			if target == nil {
+				target = &Target{Identifier: harness}
+			}
+			if got := e.newEvaluation(context.Background()).evaluateClause(&tt.clause, target); got != tt.want {
+				t.Errorf("Evaluator.evaluateClause() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_scheduleCache(t *testing.T) {
+	cache := newScheduleCache()
+	for i := 0; i < 2; i++ {
+		if _, ok := cache.window("mon-fri 09:00-17:00"); !ok {
+			t.Errorf("scheduleCache.window() ok = false, want true")
+		}
+		if _, ok := cache.window("invalid"); ok {
+			t.Errorf("scheduleCache.window() ok = true for an invalid window")
+		}
+		if _, err := cache.location("Etc/GMT+5"); err != nil {
+			t.Errorf("scheduleCache.location() error = %v", err)
+		}
+		if _, err := cache.location("Nowhere/Special"); err == nil {
+			t.Errorf("scheduleCache.location() error = nil for an unknown time zone")
+		}
+	}
+	if cache.windows.Len() != 2 || cache.locations.Len() != 2 {
+		t.Errorf("scheduleCache holds %d windows and %d locations, want 2 of each", cache.windows.Len(),
+			cache.locations.Len())
+	}
+
+	cache.evictWindow("invalid")
+	if cache.windows.Contains("invalid") || !cache.windows.Contains("mon-fri 09:00-17:00") {
+		t.Errorf("scheduleCache.evictWindow() holds %v, want only the other window", cache.windows.Keys())
+	}
+
+	var nilCache *scheduleCache
+	nilCache.evictWindow("invalid")
+	if _, ok := nilCache.window("mon-fri 09:00-17:00"); !ok {
+		t.Errorf("nil scheduleCache.window() ok = false, want true")
+	}
+	if _, err := nilCache.location("Etc/GMT+5"); err != nil {
+		t.Errorf("nil scheduleCache.location() error = %v", err)
+	}
+}
+
+func TestEvaluator_OnFlagStoredEvictsScheduleWindows(t *testing.T) {
+	e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger())
+	clause := rest.Clause{Op: scheduleOperator, Values: []string{"mon-fri 09:00-17:00"}}
+	state := e.newEvaluation(context.Background())
+	state.feature = "scheduled"
+	state.evaluateClause(&clause, &Target{Identifier: harness})
+	if !e.schedules.windows.Contains(clause.Values[0]) {
+		t.Fatalf("Evaluator doesn't cache the schedule window")
+	}
+	e.OnFlagStored(simple)
+	if !e.schedules.windows.Contains(clause.Values[0]) {
+		t.Errorf("Evaluator evicted the schedule window when another flag was stored")
+	}
+	e.OnFlagStored("scheduled")
+	if e.schedules.windows.Contains(clause.Values[0]) {
+		t.Errorf("Evaluator caches the schedule window after its flag was stored")
+	}
+}
@@ -0,0 +1,221 @@
+package evaluation
+
+import (
+	"strconv"
+	"strings"
+)
+
+// semverNever is the operator of comparators no version satisfies, such as <*
+const semverNever = "!"
+
+// semverOperators are the comparator operators in the order they are matched against a token
+var semverOperators = []string{">=", "<=", ">", "<", "=", "~", "^"}
+
+// semverComparator is a primitive comparison a version must satisfy
+type semverComparator struct {
+	op      string
+	version semver
+}
+
+func (c semverComparator) matches(v semver) bool {
+	result := v.compare(c.version)
+	switch c.op {
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "=":
+		return result == 0
+	default:
+		return false
+	}
+}
+
+// parsePartialVersion parses versions where trailing parts may be missing or an x, X or * wildcard,
+// such as "1.2", "3.x" or "*", and returns how many leading parts were given. A prerelease is only
+// accepted on full versions.
+func parsePartialVersion(s string) (semver, int, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	var v semver
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		v.prerelease = strings.Split(s[i+1:], ".")
+		for _, identifier := range v.prerelease {
+			if identifier == "" {
+				return semver{}, 0, false
+			}
+		}
+		s = s[:i]
+	}
+	if s == "" {
+		return v, 0, len(v.prerelease) == 0
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return semver{}, 0, false
+	}
+	var numbers [3]uint64
+	count := 0
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			continue
+		}
+		// a number can't follow a wildcard
+		if count != i {
+			return semver{}, 0, false
+		}
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return semver{}, 0, false
+		}
+		numbers[i] = n
+		count++
+	}
+	if len(v.prerelease) > 0 && count != 3 {
+		return semver{}, 0, false
+	}
+	v.major, v.minor, v.patch = numbers[0], numbers[1], numbers[2]
+	return v, count, true
+}
+
+// nextSemver returns the lowest version above every version sharing the given number of leading parts with v
+func nextSemver(v semver, parts int) semver {
+	switch parts {
+	case 1:
+		return semver{major: v.major + 1}
+	case 2:
+		return semver{major: v.major, minor: v.minor + 1}
+	default:
+		return semver{major: v.major, minor: v.minor, patch: v.patch + 1}
+	}
+}
+
+// semverComparators desugars an npm style comparator into primitive comparators
+func semverComparators(op, version string) ([]semverComparator, bool) {
+	v, count, ok := parsePartialVersion(version)
+	if !ok {
+		return nil, false
+	}
+	if count == 0 {
+		// wildcards match any version, except as exclusive bounds
+		if op == ">" || op == "<" {
+			return []semverComparator{{op: semverNever}}, true
+		}
+		return nil, true
+	}
+	switch op {
+	case "", "=":
+		if count == 3 {
+			return []semverComparator{{op: "=", version: v}}, true
+		}
+		return []semverComparator{{op: ">=", version: v}, {op: "<", version: nextSemver(v, count)}}, true
+	case ">":
+		if count == 3 {
+			return []semverComparator{{op: ">", version: v}}, true
+		}
+		return []semverComparator{{op: ">=", version: nextSemver(v, count)}}, true
+	case ">=", "<":
+		return []semverComparator{{op: op, version: v}}, true
+	case "<=":
+		if count == 3 {
+			return []semverComparator{{op: "<=", version: v}}, true
+		}
+		return []semverComparator{{op: "<", version: nextSemver(v, count)}}, true
+	case "~":
+		parts := 2
+		if count == 1 {
+			parts = 1
+		}
+		return []semverComparator{{op: ">=", version: v}, {op: "<", version: nextSemver(v, parts)}}, true
+	case "^":
+		// the first non zero part given may not change
+		parts := 3
+		switch {
+		case count == 1 || v.major > 0:
+			parts = 1
+		case count == 2 || v.minor > 0:
+			parts = 2
+		}
+		return []semverComparator{{op: ">=", version: v}, {op: "<", version: nextSemver(v, parts)}}, true
+	default:
+		return nil, false
+	}
+}
+
+// parseSemverRange parses a space separated list of comparators all of which must be satisfied, or a
+// hyphen range such as "1.2.3 - 2.3"
+func parseSemverRange(r string) ([]semverComparator, bool) {
+	tokens := strings.Fields(r)
+	if len(tokens) == 3 && tokens[1] == "-" {
+		lower, lowerOk := semverComparators(">=", tokens[0])
+		upper, upperOk := semverComparators("<=", tokens[2])
+		return append(lower, upper...), lowerOk && upperOk
+	}
+
+	var comparators []semverComparator
+	for i := 0; i < len(tokens); i++ {
+		op, version := "", tokens[i]
+		for _, candidate := range semverOperators {
+			if strings.HasPrefix(version, candidate) {
+				op, version = candidate, strings.TrimPrefix(version, candidate)
+				break
+			}
+		}
+		// the operator may be separated from its version by spaces
+		if op != "" && version == "" && i+1 < len(tokens) {
+			i++
+			version = tokens[i]
+		}
+		if op != "" && version == "" {
+			return nil, false
+		}
+		desugared, ok := semverComparators(op, version)
+		if !ok {
+			return nil, false
+		}
+		comparators = append(comparators, desugared...)
+	}
+	return comparators, true
+}
+
+// matchSemverConstraint reports whether the version satisfies the npm style constraint, such as
+// ">=1.2.0 <2.0.0 || 3.x". Ranges separated by "||" are ORed, comparators within a range are ANDed.
+// The x-range, tilde, caret and hyphen range shorthands are supported, prerelease versions are
+// ordered by precedence like any other version. Invalid versions or constraints don't match.
+func matchSemverConstraint(version, constraint string) bool {
+	v, ok := parseSemver(version)
+	if !ok {
+		return false
+	}
+	ranges := strings.Split(constraint, "||")
+	parsed := make([][]semverComparator, 0, len(ranges))
+	for _, r := range ranges {
+		comparators, ok := parseSemverRange(r)
+		if !ok {
+			return false
+		}
+		parsed = append(parsed, comparators)
+	}
+	for _, comparators := range parsed {
+		if semverRangeMatches(comparators, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func semverRangeMatches(comparators []semverComparator, v semver) bool {
+	for _, comparator := range comparators {
+		if !comparator.matches(v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package evaluation
+
+import "testing"
+
+func Test_matchSemverConstraint(t *testing.T) {
+	tests := []struct {
+		version, constraint string
+		want                bool
+	}{
+		{version: "1.5.0", constraint: ">=1.2.0 <2.0.0 || 3.x", want: true},
+		{version: "3.4.1", constraint: ">=1.2.0 <2.0.0 || 3.x", want: true},
+		{version: "2.1.0", constraint: ">=1.2.0 <2.0.0 || 3.x", want: false},
+		{version: "1.1.9", constraint: ">=1.2.0 <2.0.0 || 3.x", want: false},
+		{version: "4.0.0", constraint: ">=1.2.0 <2.0.0 || 3.x", want: false},
+		{version: "1.2.7", constraint: "1.2.x", want: true},
+		{version: "1.3.0", constraint: "1.2.X", want: false},
+		{version: "9.9.9", constraint: "*", want: true},
+		{version: "9.9.9", constraint: "", want: true},
+		{version: "1.2.3", constraint: ">= 1.2.3", want: true},
+		{version: "1.3.0", constraint: ">1.2", want: true},
+		{version: "1.2.9", constraint: ">1.2", want: false},
+		{version: "1.2.9", constraint: "<=1.2", want: true},
+		{version: "1.2.4", constraint: "~1.2.3", want: true},
+		{version: "1.3.0", constraint: "~1.2.3", want: false},
+		{version: "1.9.0", constraint: "~1", want: true},
+		{version: "1.9.0", constraint: "^1.2.3", want: true},
+		{version: "2.0.0", constraint: "^1.2.3", want: false},
+		{version: "0.2.9", constraint: "^0.2.3", want: true},
+		{version: "0.3.0", constraint: "^0.2.3", want: false},
+		{version: "0.0.4", constraint: "^0.0.3", want: false},
+		{version: "2.3.9", constraint: "1.2.3 - 2.3", want: true},
+		{version: "2.4.0", constraint: "1.2.3 - 2.3", want: false},
+		{version: "1.0.0", constraint: "<*", want: false},
+		{version: "v1.2.3", constraint: "=1.2.3", want: true},
+		{version: "1.2", constraint: "1.x", want: false},
+		{version: "1.2.3", constraint: ">=1.x.3", want: false},
+		{version: "1.2.3", constraint: "latest", want: false},
+		{version: "1.2.3", constraint: ">=", want: false},
+		{version: "1.2.3", constraint: "1.2.3 || junk", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version+" "+tt.constraint, func(t *testing.T) {
+			if got := matchSemverConstraint(tt.version, tt.constraint); got != tt.want {
+				t.Errorf("matchSemverConstraint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package evaluation
+
+import "testing"
+
+func Test_compareSemver(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		want   int
+		wantOk bool
+	}{
+		{a: "2.9.0", b: "2.14.0", want: -1, wantOk: true},
+		{a: "2.14.0", b: "2.9.0", want: 1, wantOk: true},
+		{a: "2.14.0", b: "v2.14.0", want: 0, wantOk: true},
+		{a: "1.10.2", b: "1.9.12", want: 1, wantOk: true},
+		{a: "1.10.2", b: "1.10.10", want: -1, wantOk: true},
+		{a: "2.14.0+build.5", b: "2.14.0", want: 0, wantOk: true},
+		{a: "1.0.0-alpha", b: "1.0.0", want: -1, wantOk: true},
+		{a: "1.0.0-alpha.1", b: "1.0.0-alpha", want: 1, wantOk: true},
+		{a: "1.0.0-alpha.2", b: "1.0.0-alpha.10", want: -1, wantOk: true},
+		{a: "1.0.0-1", b: "1.0.0-beta", want: -1, wantOk: true},
+		{a: "2.14", b: "2.14.0", wantOk: false},
+		{a: "2.x.0", b: "2.14.0", wantOk: false},
+		{a: "2.14.0", b: "latest", wantOk: false},
+		{a: "1.0.0-", b: "1.0.0", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			got, ok := compareSemver(tt.a, tt.b)
+			if ok != tt.wantOk {
+				t.Errorf("compareSemver() ok = %v, want %v", ok, tt.wantOk)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("compareSemver() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
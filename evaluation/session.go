@@ -0,0 +1,48 @@
+package evaluation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+// SessionCache holds the variations evaluated for the target of a single session keyed by flag
+// identifier. Cached variations are only reused while the flag stays at the version they were
+// evaluated at. A SessionCache is not safe for concurrent use.
+type SessionCache map[string]sessionEntry
+
+type sessionEntry struct {
+	version   int64
+	variation rest.Variation
+}
+
+// EvaluateInSession is like Evaluate but reuses the variation cached in the session when the flag
+// hasn't changed version since, evaluated variations are written back to the session. The session
+// must only ever be used for the same target. Flags without a version are always evaluated.
+func (e Evaluator) EvaluateInSession(session SessionCache, identifier string, target *Target,
+	kind string) (rest.Variation, error) {
+	state := e.newEvaluation(context.Background())
+	flag, err := state.flag(identifier)
+	if err != nil {
+		return rest.Variation{}, err
+	}
+	if !kindMatches(flag.Kind, kind) {
+		return rest.Variation{}, fmt.Errorf("%w, expected: %s, got: %s", ErrFlagKindMismatch, kind, flag.Kind)
+	}
+
+	if entry, ok := session[identifier]; ok && flag.Version != nil && entry.version == *flag.Version {
+		e.postEvaluate(flag, target, entry.variation)
+		return entry.variation, nil
+	}
+
+	// the flag is memoized so evaluating it doesn't retrieve it again
+	variation, err := state.evaluate(identifier, target, kind)
+	if err != nil {
+		return rest.Variation{}, err
+	}
+	if session != nil && flag.Version != nil {
+		session[identifier] = sessionEntry{version: *flag.Version, variation: variation}
+	}
+	return variation, nil
+}
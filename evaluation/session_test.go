@@ -0,0 +1,54 @@
+package evaluation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+func TestEvaluator_EvaluateInSession(t *testing.T) {
+	version := int64(1)
+	flag := rest.FeatureConfig{
+		Feature:      "sessionFlag",
+		State:        rest.FeatureStateOn,
+		Kind:         "boolean",
+		OffVariation: identifierFalse,
+		DefaultServe: rest.Serve{Variation: &identifierTrue},
+		Variations:   boolVariations,
+		Version:      &version,
+	}
+	repo := NewTestRepository(map[string]rest.FeatureConfig{flag.Feature: flag}, nil)
+	e, _ := NewEvaluator(repo, nil, logger.NewNoOpLogger())
+	session := SessionCache{}
+	target := &Target{Identifier: harness}
+
+	evaluate := func(want rest.Variation) {
+		t.Helper()
+		got, err := e.EvaluateInSession(session, flag.Feature, target, "boolean")
+		if err != nil {
+			t.Fatalf("Evaluator.EvaluateInSession() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Evaluator.EvaluateInSession() = %v, want %v", got, want)
+		}
+	}
+
+	evaluate(boolVariations[0])
+
+	// the flag now serves false but is still at the same version so the session result is reused
+	flag.DefaultServe = rest.Serve{Variation: &identifierFalse}
+	repo.flags[flag.Feature] = flag
+	evaluate(boolVariations[0])
+
+	// a new version invalidates the session result
+	newVersion := int64(2)
+	flag.Version = &newVersion
+	repo.flags[flag.Feature] = flag
+	evaluate(boolVariations[1])
+
+	if _, err := e.EvaluateInSession(session, flag.Feature, target, "string"); err == nil {
+		t.Errorf("Evaluator.EvaluateInSession() expected a kind mismatch error")
+	}
+}
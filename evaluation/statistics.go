@@ -0,0 +1,58 @@
+package evaluation
+
+import "sync"
+
+// ClauseKey identifies a clause by the serving rule it belongs to and its index within that rule
+type ClauseKey struct {
+	RuleID      string
+	ClauseIndex int
+}
+
+// ClauseStatistics counts how often each clause was the one that short-circuited a failing
+// serving rule. Clauses that fail often are good candidates to be moved first in a rule while
+// expensive clauses that rarely fail can be moved last. It is safe for concurrent use.
+type ClauseStatistics struct {
+	mu     sync.Mutex
+	counts map[ClauseKey]uint64
+}
+
+// NewClauseStatistics creates an empty ClauseStatistics
+func NewClauseStatistics() *ClauseStatistics {
+	return &ClauseStatistics{
+		counts: make(map[ClauseKey]uint64),
+	}
+}
+
+// Count returns how many times the clause at clauseIndex short-circuited the rule with ruleID
+func (s *ClauseStatistics) Count(ruleID string, clauseIndex int) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.counts[ClauseKey{RuleID: ruleID, ClauseIndex: clauseIndex}]
+}
+
+// Snapshot returns a copy of all collected counters
+func (s *ClauseStatistics) Snapshot() map[ClauseKey]uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot := make(map[ClauseKey]uint64, len(s.counts))
+	for key, count := range s.counts {
+		snapshot[key] = count
+	}
+	return snapshot
+}
+
+// Reset clears all collected counters
+func (s *ClauseStatistics) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts = make(map[ClauseKey]uint64)
+}
+
+func (s *ClauseStatistics) record(ruleID string, clauseIndex int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[ClauseKey{RuleID: ruleID, ClauseIndex: clauseIndex}]++
+}
@@ -0,0 +1,66 @@
+package evaluation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+func TestClauseStatistics_failingClauseIsCounted(t *testing.T) {
+	statistics := NewClauseStatistics()
+	e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger(), WithClauseStatistics(statistics))
+
+	rules := []rest.ServingRule{
+		{
+			RuleId:   "rule1",
+			Priority: 1,
+			Clauses: []rest.Clause{
+				{Attribute: identifier, Op: equalOperator, Values: []string{harness}},
+				{Attribute: "country", Op: equalOperator, Values: []string{"US"}},
+			},
+			Serve: rest.Serve{Variation: &identifierTrue},
+		},
+	}
+	target := &Target{
+		Identifier: harness,
+		Attributes: &map[string]interface{}{"country": "CA"},
+	}
+
+	for i := 0; i < 3; i++ {
+		if got := e.newEvaluation(context.Background()).evaluateRules(simple, rules, target); got != "" {
+			t.Fatalf("Evaluator.evaluateRules() = %v, want empty", got)
+		}
+	}
+
+	if got := statistics.Count("rule1", 1); got != 3 {
+		t.Errorf("ClauseStatistics.Count() for failing clause = %v, want 3", got)
+	}
+	if got := statistics.Count("rule1", 0); got != 0 {
+		t.Errorf("ClauseStatistics.Count() for matching clause = %v, want 0", got)
+	}
+
+	statistics.Reset()
+	if got := len(statistics.Snapshot()); got != 0 {
+		t.Errorf("ClauseStatistics.Snapshot() after reset has %v entries, want 0", got)
+	}
+}
+
+func TestClauseStatistics_matchingRuleIsNotCounted(t *testing.T) {
+	statistics := NewClauseStatistics()
+	e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger(), WithClauseStatistics(statistics))
+
+	rule := rest.ServingRule{
+		RuleId: "rule1",
+		Clauses: []rest.Clause{
+			{Attribute: identifier, Op: equalOperator, Values: []string{harness}},
+		},
+	}
+	if !e.newEvaluation(context.Background()).evaluateRule(&rule, &Target{Identifier: harness}) {
+		t.Fatalf("Evaluator.evaluateRule() = false, want true")
+	}
+	if got := len(statistics.Snapshot()); got != 0 {
+		t.Errorf("ClauseStatistics.Snapshot() has %v entries, want 0", got)
+	}
+}
@@ -0,0 +1,49 @@
+package evaluation
+
+import "github.com/harness/ff-golang-server-sdk/rest"
+
+// StickyStore persists the variation a target was bucketed into by a percentage rollout so
+// that the target keeps being served that variation even when the distribution weights change
+type StickyStore interface {
+	// GetAssignment returns the variation previously assigned to the target for the flag
+	GetAssignment(flagIdentifier string, targetIdentifier string) (string, bool)
+	// SetAssignment persists the variation assigned to the target for the flag
+	SetAssignment(flagIdentifier string, targetIdentifier string, variation string)
+}
+
+// evaluateStickyDistribution serves the distribution honoring any assignment persisted in the
+// sticky store, falling back to bucketing the target and persisting the outcome
+func (e Evaluator) evaluateStickyDistribution(feature string, distribution *rest.Distribution, target *Target) string {
+	distribution = e.defaultBucketBy(distribution)
+	if e.stickyStore == nil || distribution == nil || target == nil || target.Identifier == "" {
+		return evaluateDistribution(distribution, target)
+	}
+
+	if assigned, ok := e.stickyStore.GetAssignment(feature, target.Identifier); ok {
+		// only honor assignments that are still part of the distribution
+		for _, wv := range distribution.Variations {
+			if wv.Variation == assigned {
+				return assigned
+			}
+		}
+		e.logger.Debugf("Sticky assignment %s of target %s is no longer served by flag %s",
+			assigned, target.Identifier, feature)
+	}
+
+	variation := evaluateDistribution(distribution, target)
+	if variation != "" {
+		e.stickyStore.SetAssignment(feature, target.Identifier, variation)
+	}
+	return variation
+}
+
+// defaultBucketBy returns the distribution bucketing by the evaluator's bucketBy attribute when
+// it doesn't name one itself, the flag configuration is left untouched
+func (e Evaluator) defaultBucketBy(distribution *rest.Distribution) *rest.Distribution {
+	if distribution == nil || distribution.BucketBy != "" || e.bucketBy == "" {
+		return distribution
+	}
+	bucketed := *distribution
+	bucketed.BucketBy = e.bucketBy
+	return &bucketed
+}
@@ -0,0 +1,127 @@
+package evaluation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+type mapStickyStore map[string]string
+
+func (m mapStickyStore) GetAssignment(flagIdentifier string, targetIdentifier string) (string, bool) {
+	variation, ok := m[flagIdentifier+"/"+targetIdentifier]
+	return variation, ok
+}
+
+func (m mapStickyStore) SetAssignment(flagIdentifier string, targetIdentifier string, variation string) {
+	m[flagIdentifier+"/"+targetIdentifier] = variation
+}
+
+func TestEvaluator_evaluateFlagWithStickyStore(t *testing.T) {
+	// weights have since been changed to serve false to everyone
+	fc := rest.FeatureConfig{
+		Feature:    simple,
+		State:      rest.FeatureStateOn,
+		Variations: boolVariations,
+		DefaultServe: rest.Serve{
+			Distribution: &rest.Distribution{
+				BucketBy: identifier,
+				Variations: []rest.WeightedVariation{
+					{Variation: identifierTrue, Weight: 0},
+					{Variation: identifierFalse, Weight: 100},
+				},
+			},
+		},
+	}
+	target := &Target{Identifier: harness}
+
+	tests := []struct {
+		name      string
+		store     mapStickyStore
+		want      string
+		wantStore string
+	}{
+		{
+			name:      "prior assignment overrides the new weights",
+			store:     mapStickyStore{simple + "/" + harness: identifierTrue},
+			want:      identifierTrue,
+			wantStore: identifierTrue,
+		},
+		{
+			name:      "target without assignment is bucketed and the assignment persisted",
+			store:     mapStickyStore{},
+			want:      identifierFalse,
+			wantStore: identifierFalse,
+		},
+		{
+			name:      "assignment no longer part of the distribution is replaced",
+			store:     mapStickyStore{simple + "/" + harness: "removed"},
+			want:      identifierFalse,
+			wantStore: identifierFalse,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger(), WithStickyStore(tt.store))
+			got, err := e.newEvaluation(context.Background()).evaluateFlag(fc, target)
+			if err != nil {
+				t.Fatalf("Evaluator.evaluateFlag() error = %v", err)
+			}
+			if got.Identifier != tt.want {
+				t.Errorf("Evaluator.evaluateFlag() = %v, want %v", got.Identifier, tt.want)
+			}
+			if stored := tt.store[simple+"/"+harness]; stored != tt.wantStore {
+				t.Errorf("sticky store assignment = %v, want %v", stored, tt.wantStore)
+			}
+		})
+	}
+}
+
+func TestEvaluator_evaluateFlagWithBucketBy(t *testing.T) {
+	distribution := &rest.Distribution{
+		Variations: []rest.WeightedVariation{
+			{Variation: identifierTrue, Weight: 50},
+			{Variation: identifierFalse, Weight: 50},
+		},
+	}
+	fc := rest.FeatureConfig{
+		Feature:      simple,
+		State:        rest.FeatureStateOn,
+		Variations:   boolVariations,
+		DefaultServe: rest.Serve{Distribution: distribution},
+	}
+	e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger(), WithBucketBy("accountId"))
+	byAccount := &rest.Distribution{BucketBy: "accountId", Variations: distribution.Variations}
+
+	for _, accountID := range []string{"account-1", "account-2", "account-3", "account-4"} {
+		first := &Target{Identifier: "first", Attributes: &map[string]interface{}{"accountId": accountID}}
+		second := &Target{Identifier: "second", Attributes: &map[string]interface{}{"accountId": accountID}}
+		got, err := e.newEvaluation(context.Background()).evaluateFlag(fc, first)
+		if err != nil {
+			t.Fatalf("Evaluator.evaluateFlag() error = %v", err)
+		}
+		if want := evaluateDistribution(byAccount, first); got.Identifier != want {
+			t.Errorf("Evaluator.evaluateFlag() for account %s = %v, want %v", accountID, got.Identifier, want)
+		}
+		state := e.newEvaluation(context.Background())
+		if other, _ := state.evaluateFlag(fc, second); other.Identifier != got.Identifier {
+			t.Errorf("Evaluator.evaluateFlag() for account %s = %v and %v, want the same variation",
+				accountID, got.Identifier, other.Identifier)
+		}
+	}
+	if distribution.BucketBy != "" {
+		t.Errorf("Evaluator.evaluateFlag() changed the flag bucketBy to %q", distribution.BucketBy)
+	}
+
+	// the bucketBy attribute of the flag takes precedence
+	distribution.BucketBy = identifier
+	for _, id := range []string{"first", "second", "third", "fourth"} {
+		target := &Target{Identifier: id, Attributes: &map[string]interface{}{"accountId": "account-1"}}
+		got, _ := e.newEvaluation(context.Background()).evaluateFlag(fc, target)
+		if want := evaluateDistribution(distribution, target); got.Identifier != want {
+			t.Errorf("Evaluator.evaluateFlag() for target %s = %v, want %v", id, got.Identifier, want)
+		}
+	}
+}
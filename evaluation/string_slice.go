@@ -1,5 +1,7 @@
 package evaluation
 
+import "strings"
+
 func contains(input []string, q string) bool {
 	for _, val := range input {
 		if val == q {
@@ -8,3 +10,21 @@ func contains(input []string, q string) bool {
 	}
 	return false
 }
+
+// matchAllowDeny reports whether any of the attribute values equals a positive token while none
+// equals a token negated with a "!" prefix
+func matchAllowDeny(tokens []string, attrValues []string) bool {
+	allowed := false
+	for _, token := range tokens {
+		if denied := strings.TrimPrefix(token, "!"); denied != token {
+			if contains(attrValues, denied) {
+				return false
+			}
+			continue
+		}
+		if contains(attrValues, token) {
+			allowed = true
+		}
+	}
+	return allowed
+}
@@ -2,7 +2,6 @@ package evaluation
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/harness/ff-golang-server-sdk/types"
 
@@ -15,30 +14,16 @@ type Target struct {
 	Name       string
 	Anonymous  *bool
 	Attributes *map[string]interface{}
+	// Parent is an optional target higher up in an entity hierarchy (for example the
+	// org of a user) which attributes are inherited from when missing on this target
+	Parent *Target
 }
 
-// GetAttrValue returns value from target with specified attribute
+// GetAttrValue returns value from target with specified attribute, attributes missing on the
+// target are inherited from its parents and dotted paths resolve nested attributes, just like
+// when the attribute is used in a clause
 func (t Target) GetAttrValue(attr string) reflect.Value {
-	var value reflect.Value
-	attrs := make(map[string]interface{})
-	if t.Attributes != nil {
-		attrs = *t.Attributes
-	}
-
-	attrVal, ok := attrs[attr] // first check custom attributes
-	if ok {
-		value = reflect.ValueOf(attrVal)
-	} else {
-		// We only have two fields here, so we will access the fields directly, and use reflection if we start adding
-		// more in the future
-		switch strings.ToLower(attr) {
-		case "identifier":
-			value = reflect.ValueOf(t.Identifier)
-		case "name":
-			value = reflect.ValueOf(t.Name)
-		}
-	}
-	return value
+	return getAttrValue(&t, attr)
 }
 
 // GetOperator returns interface based on attribute value
@@ -140,6 +140,42 @@ func TestTarget_GetAttrValue(t1 *testing.T) {
 	}
 }
 
+func TestTarget_GetAttrValueResolvesParentsAndPaths(t *testing.T) {
+	org := &Target{Identifier: "org", Attributes: &map[string]interface{}{
+		"plan":    "enterprise",
+		"account": map[string]interface{}{"region": "eu"},
+	}}
+	target := Target{Identifier: "john", Parent: org, Attributes: &map[string]interface{}{
+		"email":   "john@doe.com",
+		"profile": map[string]interface{}{"team": "platform"},
+	}}
+	tests := []struct {
+		name string
+		attr string
+		want interface{}
+	}{
+		{name: "own attribute", attr: "email", want: "john@doe.com"},
+		{name: "inherited attribute", attr: "plan", want: "enterprise"},
+		{name: "dotted path", attr: "profile.team", want: "platform"},
+		{name: "inherited dotted path", attr: "account.region", want: "eu"},
+		{name: "missing attribute", attr: "missing", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := target.GetAttrValue(tt.attr)
+			if tt.want == nil {
+				if got.IsValid() {
+					t.Errorf("GetAttrValue() = %v, want an invalid value", got)
+				}
+				return
+			}
+			if !got.IsValid() || !reflect.DeepEqual(got.Interface(), tt.want) {
+				t.Errorf("GetAttrValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestTarget_GetOperator1(t1 *testing.T) {
 	m := make(map[string]interface{})
 	m["anonymous"] = false
@@ -0,0 +1,152 @@
+package evaluation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+const (
+	traceOff          = "off"
+	tracePrerequisite = "prerequisite"
+	traceVariationMap = "variationMap"
+	traceRule         = "rule"
+	traceClause       = "clause"
+	traceSegment      = "segment"
+	traceDefaultServe = "defaultServe"
+)
+
+// traceNode records a single decision taken while evaluating a flag together
+// with the decisions it depended on
+type traceNode struct {
+	kind     string
+	label    string
+	matched  bool
+	children []*traceNode
+}
+
+// evaluationTrace collects the targeting path taken by a single evaluation.
+// All methods are safe to call on a nil trace so evaluations that are not
+// traced don't pay for it.
+type evaluationTrace struct {
+	roots []*traceNode
+	stack []*traceNode
+}
+
+func (t *evaluationTrace) begin(kind, label string) *traceNode {
+	if t == nil {
+		return nil
+	}
+	node := &traceNode{kind: kind, label: label}
+	if len(t.stack) > 0 {
+		parent := t.stack[len(t.stack)-1]
+		parent.children = append(parent.children, node)
+	} else {
+		t.roots = append(t.roots, node)
+	}
+	t.stack = append(t.stack, node)
+	return node
+}
+
+func (t *evaluationTrace) end(node *traceNode, matched bool) {
+	if t == nil || node == nil {
+		return
+	}
+	node.matched = matched
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+func (t *evaluationTrace) beginRule(rule *rest.ServingRule) *traceNode {
+	if t == nil {
+		return nil
+	}
+	return t.begin(traceRule, fmt.Sprintf("rule#%d", rule.Priority))
+}
+
+func (t *evaluationTrace) beginClause(clause *rest.Clause) *traceNode {
+	if t == nil {
+		return nil
+	}
+	parts := []string{"clause"}
+	if clause.Attribute != "" {
+		parts = append(parts, clause.Attribute)
+	}
+	if clause.Negate {
+		parts = append(parts, "not")
+	}
+	parts = append(parts, clause.Op, strings.Join(clause.Values, ","))
+	return t.begin(traceClause, strings.Join(parts, " "))
+}
+
+func (t *evaluationTrace) beginSegment(identifier string) *traceNode {
+	if t == nil {
+		return nil
+	}
+	return t.begin(traceSegment, "segment "+identifier)
+}
+
+func (t *evaluationTrace) beginPrerequisite(feature string) *traceNode {
+	if t == nil {
+		return nil
+	}
+	return t.begin(tracePrerequisite, "prerequisite "+feature)
+}
+
+// nameSegment adds the name of the segment being evaluated to its node label
+func (t *evaluationTrace) nameSegment(name string) {
+	if t == nil || len(t.stack) == 0 || name == "" {
+		return
+	}
+	node := t.stack[len(t.stack)-1]
+	if node.kind == traceSegment {
+		node.label += " (" + name + ")"
+	}
+}
+
+// path renders the trace as a compact ordered string, for example
+// "variationMap→miss; rule#2→clause country equal US match; serve blue"
+func (t *evaluationTrace) path(variation rest.Variation) string {
+	if t == nil {
+		return ""
+	}
+	steps := make([]string, 0, len(t.roots)+1)
+	for _, node := range t.roots {
+		steps = append(steps, node.render())
+	}
+	if variation.Identifier != "" {
+		steps = append(steps, "serve "+variation.Identifier)
+	}
+	return strings.Join(steps, "; ")
+}
+
+func (n *traceNode) render() string {
+	if len(n.children) == 0 {
+		if n.kind == traceOff || n.kind == traceDefaultServe {
+			return n.label
+		}
+		return n.label + "→" + n.outcome()
+	}
+	return n.label + "→" + n.renderChildren()
+}
+
+// renderChildren renders each child with its outcome, nested decisions such as the segments
+// of a segmentMatch clause are rendered in brackets
+func (n *traceNode) renderChildren() string {
+	children := make([]string, 0, len(n.children))
+	for _, child := range n.children {
+		rendered := child.label + " " + child.outcome()
+		if len(child.children) > 0 {
+			rendered += " [" + child.renderChildren() + "]"
+		}
+		children = append(children, rendered)
+	}
+	return strings.Join(children, ", ")
+}
+
+func (n *traceNode) outcome() string {
+	if n.matched {
+		return "match"
+	}
+	return "miss"
+}
@@ -0,0 +1,82 @@
+package evaluation
+
+import (
+	"context"
+
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+const (
+	evaluationSpanName = "feature_flag.evaluate"
+
+	// span attributes follow the OpenTelemetry semantic conventions for feature flags where they exist
+	spanAttributeKey               = "feature_flag.key"
+	spanAttributeVariant           = "feature_flag.variant"
+	spanAttributeReason            = "feature_flag.reason"
+	spanAttributeError             = "feature_flag.error"
+	spanAttributeSegmentCount      = "feature_flag.segment_count"
+	spanAttributePrerequisiteCount = "feature_flag.prerequisite_count"
+)
+
+// Tracer starts a span around every flag evaluation, it is typically a thin adapter over an
+// OpenTelemetry trace.Tracer so the SDK doesn't depend on a tracing library
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span records the attributes of a single flag evaluation
+type Span interface {
+	SetAttribute(key string, value interface{})
+	End()
+}
+
+// evaluationCounts counts the segments and prerequisites checked by a single evaluation.
+// All methods are safe to call on nil counts.
+type evaluationCounts struct {
+	segments      int
+	prerequisites int
+}
+
+func (c *evaluationCounts) segment() {
+	if c != nil {
+		c.segments++
+	}
+}
+
+func (c *evaluationCounts) prerequisite() {
+	if c != nil {
+		c.prerequisites++
+	}
+}
+
+// startSpan starts the evaluation span of the flag, which context the state carries from then on,
+// and returns the function ending the span
+func (e *evaluationState) startSpan(identifier string) func(variation rest.Variation, err error) {
+	if e.tracer == nil {
+		return func(rest.Variation, error) {}
+	}
+	ctx := e.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var span Span
+	e.ctx, span = e.tracer.Start(ctx, evaluationSpanName)
+	e.counts = &evaluationCounts{}
+	counts, reason := e.counts, e.reason
+	return func(variation rest.Variation, err error) {
+		span.SetAttribute(spanAttributeKey, identifier)
+		switch {
+		case err != nil:
+			span.SetAttribute(spanAttributeReason, string(ReasonError))
+			span.SetAttribute(spanAttributeError, err.Error())
+		case reason != nil:
+			span.SetAttribute(spanAttributeReason, string(reason.Kind))
+		}
+		if err == nil {
+			span.SetAttribute(spanAttributeVariant, variation.Identifier)
+		}
+		span.SetAttribute(spanAttributeSegmentCount, counts.segments)
+		span.SetAttribute(spanAttributePrerequisiteCount, counts.prerequisites)
+		span.End()
+	}
+}
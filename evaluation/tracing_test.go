@@ -0,0 +1,97 @@
+package evaluation
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+type fakeSpan struct {
+	name       string
+	attributes map[string]interface{}
+	ended      bool
+}
+
+func (s *fakeSpan) SetAttribute(key string, value interface{}) {
+	s.attributes[key] = value
+}
+
+func (s *fakeSpan) End() {
+	s.ended = true
+}
+
+// fakeTracer records the spans it starts
+type fakeTracer struct {
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &fakeSpan{name: name, attributes: map[string]interface{}{}}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func TestEvaluator_WithTracer(t *testing.T) {
+	flag := rest.FeatureConfig{
+		Feature: "traced",
+		State:   rest.FeatureStateOn,
+		Kind:    "boolean",
+		Prerequisites: &[]rest.Prerequisite{
+			{Feature: simple, Variations: []string{identifierTrue}},
+		},
+		Rules: &[]rest.ServingRule{{
+			RuleId:  "beta-users",
+			Clauses: []rest.Clause{{Op: segmentMatchOperator, Values: []string{beta}}},
+			Serve:   rest.Serve{Variation: &identifierTrue},
+		}},
+		DefaultServe: rest.Serve{Variation: &identifierFalse},
+		Variations:   boolVariations,
+	}
+	repo := NewTestRepository(map[string]rest.FeatureConfig{
+		flag.Feature: flag,
+		simple:       testRepo.flags[simple],
+	}, testRepo.segments)
+	tracer := &fakeTracer{}
+	e, _ := NewEvaluator(repo, nil, logger.NewNoOpLogger(), WithTracer(tracer))
+
+	if got := e.BoolVariation(flag.Feature, &Target{Identifier: harness}, false); !got {
+		t.Errorf("Evaluator.BoolVariation() = %v, want true", got)
+	}
+	if _, _, err := e.Evaluate("flagNotFound1000", &Target{Identifier: harness}, "boolean"); err == nil {
+		t.Errorf("Evaluator.Evaluate() expected an error for an unknown flag")
+	}
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("started %d spans, want 2", len(tracer.spans))
+	}
+	want := []map[string]interface{}{
+		{
+			spanAttributeKey:               flag.Feature,
+			spanAttributeVariant:           identifierTrue,
+			spanAttributeReason:            string(ReasonRuleMatch),
+			spanAttributeSegmentCount:      1,
+			spanAttributePrerequisiteCount: 1,
+		},
+		{
+			spanAttributeKey:               "flagNotFound1000",
+			spanAttributeReason:            string(ReasonError),
+			spanAttributeError:             tracer.spans[1].attributes[spanAttributeError],
+			spanAttributeSegmentCount:      0,
+			spanAttributePrerequisiteCount: 0,
+		},
+	}
+	for i, span := range tracer.spans {
+		if span.name != evaluationSpanName || !span.ended {
+			t.Errorf("span %d = %s ended %v, want %s ended", i, span.name, span.ended, evaluationSpanName)
+		}
+		if !reflect.DeepEqual(span.attributes, want[i]) {
+			t.Errorf("span %d attributes = %v, want %v", i, span.attributes, want[i])
+		}
+	}
+	if tracer.spans[1].attributes[spanAttributeError] == nil {
+		t.Errorf("span of a failed evaluation has no error attribute")
+	}
+}
@@ -0,0 +1,39 @@
+package evaluation
+
+import (
+	"reflect"
+	"strings"
+)
+
+// AttributeTransform normalizes an attribute value before clauses are evaluated against it,
+// for example lower casing emails. Transforms must be pure functions.
+type AttributeTransform func(value string) string
+
+// getAttrValue returns the attribute value of the target, or of the request header carried by
+// the evaluation context for header: attributes, with the transforms registered for the attribute
+// applied in registration order. List values are transformed per element.
+func (e *evaluationState) getAttrValue(target *Target, attr string) reflect.Value {
+	var value reflect.Value
+	if header := strings.TrimPrefix(attr, headerAttributePrefix); header != attr {
+		value = headerAttrValue(e.ctx, header)
+	} else {
+		value = getAttrValue(target, attr)
+	}
+	transforms := e.attributeTransforms[attr]
+	if len(transforms) == 0 || !value.IsValid() {
+		return value
+	}
+	transform := func(s string) string {
+		for _, t := range transforms {
+			s = t(s)
+		}
+		return s
+	}
+	if elements, ok := listAttrValues(value); ok {
+		for i := range elements {
+			elements[i] = transform(elements[i])
+		}
+		return reflect.ValueOf(elements)
+	}
+	return reflect.ValueOf(transform(formatAttrValue(value)))
+}
@@ -0,0 +1,44 @@
+package evaluation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+// normalizeGmail strips the +tag from gmail addresses
+func normalizeGmail(email string) string {
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 || email[at+1:] != "gmail.com" {
+		return email
+	}
+	local := email[:at]
+	if plus := strings.IndexByte(local, '+'); plus >= 0 {
+		local = local[:plus]
+	}
+	return local + email[at:]
+}
+
+func TestEvaluator_WithAttributeTransform(t *testing.T) {
+	clause := &rest.Clause{Attribute: "email", Op: equalSensitiveOperator, Values: []string{"user@gmail.com"}}
+	target := &Target{
+		Identifier: harness,
+		Attributes: &map[string]interface{}{"email": "User+tag@Gmail.com"},
+	}
+
+	e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger())
+	if e.newEvaluation(context.Background()).evaluateClause(clause, target) {
+		t.Errorf("Evaluator.evaluateClause() = true, want false without transforms")
+	}
+
+	e, _ = NewEvaluator(testRepo, nil, logger.NewNoOpLogger(),
+		WithAttributeTransform("email", strings.ToLower),
+		WithAttributeTransform("email", normalizeGmail),
+	)
+	if !e.newEvaluation(context.Background()).evaluateClause(clause, target) {
+		t.Errorf("Evaluator.evaluateClause() = false, want true with the email normalized")
+	}
+}
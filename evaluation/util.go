@@ -1,21 +1,52 @@
 package evaluation
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"math"
+	"net"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/harness/ff-golang-server-sdk/log"
 	"github.com/harness/ff-golang-server-sdk/rest"
 	"github.com/spaolacci/murmur3"
 )
 
+const identifierAttribute = "identifier"
+
 func getAttrValue(target *Target, attr string) reflect.Value {
 	var value reflect.Value
 	if target == nil {
 		return value
 	}
 
+	value = getOwnAttrValue(target, attr)
+	if value.IsValid() || target.Parent == nil {
+		return value
+	}
+
+	// attribute is missing so walk up the parent chain, guarding against targets
+	// that are (mis)configured as their own ancestors
+	visited := map[*Target]struct{}{target: {}}
+	for parent := target.Parent; parent != nil; parent = parent.Parent {
+		if _, ok := visited[parent]; ok {
+			break
+		}
+		visited[parent] = struct{}{}
+		value = getOwnAttrValue(parent, attr)
+		if value.IsValid() {
+			return value
+		}
+	}
+	return value
+}
+
+func getOwnAttrValue(target *Target, attr string) reflect.Value {
+	var value reflect.Value
 	attrs := make(map[string]interface{})
 	if target.Attributes != nil {
 		attrs = *target.Attributes
@@ -24,11 +55,13 @@ func getAttrValue(target *Target, attr string) reflect.Value {
 	attrVal, ok := attrs[attr] // first check custom attributes
 	if ok {
 		value = reflect.ValueOf(attrVal)
+	} else if strings.Contains(attr, ".") {
+		value = getNestedAttrValue(attrs, attr)
 	} else {
 		// We only have two fields here, so we will access the fields directly, and use reflection if we start adding
 		// more in the future
 		switch strings.ToLower(attr) {
-		case "identifier":
+		case identifierAttribute:
 			value = reflect.ValueOf(target.Identifier)
 		case "name":
 			value = reflect.ValueOf(target.Name)
@@ -37,6 +70,173 @@ func getAttrValue(target *Target, attr string) reflect.Value {
 	return value
 }
 
+// getNestedAttrValue resolves a dotted path like account.plan.tier by walking into nested maps and
+// structs, a path which doesn't resolve returns an invalid value just like a missing attribute
+func getNestedAttrValue(attrs map[string]interface{}, path string) reflect.Value {
+	value := reflect.ValueOf(attrs)
+	for _, key := range strings.Split(path, ".") {
+		value = nestedAttrValue(indirectAttrValue(value), key)
+		if !value.IsValid() {
+			return value
+		}
+	}
+	return indirectAttrValue(value)
+}
+
+// indirectAttrValue unwraps interfaces and dereferences pointers, times are kept as pointers as
+// formatAttrValue formats them directly
+func indirectAttrValue(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Interface || (value.Kind() == reflect.Ptr && value.Type() != timePtrType) {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+var timePtrType = reflect.TypeOf((*time.Time)(nil))
+
+// nestedAttrValue returns the map entry or the exported struct field named by key, struct fields
+// are matched by their json name first and then case insensitively by their field name
+func nestedAttrValue(value reflect.Value, key string) reflect.Value {
+	switch value.Kind() {
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}
+		}
+		return value.MapIndex(reflect.ValueOf(key).Convert(value.Type().Key()))
+	case reflect.Struct:
+		structType := value.Type()
+		for i := 0; i < structType.NumField(); i++ {
+			field := structType.Field(i)
+			if field.PkgPath == "" && strings.Split(field.Tag.Get("json"), ",")[0] == key {
+				return value.Field(i)
+			}
+		}
+		for i := 0; i < structType.NumField(); i++ {
+			field := structType.Field(i)
+			if field.PkgPath == "" && strings.EqualFold(field.Name, key) {
+				return value.Field(i)
+			}
+		}
+	case reflect.Invalid, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.Array, reflect.Chan,
+		reflect.Func, reflect.Interface, reflect.Ptr, reflect.Slice, reflect.String, reflect.UnsafePointer:
+	}
+	return reflect.Value{}
+}
+
+// formatAttrValue converts an attribute value into the string form operators compare against, nil
+// pointers and interfaces format as an empty string just like a missing attribute
+func formatAttrValue(value reflect.Value) string {
+	value = indirectAttrValue(value)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(value.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64)
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	case reflect.String:
+		return value.String()
+	case reflect.Invalid:
+		return ""
+	case reflect.Struct, reflect.Ptr:
+		// times are formatted the way the before and after operators parse them
+		switch t := value.Interface().(type) {
+		case time.Time:
+			return t.Format(time.RFC3339Nano)
+		case *time.Time:
+			if t == nil {
+				return ""
+			}
+			return t.Format(time.RFC3339Nano)
+		}
+		return fmt.Sprintf("%v", value.Interface())
+	case reflect.Array, reflect.Chan, reflect.Complex128, reflect.Complex64, reflect.Func, reflect.Interface,
+		reflect.Map, reflect.Slice, reflect.UnsafePointer:
+		return fmt.Sprintf("%v", value.Interface())
+	default:
+		// Use string formatting as last ditch effort for any unexpected values
+		return fmt.Sprintf("%v", value.Interface())
+	}
+}
+
+// normalizeClauseValue formats the clause value the way formatAttrValue formats attributes of the
+// given kind, so "True" matches a true boolean attribute and "007" an integer attribute of 7. Values
+// which don't parse as the kind are returned unchanged.
+func normalizeClauseValue(kind reflect.Kind, value string) string {
+	trimmed := strings.TrimSpace(value)
+	switch kind {
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(strings.ToLower(trimmed)); err == nil {
+			return strconv.FormatBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+			return strconv.FormatInt(i, 10)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u, err := strconv.ParseUint(trimmed, 10, 64); err == nil {
+			return strconv.FormatUint(u, 10)
+		}
+	case reflect.Invalid, reflect.Float32, reflect.Float64, reflect.String, reflect.Array, reflect.Chan,
+		reflect.Complex128, reflect.Complex64, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr,
+		reflect.Slice, reflect.Struct, reflect.UnsafePointer:
+	}
+	return value
+}
+
+// normalizeClauseValues applies normalizeClauseValue to every value, values are returned as is for
+// string attributes which are by far the most common
+func normalizeClauseValues(kind reflect.Kind, values []string) []string {
+	if kind == reflect.String {
+		return values
+	}
+	normalized := make([]string, len(values))
+	for i, value := range values {
+		normalized[i] = normalizeClauseValue(kind, value)
+	}
+	return normalized
+}
+
+// listAttrValues returns the formatted elements of a slice or array attribute value and
+// whether the value is a list at all
+func listAttrValues(value reflect.Value) ([]string, bool) {
+	elements, ok := listAttrElements(value)
+	if !ok {
+		return nil, false
+	}
+	formatted := make([]string, 0, len(elements))
+	for _, element := range elements {
+		formatted = append(formatted, formatAttrValue(element))
+	}
+	return formatted, true
+}
+
+// listAttrElements returns the elements of a slice or array attribute value with interfaces
+// unwrapped, so they keep their own kind, and whether the value is a list at all
+func listAttrElements(value reflect.Value) ([]reflect.Value, bool) {
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, false
+	}
+	elements := make([]reflect.Value, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		element := value.Index(i)
+		if element.Kind() == reflect.Interface {
+			element = element.Elem()
+		}
+		elements = append(elements, element)
+	}
+	return elements, true
+}
+
 func findVariation(variations []rest.Variation, identifier string) (rest.Variation, error) {
 	for _, variation := range variations {
 		if variation.Identifier == identifier {
@@ -47,19 +247,64 @@ func findVariation(variations []rest.Variation, identifier string) (rest.Variati
 }
 
 func getNormalizedNumber(identifier, bucketBy string) int {
-	value := []byte(strings.Join([]string{bucketBy, identifier}, ":"))
+	return bucketForKey(strings.Join([]string{bucketBy, identifier}, ":"))
+}
+
+// bucketForKey maps the key, which is the bucketBy attribute name and value joined by ":", to a bucket
+// between 1 and 100. The key is hashed with 32 bit MurmurHash3 using seed 0 and the unsigned hash taken
+// modulo 100 plus one. This must stay in line with the other Feature Flags SDKs so a target lands in
+// the same bucket whichever SDK evaluates it, the golden vectors in Test_bucketForKey guard it.
+func bucketForKey(key string) int {
+	return int(hashKey(key)%oneHundred) + 1
+}
+
+// hashKey hashes the key with 32 bit MurmurHash3 using seed 0
+func hashKey(key string) uint32 {
 	hasher := murmur3.New32()
-	_, err := hasher.Write(value)
+	_, err := hasher.Write([]byte(key))
 	if err != nil {
 		log.Debugf("error %v", err)
 	}
-	hash := int(hasher.Sum32())
-	return (hash % oneHundred) + 1
+	return hasher.Sum32()
+}
+
+// weightedBucket deterministically assigns the key to one of the buckets, given as "name:weight",
+// in proportion to their weights. Buckets with an invalid or non positive weight are ignored and
+// "" is returned when no bucket is left.
+func weightedBucket(key string, buckets []string) string {
+	names := make([]string, 0, len(buckets))
+	weights := make([]uint32, 0, len(buckets))
+	var total uint32
+	for _, bucket := range buckets {
+		i := strings.LastIndex(bucket, ":")
+		if i <= 0 {
+			continue
+		}
+		weight, err := strconv.ParseUint(strings.TrimSpace(bucket[i+1:]), 10, 16)
+		if err != nil || weight == 0 {
+			continue
+		}
+		names = append(names, bucket[:i])
+		weights = append(weights, uint32(weight))
+		total += uint32(weight)
+	}
+	if total == 0 {
+		return ""
+	}
+	position := hashKey(key) % total
+	for i, weight := range weights {
+		if position < weight {
+			return names[i]
+		}
+		position -= weight
+	}
+	return ""
 }
 
 func isEnabled(target *Target, bucketBy string, percentage int) bool {
-	value := getAttrValue(target, bucketBy)
-	identifier := value.String()
+	// bucket by the formatted value like evaluateDistribution, so non string attributes such as
+	// numeric ids spread across buckets instead of all hashing their type name
+	identifier := formatAttrValue(getAttrValue(target, bucketBy))
 	if identifier == "" {
 		return false
 	}
@@ -68,23 +313,147 @@ func isEnabled(target *Target, bucketBy string, percentage int) bool {
 	return percentage > 0 && bucketID <= percentage
 }
 
+// isSampled reports whether the value falls within the sampled percentage, values are bucketed
+// by the sample key and value joined by ":" so the same value is sampled consistently
+func isSampled(value, percentage, sampleKey string) bool {
+	if value == "" {
+		return false
+	}
+	p, err := strconv.Atoi(strings.TrimSpace(percentage))
+	if err != nil || p <= 0 {
+		return false
+	}
+	return bucketForKey(strings.Join([]string{sampleKey, value}, ":")) <= p
+}
+
+// evaluateDistribution serves the variation whose cumulative weight range the target is bucketed into.
+// Weights are expected to sum to 100. When they sum to less the rest of the range is deterministically
+// served the last variation, when they sum to more the ranges are cut off at 100 so variations past
+// it are never served. Evaluator.validateWeights warns about both cases.
 func evaluateDistribution(distribution *rest.Distribution, target *Target) string {
 	variation := ""
 	if distribution == nil {
 		return variation
 	}
 
+	// bucket by the identifier when the target doesn't have the bucketBy attribute
+	bucketBy := distribution.BucketBy
+	if target != nil && bucketBy != identifierAttribute && formatAttrValue(getAttrValue(target, bucketBy)) == "" {
+		log.Debugf("Target %s has no bucketBy attribute '%s', bucketing by identifier", target.Identifier, bucketBy)
+		bucketBy = identifierAttribute
+	}
+
 	totalPercentage := 0
 	for _, wv := range distribution.Variations {
 		variation = wv.Variation
 		totalPercentage += wv.Weight
-		if isEnabled(target, distribution.BucketBy, totalPercentage) {
+		if isEnabled(target, bucketBy, totalPercentage) {
 			return wv.Variation
 		}
 	}
 	return variation
 }
 
+// distributionWeight returns the percentage of targets the distribution serves the variation to
+func distributionWeight(distribution *rest.Distribution, variation string) int {
+	weight := 0
+	for _, wv := range distribution.Variations {
+		if wv.Variation == variation {
+			weight += wv.Weight
+		}
+	}
+	return weight
+}
+
+// compareValues compares a and b numerically when both parse as numbers, falling back
+// to a lexicographic comparison otherwise. The result is 0 if a == b, -1 if a < b and +1 if a > b.
+func compareValues(a, b string) int {
+	aNum, aErr := strconv.ParseFloat(a, 64)
+	bNum, bErr := strconv.ParseFloat(b, 64)
+	if aErr != nil || bErr != nil {
+		return strings.Compare(a, b)
+	}
+	switch {
+	case aNum < bNum:
+		return -1
+	case aNum > bNum:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareMixedValues is like compareValues but applies the policy when only one of a and b is numeric,
+// ok is false when the policy rejects the comparison
+func compareMixedValues(a, b string, policy MixedComparisonPolicy) (result int, ok bool) {
+	_, aErr := strconv.ParseFloat(a, 64)
+	_, bErr := strconv.ParseFloat(b, 64)
+	if (aErr == nil) == (bErr == nil) {
+		return compareValues(a, b), true
+	}
+	switch policy {
+	case MixedComparisonNumericFirst:
+		if aErr == nil {
+			return -1, true
+		}
+		return 1, true
+	case MixedComparisonFail:
+		return 0, false
+	case MixedComparisonLexicographic:
+		return strings.Compare(a, b), true
+	default:
+		return strings.Compare(a, b), true
+	}
+}
+
+// floatEqual reports whether a and b parse as numbers that differ by no more than epsilon
+func floatEqual(a, b string, epsilon float64) bool {
+	aNum, err := strconv.ParseFloat(a, 64)
+	if err != nil {
+		return false
+	}
+	bNum, err := strconv.ParseFloat(b, 64)
+	if err != nil {
+		return false
+	}
+	return math.Abs(aNum-bNum) <= epsilon
+}
+
+// foldValue trims and lower cases the value for case and whitespace insensitive comparisons
+func foldValue(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// matchGlob reports whether s matches the glob pattern, where '*' matches any
+// sequence of characters (including none) and '?' matches exactly one character
+func matchGlob(pattern, s string) bool {
+	p, str := []rune(pattern), []rune(s)
+	pIdx, sIdx := 0, 0
+	starIdx, matchIdx := -1, 0
+	for sIdx < len(str) {
+		switch {
+		case pIdx < len(p) && (p[pIdx] == '?' || p[pIdx] == str[sIdx]):
+			pIdx++
+			sIdx++
+		case pIdx < len(p) && p[pIdx] == '*':
+			starIdx = pIdx
+			matchIdx = sIdx
+			pIdx++
+		case starIdx != -1:
+			// backtrack and let the last star consume one more character
+			pIdx = starIdx + 1
+			matchIdx++
+			sIdx = matchIdx
+		default:
+			return false
+		}
+	}
+	for pIdx < len(p) && p[pIdx] == '*' {
+		pIdx++
+	}
+	return pIdx == len(p)
+}
+
 func isTargetInList(target *Target, targets []rest.Target) bool {
 	if targets == nil || target == nil {
 		return false
@@ -96,3 +465,110 @@ func isTargetInList(target *Target, targets []rest.Target) bool {
 	}
 	return false
 }
+
+// levenshtein returns the minimum number of single character insertions, deletions and
+// substitutions needed to turn a into b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, minInt(current[j-1]+1, previous[j-1]+cost))
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// SHA256AttributeHasher hashes an attribute value into its hex encoded SHA-256 digest, it can be
+// used with WithAttributeHasher and to hash target attributes compared by hashed_equal
+func SHA256AttributeHasher(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseBool parses boolean variation values ignoring case and surrounding whitespace, on top of
+// the forms accepted by strconv.ParseBool it accepts yes/no and on/off used by legacy flags
+func parseBool(value string) (bool, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "yes", "on":
+		return true, nil
+	case "no", "off":
+		return false, nil
+	default:
+		return strconv.ParseBool(value)
+	}
+}
+
+// epochMillisThreshold is the smallest epoch taken as milliseconds rather than seconds, as seconds
+// it is in the year 33658
+const epochMillisThreshold = 1e12
+
+// parseTime parses an RFC3339 timestamp or Unix epoch seconds or milliseconds, epochs of at least
+// epochMillisThreshold in absolute value are taken as milliseconds
+func parseTime(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if epoch >= epochMillisThreshold || epoch <= -epochMillisThreshold {
+			return time.Unix(0, epoch*int64(time.Millisecond)), true
+		}
+		return time.Unix(epoch, 0), true
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// compareTimes compares a and b chronologically, ok is false if either fails to parse
+func compareTimes(a, b string) (result int, ok bool) {
+	at, aOk := parseTime(a)
+	bt, bOk := parseTime(b)
+	if !aOk || !bOk {
+		return 0, false
+	}
+	switch {
+	case at.Before(bt):
+		return -1, true
+	case at.After(bt):
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+// ipInAnyCIDR reports whether ip falls within any of the CIDR ranges, invalid ranges are skipped
+func ipInAnyCIDR(ip string, cidrs []string) bool {
+	addr := net.ParseIP(strings.TrimSpace(ip))
+	if addr == nil {
+		return false
+	}
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			continue
+		}
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
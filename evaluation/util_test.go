@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/harness/ff-golang-server-sdk/rest"
 )
@@ -38,6 +39,70 @@ func Test_getAttrValueIsNil(t *testing.T) {
 			},
 			want: reflect.Value{},
 		},
+		{
+			name: "missing attribute with a cyclic parent chain should return Value{}",
+			args: args{
+				target: func() *Target {
+					child := &Target{Identifier: harness}
+					child.Parent = &Target{Identifier: org, Parent: child}
+					return child
+				}(),
+				attr: "email",
+			},
+			want: reflect.Value{},
+		},
+		{
+			name: "nested attribute path dead ending in the middle should return Value{}",
+			args: args{
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"device": map[string]interface{}{"os": "ios"},
+					},
+				},
+				attr: "device.os.version",
+			},
+			want: reflect.Value{},
+		},
+		{
+			name: "nested attribute path with a missing level should return Value{}",
+			args: args{
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"device": map[string]interface{}{"os": "ios"},
+					},
+				},
+				attr: "device.model.name",
+			},
+			want: reflect.Value{},
+		},
+		{
+			name: "nested attribute path to an unexported struct field should return Value{}",
+			args: args{
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"account": nestedAccount{Plan: &nestedPlan{owner: harness}},
+					},
+				},
+				attr: "account.plan.owner",
+			},
+			want: reflect.Value{},
+		},
+		{
+			name: "nested attribute path through a nil pointer should return Value{}",
+			args: args{
+				target: &Target{
+					Identifier: harness,
+					Attributes: &map[string]interface{}{
+						"account": nestedAccount{},
+					},
+				},
+				attr: "account.plan.tier",
+			},
+			want: reflect.Value{},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -48,6 +113,16 @@ func Test_getAttrValueIsNil(t *testing.T) {
 	}
 }
 
+type nestedPlan struct {
+	Tier  string `json:"plan_tier"`
+	Seats int
+	owner string
+}
+
+type nestedAccount struct {
+	Plan *nestedPlan `json:"plan"`
+}
+
 func Test_getAttrValue(t *testing.T) {
 	email := "john@doe.com"
 	type args struct {
@@ -138,6 +213,119 @@ func Test_getAttrValue(t *testing.T) {
 			want:    reflect.ValueOf(true),
 			wantStr: "true",
 		},
+		{
+			name: "check attribute inherited from parent target",
+			args: args{
+				target: &Target{
+					Identifier: identifier,
+					Parent: &Target{
+						Identifier: harness,
+						Attributes: &map[string]interface{}{
+							"email": email,
+						},
+					},
+				},
+				attr: "email",
+			},
+			want:    reflect.ValueOf(email),
+			wantStr: email,
+		},
+		{
+			name: "check attribute inherited from grandparent target",
+			args: args{
+				target: &Target{
+					Identifier: identifier,
+					Parent: &Target{
+						Identifier: harness,
+						Parent: &Target{
+							Identifier: org,
+							Attributes: &map[string]interface{}{
+								"active": true,
+							},
+						},
+					},
+				},
+				attr: "active",
+			},
+			want:    reflect.ValueOf(true),
+			wantStr: "true",
+		},
+		{
+			name: "check own attribute takes precedence over parent",
+			args: args{
+				target: &Target{
+					Identifier: identifier,
+					Attributes: &map[string]interface{}{
+						"age": 123,
+					},
+					Parent: &Target{
+						Identifier: harness,
+						Attributes: &map[string]interface{}{
+							"age": 456,
+						},
+					},
+				},
+				attr: "age",
+			},
+			want:    reflect.ValueOf(123),
+			wantStr: "123",
+		},
+		{
+			name: "check nested attribute path",
+			args: args{
+				target: &Target{
+					Identifier: identifier,
+					Attributes: &map[string]interface{}{
+						"device": map[string]interface{}{"os": "ios"},
+					},
+				},
+				attr: "device.os",
+			},
+			want:    reflect.ValueOf("ios"),
+			wantStr: "ios",
+		},
+		{
+			name: "check nested attribute path through maps",
+			args: args{
+				target: &Target{
+					Identifier: identifier,
+					Attributes: &map[string]interface{}{
+						"account": map[string]interface{}{"plan": map[string]string{"tier": "enterprise"}},
+					},
+				},
+				attr: "account.plan.tier",
+			},
+			want:    reflect.ValueOf("enterprise"),
+			wantStr: "enterprise",
+		},
+		{
+			name: "check nested attribute path through structs",
+			args: args{
+				target: &Target{
+					Identifier: identifier,
+					Attributes: &map[string]interface{}{
+						"account": &nestedAccount{Plan: &nestedPlan{Tier: "team", Seats: 25}},
+					},
+				},
+				attr: "account.plan.seats",
+			},
+			want:    reflect.ValueOf(25),
+			wantStr: "25",
+		},
+		{
+			name: "check nested attribute path by json name",
+			args: args{
+				target: &Target{
+					Identifier: identifier,
+					Attributes: &map[string]interface{}{
+						"account": nestedAccount{Plan: &nestedPlan{Tier: "team"}},
+					},
+				},
+				attr: "account.plan.plan_tier",
+			},
+			want:    reflect.ValueOf("team"),
+			wantStr: "team",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -217,6 +405,103 @@ func Test_findVariation(t *testing.T) {
 	}
 }
 
+func Test_bucketForKey(t *testing.T) {
+	// golden vectors shared by the SDKs, a change means targets land in other buckets than
+	// they do when evaluated by the other SDKs or by earlier versions of this one
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{key: "identifier:enver", want: 67},
+		{key: "identifier:harness", want: 6},
+		{key: "identifier:Harness", want: 22},
+		{key: "identifier:test", want: 57},
+		{key: "identifier:target1", want: 75},
+		{key: "identifier:target2", want: 26},
+		{key: "identifier:", want: 92},
+		{key: "identifier:ünïcödé", want: 94},
+		{key: "email:enver.bisevac@harness.io", want: 32},
+		{key: "accountId:abc123", want: 2},
+		{key: "name:Jane Doe", want: 58},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := bucketForKey(tt.key); got != tt.want {
+				t.Errorf("bucketForKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isSampled(t *testing.T) {
+	const targets = 10000
+	for _, percentage := range []int{0, 10, 25, 50, 100} {
+		t.Run(strconv.Itoa(percentage), func(t *testing.T) {
+			sampled := 0
+			for i := 0; i < targets; i++ {
+				identifier := "target" + strconv.Itoa(i)
+				got := isSampled(identifier, strconv.Itoa(percentage), "telemetry")
+				if got != isSampled(identifier, strconv.Itoa(percentage), "telemetry") {
+					t.Fatalf("isSampled() is not stable for %s", identifier)
+				}
+				if got {
+					sampled++
+				}
+			}
+			want := targets * percentage / 100
+			if diff := sampled - want; diff > targets/100 || diff < -targets/100 {
+				t.Errorf("isSampled() sampled %d of %d targets, want about %d", sampled, targets, want)
+			}
+		})
+	}
+
+	// a target sampled under one key isn't necessarily sampled under another
+	differs := false
+	for i := 0; i < 100 && !differs; i++ {
+		identifier := "target" + strconv.Itoa(i)
+		differs = isSampled(identifier, "50", "telemetry") != isSampled(identifier, "50", "tracing")
+	}
+	if !differs {
+		t.Errorf("isSampled() samples the same targets for different sample keys")
+	}
+
+	for _, percentage := range []string{"", "ten", "-5"} {
+		if isSampled(harness, percentage, "telemetry") {
+			t.Errorf("isSampled() with percentage %q = true, want false", percentage)
+		}
+	}
+	if isSampled("", "100", "telemetry") {
+		t.Errorf("isSampled() with an empty value = true, want false")
+	}
+}
+
+func Test_weightedBucket(t *testing.T) {
+	buckets := []string{"control:50", "a:30", "b:20"}
+	const targets = 10000
+	counts := map[string]int{}
+	for i := 0; i < targets; i++ {
+		key := "identifier:target" + strconv.Itoa(i)
+		got := weightedBucket(key, buckets)
+		if again := weightedBucket(key, buckets); again != got {
+			t.Fatalf("weightedBucket() is not stable for %s, got %s then %s", key, got, again)
+		}
+		counts[got]++
+	}
+	for bucket, weight := range map[string]int{"control": 50, "a": 30, "b": 20} {
+		want := targets * weight / 100
+		if diff := counts[bucket] - want; diff > targets/100 || diff < -targets/100 {
+			t.Errorf("weightedBucket() assigned %d of %d targets to %s, want about %d", counts[bucket], targets, bucket, want)
+		}
+	}
+
+	if got := weightedBucket("identifier:harness", []string{"a:0", "b", "c:x", ":5"}); got != "" {
+		t.Errorf("weightedBucket() without valid buckets = %q, want none", got)
+	}
+	if got := weightedBucket("identifier:harness", []string{"a:0", "only:1"}); got != "only" {
+		t.Errorf("weightedBucket() = %q, want only", got)
+	}
+}
+
 func Test_getNormalizedNumber(t *testing.T) {
 	type args struct {
 		identifier string
@@ -302,6 +587,31 @@ func Test_isEnabled(t *testing.T) {
 	}
 }
 
+func Test_isEnabledSpreadsBucketByValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		value func(i int) interface{}
+	}{
+		{name: "int ids", value: func(i int) interface{} { return i }},
+		{name: "float ids", value: func(i int) interface{} { return float64(i) }},
+		{name: "string ids", value: func(i int) interface{} { return strconv.Itoa(i) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enabled := 0
+			for i := 0; i < 200; i++ {
+				target := &Target{Identifier: harness, Attributes: &map[string]interface{}{"accountId": tt.value(i)}}
+				if isEnabled(target, "accountId", 50) {
+					enabled++
+				}
+			}
+			if enabled < 60 || enabled > 140 {
+				t.Errorf("isEnabled() enabled %d of 200 targets in a 50%% rollout, want them spread", enabled)
+			}
+		})
+	}
+}
+
 func Test_evaluateDistribution(t *testing.T) {
 	type args struct {
 		distribution *rest.Distribution
@@ -396,6 +706,40 @@ func Test_evaluateDistribution(t *testing.T) {
 			},
 			want: "A",
 		},
+		{
+			name: "weights under 100 serve the remainder the last variation",
+			args: args{
+				distribution: &rest.Distribution{
+					BucketBy: identifier,
+					Variations: []rest.WeightedVariation{
+						{Variation: "A", Weight: 10},
+						{Variation: "B", Weight: 20},
+						{Variation: "C", Weight: 30},
+					},
+				},
+				target: &Target{
+					Identifier: "enver",
+				},
+			},
+			want: "C",
+		},
+		{
+			name: "weights over 100 are cut off at 100",
+			args: args{
+				distribution: &rest.Distribution{
+					BucketBy: identifier,
+					Variations: []rest.WeightedVariation{
+						{Variation: "A", Weight: 60},
+						{Variation: "B", Weight: 60},
+						{Variation: "C", Weight: 30},
+					},
+				},
+				target: &Target{
+					Identifier: "enver",
+				},
+			},
+			want: "B",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -460,3 +804,307 @@ func Test_isTargetInList(t *testing.T) {
 		})
 	}
 }
+
+func Test_matchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		want    bool
+	}{
+		{pattern: "*@example.com", s: "john@example.com", want: true},
+		{pattern: "*@example.com", s: "john@example.org", want: false},
+		{pattern: "j?hn@*", s: "john@example.com", want: true},
+		{pattern: "*", s: "", want: true},
+		{pattern: "a*b*c", s: "aXXbYYc", want: true},
+		{pattern: "a*b*c", s: "aXXbYY", want: false},
+		{pattern: "harness", s: "harness", want: true},
+		{pattern: "harness", s: "harnesses", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.s, func(t *testing.T) {
+			if got := matchGlob(tt.pattern, tt.s); got != tt.want {
+				t.Errorf("matchGlob() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_compareValues(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{a: "9", b: "10", want: -1},
+		{a: "100", b: "99", want: 1},
+		{a: "10", b: "10.0", want: 0},
+		{a: "-1.5", b: "1", want: -1},
+		{a: "B", b: "A", want: 1},
+		{a: "100", b: "abc", want: -1},
+		{a: "abc", b: "100", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			if got := compareValues(tt.a, tt.b); got != tt.want {
+				t.Errorf("compareValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_floatEqual(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       string
+		b       string
+		epsilon float64
+		want    bool
+	}{
+		{name: "exactly equal", a: "4.5", b: "4.5", epsilon: 1e-9, want: true},
+		{name: "within epsilon", a: "0.1", b: "0.1000000001", epsilon: 1e-9, want: true},
+		{name: "beyond epsilon", a: "0.1", b: "0.100001", epsilon: 1e-9, want: false},
+		{name: "within a configured epsilon", a: "0.1", b: "0.100001", epsilon: 1e-3, want: true},
+		{name: "non numeric attribute", a: "abc", b: "0.1", epsilon: 1e-9, want: false},
+		{name: "non numeric value", a: "0.1", b: "abc", epsilon: 1e-9, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := floatEqual(tt.a, tt.b, tt.epsilon); got != tt.want {
+				t.Errorf("floatEqual() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+var (
+	formatTime   = time.Date(2021, time.June, 1, 12, 30, 0, 500000000, time.UTC)
+	formatString = harness
+)
+
+type nestedOwner struct {
+	Owner interface{}
+}
+
+func Test_formatAttrValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value reflect.Value
+		want  string
+	}{
+		{name: "invalid", value: reflect.Value{}, want: ""},
+		{name: "string", value: reflect.ValueOf(harness), want: harness},
+		{name: "bool", value: reflect.ValueOf(true), want: "true"},
+		{name: "int", value: reflect.ValueOf(123), want: "123"},
+		{name: "int8", value: reflect.ValueOf(int8(-8)), want: "-8"},
+		{name: "int16", value: reflect.ValueOf(int16(16)), want: "16"},
+		{name: "int32", value: reflect.ValueOf(int32(32)), want: "32"},
+		{name: "int64", value: reflect.ValueOf(int64(64)), want: "64"},
+		{name: "uint", value: reflect.ValueOf(uint(1)), want: "1"},
+		{name: "uint8", value: reflect.ValueOf(uint8(8)), want: "8"},
+		{name: "uint16", value: reflect.ValueOf(uint16(16)), want: "16"},
+		{name: "uint32", value: reflect.ValueOf(uint32(32)), want: "32"},
+		{name: "uint64", value: reflect.ValueOf(uint64(64)), want: "64"},
+		{name: "float32", value: reflect.ValueOf(float32(0.1)), want: "0.1"},
+		{name: "float64", value: reflect.ValueOf(4.5), want: "4.5"},
+		{name: "float64 without fraction", value: reflect.ValueOf(float64(42)), want: "42"},
+		{name: "uintptr", value: reflect.ValueOf(uintptr(7)), want: "7"},
+		{name: "time", value: reflect.ValueOf(formatTime), want: "2021-06-01T12:30:00.5Z"},
+		{name: "time pointer", value: reflect.ValueOf(&formatTime), want: "2021-06-01T12:30:00.5Z"},
+		{name: "nil time pointer", value: reflect.ValueOf((*time.Time)(nil)), want: ""},
+		{name: "slice", value: reflect.ValueOf([]string{"a", "b"}), want: "[a b]"},
+		{name: "string pointer", value: reflect.ValueOf(&formatString), want: harness},
+		{name: "nil pointer", value: reflect.ValueOf((*nestedPlan)(nil)), want: ""},
+		{name: "nil interface", value: reflect.ValueOf(&nestedOwner{}).Elem().Field(0), want: ""},
+		{
+			name: "nil pointer reached through a dotted path",
+			value: getAttrValue(&Target{Identifier: harness, Attributes: &map[string]interface{}{
+				"account.plan": (*nestedPlan)(nil),
+			}}, "account.plan"),
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatAttrValue(tt.value); got != tt.want {
+				t.Errorf("formatAttrValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_evaluateDistributionBucketBy(t *testing.T) {
+	distribution := &rest.Distribution{
+		BucketBy: "accountId",
+		Variations: []rest.WeightedVariation{
+			{Variation: "A", Weight: 50},
+			{Variation: "B", Weight: 50},
+		},
+	}
+	byIdentifier := &rest.Distribution{
+		BucketBy:   identifier,
+		Variations: distribution.Variations,
+	}
+	for _, accountID := range []interface{}{"account-1", "account-2", "account-3", 42, 1337} {
+		first := &Target{
+			Identifier: "first",
+			Attributes: &map[string]interface{}{"accountId": accountID},
+		}
+		second := &Target{
+			Identifier: "second",
+			Attributes: &map[string]interface{}{"accountId": accountID},
+		}
+		if got, want := evaluateDistribution(distribution, second), evaluateDistribution(distribution, first); got != want {
+			t.Errorf("evaluateDistribution() for account %v = %v, want %v", accountID, got, want)
+		}
+	}
+
+	for _, id := range []string{"first", "second", "third", "fourth"} {
+		target := &Target{Identifier: id}
+		if got, want := evaluateDistribution(distribution, target), evaluateDistribution(byIdentifier, target); got != want {
+			t.Errorf("evaluateDistribution() for target %s missing accountId = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func Test_levenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "exampl", b: "example", want: 1},
+		{a: "example", b: "exampel", want: 2},
+		{a: "kitten", b: "sitting", want: 3},
+		{a: "", b: "abc", want: 3},
+		{a: "héllo", b: "hello", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			if got := levenshtein(tt.a, tt.b); got != tt.want {
+				t.Errorf("levenshtein() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseBool(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    bool
+		wantErr bool
+	}{
+		{value: "true", want: true},
+		{value: "TrUe", want: true},
+		{value: " true ", want: true},
+		{value: "1", want: true},
+		{value: "t", want: true},
+		{value: "Yes", want: true},
+		{value: "ON", want: true},
+		{value: "false", want: false},
+		{value: "FALSE", want: false},
+		{value: "0", want: false},
+		{value: "f", want: false},
+		{value: "no", want: false},
+		{value: "Off", want: false},
+		{value: "", wantErr: true},
+		{value: "2", wantErr: true},
+		{value: "enabled", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseBool(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseBool() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseBool() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_compareTimes(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   string
+		want   int
+		wantOk bool
+	}{
+		{name: "rfc3339 after", a: "2024-03-01T12:00:00Z", b: "2024-01-01T00:00:00Z", want: 1, wantOk: true},
+		{name: "rfc3339 with offsets", a: "2024-01-01T01:00:00+01:00", b: "2024-01-01T00:00:00Z", want: 0, wantOk: true},
+		{name: "epoch before rfc3339", a: "1672531200", b: "2024-01-01T00:00:00Z", want: -1, wantOk: true},
+		{name: "rfc3339 equal to epoch", a: "2024-01-01T00:00:00Z", b: "1704067200", want: 0, wantOk: true},
+		{name: "epoch millis equal to epoch", a: "1704067200000", b: "1704067200", want: 0, wantOk: true},
+		{name: "epoch millis after rfc3339", a: "1704067200001", b: "2024-01-01T00:00:00Z", want: 1, wantOk: true},
+		{name: "date without time", a: "2024-01-01", b: "1704067200", wantOk: false},
+		{name: "unparseable", a: "yesterday", b: "2024-01-01T00:00:00Z", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := compareTimes(tt.a, tt.b)
+			if ok != tt.wantOk {
+				t.Errorf("compareTimes() ok = %v, want %v", ok, tt.wantOk)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("compareTimes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_evaluateDistributionWeightSums(t *testing.T) {
+	under := &rest.Distribution{
+		BucketBy: identifier,
+		Variations: []rest.WeightedVariation{
+			{Variation: "A", Weight: 50},
+			{Variation: "B", Weight: 45},
+		},
+	}
+	over := &rest.Distribution{
+		BucketBy: identifier,
+		Variations: []rest.WeightedVariation{
+			{Variation: "A", Weight: 60},
+			{Variation: "B", Weight: 40},
+			{Variation: "C", Weight: 20},
+		},
+	}
+	for i := 0; i < 1000; i++ {
+		target := &Target{Identifier: "target" + strconv.Itoa(i)}
+		bucket := getNormalizedNumber(target.Identifier, identifier)
+		if got := evaluateDistribution(under, target); got == "" || (bucket > 95 && got != "B") {
+			t.Errorf("evaluateDistribution() for bucket %d with weights under 100 = %q, want B", bucket, got)
+		}
+		if got := evaluateDistribution(over, target); got == "C" {
+			t.Errorf("evaluateDistribution() for bucket %d with weights over 100 = C, want A or B", bucket)
+		}
+	}
+}
+
+func Test_compareMixedValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   string
+		policy MixedComparisonPolicy
+		want   int
+		wantOk bool
+	}{
+		{name: "lexicographic", a: "5", b: "abc", policy: MixedComparisonLexicographic, want: -1, wantOk: true},
+		{name: "lexicographic non numeric first", a: "abc", b: "5", policy: MixedComparisonLexicographic, want: 1, wantOk: true},
+		{name: "numeric first", a: "5", b: "abc", policy: MixedComparisonNumericFirst, want: -1, wantOk: true},
+		{name: "numeric first with the number second", a: "abc", b: "500", policy: MixedComparisonNumericFirst, want: 1, wantOk: true},
+		{name: "numeric first orders before letters sorting first", a: "10", b: "+", policy: MixedComparisonNumericFirst, want: -1, wantOk: true},
+		{name: "fail", a: "5", b: "abc", policy: MixedComparisonFail, wantOk: false},
+		{name: "fail with both numeric", a: "5", b: "10", policy: MixedComparisonFail, want: -1, wantOk: true},
+		{name: "fail with both non numeric", a: "b", b: "a", policy: MixedComparisonFail, want: 1, wantOk: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := compareMixedValues(tt.a, tt.b, tt.policy)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("compareMixedValues() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
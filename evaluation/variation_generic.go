@@ -0,0 +1,22 @@
+//go:build go1.18
+// +build go1.18
+
+package evaluation
+
+// TypedVariation evaluates the flag for the target as T, dispatching to BoolVariation, StringVariation,
+// IntVariation or NumberVariation, so it behaves exactly like the typed methods and returns
+// defaultValue on error
+func TypedVariation[T bool | string | int | float64](e *Evaluator, identifier string, target *Target, defaultValue T) T {
+	var value interface{}
+	switch d := interface{}(defaultValue).(type) {
+	case bool:
+		value = e.BoolVariation(identifier, target, d)
+	case string:
+		value = e.StringVariation(identifier, target, d)
+	case int:
+		value = e.IntVariation(identifier, target, d)
+	case float64:
+		value = e.NumberVariation(identifier, target, d)
+	}
+	return value.(T)
+}
@@ -0,0 +1,68 @@
+//go:build go1.18
+// +build go1.18
+
+package evaluation
+
+import (
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/logger"
+)
+
+func TestTypedVariation(t *testing.T) {
+	e, _ := NewEvaluator(testRepo, nil, logger.NewNoOpLogger())
+	target := &Target{Identifier: harness}
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{
+			name: "bool flag",
+			got:  TypedVariation(e, simple, target, false),
+			want: e.BoolVariation(simple, target, false),
+		},
+		{
+			name: "bool of a string flag returns the default",
+			got:  TypedVariation(e, theme, target, true),
+			want: true,
+		},
+		{
+			name: "string flag",
+			got:  TypedVariation(e, theme, target, darktheme),
+			want: e.StringVariation(theme, target, darktheme),
+		},
+		{
+			name: "string of a bool flag returns the default",
+			got:  TypedVariation(e, simple, target, darktheme),
+			want: darktheme,
+		},
+		{
+			name: "int flag",
+			got:  TypedVariation(e, weight, target, 0),
+			want: e.IntVariation(weight, target, 0),
+		},
+		{
+			name: "int of a bool flag returns the default",
+			got:  TypedVariation(e, simple, target, 7),
+			want: 7,
+		},
+		{
+			name: "number flag",
+			got:  TypedVariation(e, weight, target, 0.5),
+			want: e.NumberVariation(weight, target, 0.5),
+		},
+		{
+			name: "number of a json flag returns the default",
+			got:  TypedVariation(e, org, target, 0.5),
+			want: 0.5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("TypedVariation() = %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
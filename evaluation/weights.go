@@ -0,0 +1,36 @@
+package evaluation
+
+import "github.com/harness/ff-golang-server-sdk/rest"
+
+// validateWeights warns about the distributions of the flag whose weights don't sum to 100, once
+// per flag version
+func (e Evaluator) validateWeights(flag rest.FeatureConfig) {
+	if !e.checks.first(weightsCheck, flag) {
+		return
+	}
+	if flag.DefaultServe.Distribution != nil {
+		e.validateDistribution(flag.Feature, flag.DefaultServe.Distribution)
+	}
+	if flag.Rules != nil {
+		for _, rule := range *flag.Rules {
+			if rule.Serve.Distribution != nil {
+				e.validateDistribution(flag.Feature, rule.Serve.Distribution)
+			}
+		}
+	}
+}
+
+func (e Evaluator) validateDistribution(feature string, distribution *rest.Distribution) {
+	total := 0
+	for _, wv := range distribution.Variations {
+		total += wv.Weight
+	}
+	if total < oneHundred && len(distribution.Variations) > 0 {
+		e.logger.Warnf("Flag %s has distribution weights summing to %d instead of %d, the remainder is served "+
+			"the last variation %s", feature, total, oneHundred,
+			distribution.Variations[len(distribution.Variations)-1].Variation)
+	} else if total > oneHundred {
+		e.logger.Warnf("Flag %s has distribution weights summing to %d instead of %d, weights past %d are ignored",
+			feature, total, oneHundred, oneHundred)
+	}
+}
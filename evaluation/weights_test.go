@@ -0,0 +1,47 @@
+package evaluation
+
+import (
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/rest"
+)
+
+func TestEvaluator_validateWeights(t *testing.T) {
+	version := int64(1)
+	flag := rest.FeatureConfig{
+		Feature: "underweight",
+		State:   rest.FeatureStateOn,
+		Kind:    "boolean",
+		Version: &version,
+		DefaultServe: rest.Serve{Distribution: &rest.Distribution{
+			BucketBy: identifier,
+			Variations: []rest.WeightedVariation{
+				{Variation: identifierTrue, Weight: 50},
+				{Variation: identifierFalse, Weight: 45},
+			},
+		}},
+		Variations: boolVariations,
+	}
+	flags := map[string]rest.FeatureConfig{flag.Feature: flag}
+	recorder := &warnRecorder{}
+	e, _ := NewEvaluator(NewTestRepository(flags, nil), nil, recorder)
+	target := &Target{Identifier: harness}
+
+	for i := 0; i < 3; i++ {
+		e.BoolVariation(flag.Feature, target, false)
+	}
+	if len(recorder.warnings) != 1 {
+		t.Fatalf("logged %d warnings for a single flag version, want 1: %v", len(recorder.warnings),
+			recorder.warnings)
+	}
+
+	next := int64(2)
+	flag.Version = &next
+	flags[flag.Feature] = flag
+	e.BoolVariation(flag.Feature, target, false)
+	e.BoolVariation(flag.Feature, target, false)
+	if len(recorder.warnings) != 2 {
+		t.Errorf("logged %d warnings after a new flag version, want 2: %v", len(recorder.warnings),
+			recorder.warnings)
+	}
+}
@@ -16,7 +16,7 @@ require (
 	github.com/r3labs/sse v0.0.0-20201126193848-34e640891548
 	github.com/spaolacci/murmur3 v1.1.0
 	github.com/stretchr/testify v1.7.1
-	go.uber.org/multierr v1.6.0 // indirect
+	go.uber.org/multierr v1.6.0
 	go.uber.org/zap v1.16.0
 	gopkg.in/cenkalti/backoff.v1 v1.1.0
 )
@@ -2,12 +2,16 @@ package repository
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/harness/ff-golang-server-sdk/log"
 	"github.com/harness/ff-golang-server-sdk/rest"
 	"github.com/harness/ff-golang-server-sdk/storage"
 )
 
+const flagKeyPrefix = "flags/"
+
 // Repository interface for data providers
 type Repository interface {
 	GetFlag(identifier string) (rest.FeatureConfig, error)
@@ -84,6 +88,32 @@ func (r FFRepository) GetFlag(identifier string) (rest.FeatureConfig, error) {
 	return r.getFlagAndCache(identifier, true)
 }
 
+// GetFlags returns all flags from offline storage or cache ordered by identifier
+func (r FFRepository) GetFlags() ([]rest.FeatureConfig, error) {
+	flags := make([]rest.FeatureConfig, 0)
+	if r.storage != nil {
+		for _, value := range r.storage.List() {
+			if flag, ok := value.(rest.FeatureConfig); ok {
+				flags = append(flags, flag)
+			}
+		}
+	} else {
+		for _, key := range r.cache.Keys() {
+			flagKey, ok := key.(string)
+			if !ok || !strings.HasPrefix(flagKey, flagKeyPrefix) {
+				continue
+			}
+			if value, ok := r.cache.Get(flagKey); ok {
+				flags = append(flags, value.(rest.FeatureConfig))
+			}
+		}
+	}
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Feature < flags[j].Feature
+	})
+	return flags, nil
+}
+
 func (r FFRepository) getSegmentAndCache(identifier string, cacheable bool) (rest.Segment, error) {
 	segmentKey := formatSegmentKey(identifier)
 	flag, ok := r.cache.Get(segmentKey)
@@ -202,7 +232,7 @@ func (r FFRepository) Close() {
 }
 
 func formatFlagKey(identifier string) string {
-	return "flags/" + identifier
+	return flagKeyPrefix + identifier
 }
 
 func formatSegmentKey(identifier string) string {
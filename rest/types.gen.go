@@ -21,6 +21,18 @@ const (
 	FeatureStateOn  FeatureState = "on"
 )
 
+// Defines values for SegmentLogic.
+const (
+	SegmentLogicAnd SegmentLogic = "and"
+	SegmentLogicOr  SegmentLogic = "or"
+)
+
+// Defines values for ServingRuleLogic.
+const (
+	ServingRuleLogicAnd ServingRuleLogic = "and"
+	ServingRuleLogicOr  ServingRuleLogic = "or"
+)
+
 // AuthenticationRequest defines model for AuthenticationRequest.
 type AuthenticationRequest struct {
 	ApiKey string `json:"apiKey"`
@@ -115,7 +127,10 @@ type Segment struct {
 	// Unique identifier for the segment.
 	Identifier string    `json:"identifier"`
 	Included   *[]Target `json:"included,omitempty"`
-	ModifiedAt *int64    `json:"modifiedAt,omitempty"`
+
+	// How the clauses of each segment rule are combined, defaults to and.
+	Logic      *SegmentLogic `json:"logic,omitempty"`
+	ModifiedAt *int64        `json:"modifiedAt,omitempty"`
 
 	// Name of the segment.
 	Name string `json:"name"`
@@ -126,6 +141,9 @@ type Segment struct {
 	Version *int64    `json:"version,omitempty"`
 }
 
+// SegmentLogic defines model for Segment.Logic.
+type SegmentLogic string
+
 // Serve defines model for Serve.
 type Serve struct {
 	Distribution *Distribution `json:"distribution,omitempty"`
@@ -134,12 +152,18 @@ type Serve struct {
 
 // ServingRule defines model for ServingRule.
 type ServingRule struct {
-	Clauses  []Clause `json:"clauses"`
-	Priority int      `json:"priority"`
-	RuleId   string   `json:"ruleId"`
-	Serve    Serve    `json:"serve"`
+	Clauses []Clause `json:"clauses"`
+
+	// How the clauses of the rule are combined, defaults to and.
+	Logic    *ServingRuleLogic `json:"logic,omitempty"`
+	Priority int               `json:"priority"`
+	RuleId   string            `json:"ruleId"`
+	Serve    Serve             `json:"serve"`
 }
 
+// ServingRuleLogic defines model for ServingRule.Logic.
+type ServingRuleLogic string
+
 // A name and value pair.
 type Tag struct {
 	Name  string  `json:"name"`
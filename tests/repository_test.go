@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/harness/ff-golang-server-sdk/pkg/repository"
+	"github.com/harness/ff-golang-server-sdk/rest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFFRepository_GetFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		flags    []string
+		segments []string
+		want     []string
+	}{
+		{
+			name: "empty repository",
+			want: []string{},
+		},
+		{
+			name:     "cache backed repository",
+			flags:    []string{"bravo", "alpha", "charlie"},
+			segments: []string{"beta"},
+			want:     []string{"alpha", "bravo", "charlie"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lruCache, err := repository.NewLruCache(1000)
+			if err != nil {
+				t.Fatal(err)
+			}
+			repo := repository.New(lruCache).(repository.FFRepository)
+			for _, flag := range tt.flags {
+				repo.SetFlag(rest.FeatureConfig{Feature: flag})
+			}
+			for _, segment := range tt.segments {
+				repo.SetSegment(rest.Segment{Identifier: segment})
+			}
+
+			got, err := repo.GetFlags()
+			assert.NoError(t, err)
+			assert.NotNil(t, got)
+
+			identifiers := make([]string, 0, len(got))
+			for _, flag := range got {
+				identifiers = append(identifiers, flag.Feature)
+			}
+			assert.Equal(t, tt.want, identifiers)
+		})
+	}
+}